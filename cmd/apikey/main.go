@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"wallet/config"
+	"wallet/internal/infrastructure/database/postgres"
+	apiKeyRepository "wallet/internal/repository/apikey"
+	"wallet/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/ilyakaznacheev/cleanenv"
+	"github.com/joho/godotenv"
+)
+
+const (
+	envFilename = "config-local.env"
+)
+
+const usage = `usage:
+  apikey issue -name NAME -scopes wallet:read,wallet:write
+  apikey list
+  apikey revoke -id ID`
+
+// apikey manages api keys directly in the database, e.g. to issue the first wallet:admin key.
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatal(usage)
+	}
+
+	cfg := new(config.Config)
+
+	if err := godotenv.Load(envFilename); err != nil {
+		log.Println("No .env file found")
+	}
+
+	if err := cleanenv.ReadEnv(cfg); err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := context.Background()
+
+	store, err := postgres.NewStore(ctx, cfg.Database.Convert())
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer store.Close()
+
+	apiKeyService := service.NewAPIKeyService(apiKeyRepository.New(), store, cfg.Auth.Convert())
+
+	switch os.Args[1] {
+	case "issue":
+		fs := flag.NewFlagSet("issue", flag.ExitOnError)
+		name := fs.String("name", "", "api key name")
+		scopes := fs.String("scopes", "", "comma separated scopes")
+		_ = fs.Parse(os.Args[2:])
+
+		key, raw, err := apiKeyService.Issue(ctx, *name, strings.Split(*scopes, ","))
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("id:     %s\nscopes: %v\nkey:    %s\n", key.ID, key.Scopes, raw)
+
+	case "list":
+		keys, err := apiKeyService.List(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, k := range keys {
+			fmt.Printf("%s\t%s\t%s\t%v\trevoked=%t\n", k.ID, k.Prefix, k.Name, k.Scopes, k.IsRevoked())
+		}
+
+	case "revoke":
+		fs := flag.NewFlagSet("revoke", flag.ExitOnError)
+		id := fs.String("id", "", "api key id")
+		_ = fs.Parse(os.Args[2:])
+
+		keyID, err := uuid.Parse(*id)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := apiKeyService.Revoke(ctx, keyID); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("revoked", keyID)
+
+	default:
+		log.Fatal(usage)
+	}
+}
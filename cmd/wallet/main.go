@@ -20,6 +20,10 @@ const (
 
 // @host		localhost:8080
 // @BasePath	/api/v1
+//
+// @securityDefinitions.apikey	ApiKeyAuth
+// @in							header
+// @name						X-API-Key
 func main() {
 	cfg := new(config.Config)
 
@@ -31,5 +35,9 @@ func main() {
 		log.Fatal(err)
 	}
 
+	if err := cfg.Validate(); err != nil {
+		log.Fatal(err)
+	}
+
 	app.Run(cfg)
 }
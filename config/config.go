@@ -2,29 +2,72 @@ package config
 
 import (
 	"time"
+	"wallet/internal/infrastructure/auth/jwt"
 	"wallet/internal/infrastructure/broker/kafka"
+	"wallet/internal/infrastructure/broker/pgqueue"
 	"wallet/internal/infrastructure/cache/redis"
 	"wallet/internal/infrastructure/database/postgres"
+	"wallet/internal/infrastructure/webhook"
+	"wallet/internal/repository/domainevent"
+	"wallet/internal/repository/event"
+	"wallet/internal/repository/transaction"
+	"wallet/internal/repository/wallet"
+	"wallet/internal/service"
+	"wallet/internal/utils/grpcserver"
+	"wallet/internal/utils/httpauth"
 	"wallet/internal/utils/httpserver"
 	"wallet/internal/utils/metrics"
 	"wallet/internal/utils/pprof"
+	"wallet/internal/utils/ratelimit"
+
+	"github.com/rs/cors"
 )
 
 type (
 	Config struct {
-		HTTPServer HTTPServerConfig
-		Database   DBConfig
-		PProf      PProfConfig
-		Metrics    MetricsConfig
-		Cache      CacheConfig
-		Consumer   ConsumerConfig
-		Producer   ProducerConfig
+		HTTPServer   HTTPServerConfig
+		GRPCServer   GRPCServerConfig
+		Database     DBConfig
+		PProf        PProfConfig
+		Metrics      MetricsConfig
+		AdminServer  AdminServerConfig
+		CORS         CORSConfig
+		Cache        CacheConfig
+		Broker       BrokerConfig
+		Workers      WorkersConfig
+		Consumer     ConsumerConfig
+		Producer     ProducerConfig
+		Auth         AuthConfig
+		JWT          JWTConfig
+		RateLimit    RateLimitConfig
+		Events       EventsConfig
+		DomainEvents DomainEventsConfig
+		Topics       TopicsConfig
+		Webhook      WebhookConfig
 	}
 
 	HTTPServerConfig struct {
-		//Port         string        `env:"HTTP_SERVER_PORT" env-default:"8080"`
-		ReadTimeout  time.Duration `env:"HTTP_SERVER_READ_TIMEOUT" env-default:"5s"`
-		WriteTimeout time.Duration `env:"HTTP_SERVER_WRITE_TIMEOUT" env-default:"5s"`
+		Addr              string        `env:"HTTP_SERVER_ADDR" env-default:":8080"`
+		ReadTimeout       time.Duration `env:"HTTP_SERVER_READ_TIMEOUT" env-default:"5s"`
+		ReadHeaderTimeout time.Duration `env:"HTTP_SERVER_READ_HEADER_TIMEOUT" env-default:"2s"`
+		WriteTimeout      time.Duration `env:"HTTP_SERVER_WRITE_TIMEOUT" env-default:"5s"`
+		IdleTimeout       time.Duration `env:"HTTP_SERVER_IDLE_TIMEOUT" env-default:"60s"`
+
+		TLSEnabled  bool   `env:"HTTP_SERVER_TLS_ENABLED" env-default:"false"`
+		TLSCertFile string `env:"HTTP_SERVER_TLS_CERT_FILE"`
+		TLSKeyFile  string `env:"HTTP_SERVER_TLS_KEY_FILE"`
+		// TLSClientAuth is none, optional or require, client certificates are verified against TLSClientCAFile.
+		TLSClientAuth     string        `env:"HTTP_SERVER_TLS_CLIENT_AUTH" env-default:"none"`
+		TLSClientCAFile   string        `env:"HTTP_SERVER_TLS_CLIENT_CA_FILE"`
+		TLSReloadInterval time.Duration `env:"HTTP_SERVER_TLS_RELOAD_INTERVAL" env-default:"1m"`
+		// TLSClientAllowed restricts the api to client certificates with one of the names (CN, DNS or URI SAN).
+		TLSClientAllowed []string `env:"HTTP_SERVER_TLS_CLIENT_ALLOWED"`
+	}
+
+	GRPCServerConfig struct {
+		Enabled    bool   `env:"GRPC_SERVER_ENABLED" env-default:"true"`
+		Addr       string `env:"GRPC_SERVER_ADDR" env-default:":50051"`
+		Reflection bool   `env:"GRPC_SERVER_REFLECTION" env-default:"true"`
 	}
 
 	DBConfig struct {
@@ -33,37 +76,250 @@ type (
 		Username string `env:"DB_USERNAME" env-default:"postgres"`
 		Password string `env:"DB_PASSWORD" env-default:"postgres"`
 		Database string `env:"DB_DATABASE" env-default:"postgres"`
+		SSLMode  string `env:"DB_SSLMODE" env-default:"disable"`
+
+		// Zero pool settings keep the pgxpool defaults, zero statement timeout disables it.
+		MinConns         int32         `env:"DB_MIN_CONNS" env-default:"0"`
+		MaxConns         int32         `env:"DB_MAX_CONNS" env-default:"0"`
+		MaxConnLifetime  time.Duration `env:"DB_MAX_CONN_LIFETIME" env-default:"1h"`
+		MaxConnIdleTime  time.Duration `env:"DB_MAX_CONN_IDLE_TIME" env-default:"30m"`
+		ConnectTimeout   time.Duration `env:"DB_CONNECT_TIMEOUT" env-default:"5s"`
+		StatementTimeout time.Duration `env:"DB_STATEMENT_TIMEOUT" env-default:"0"`
+
+		TxMaxAttempts    int           `env:"DB_TX_MAX_ATTEMPTS" env-default:"5"`
+		TxRetryBaseDelay time.Duration `env:"DB_TX_RETRY_BASE_DELAY" env-default:"5ms"`
+		TxRetryMaxDelay  time.Duration `env:"DB_TX_RETRY_MAX_DELAY" env-default:"200ms"`
+		TxRetryBudget    float64       `env:"DB_TX_RETRY_BUDGET" env-default:"0.1"`
 	}
 
 	CacheConfig struct {
+		// Mode is one of standalone, sentinel or cluster.
+		Mode string `env:"CACHE_MODE" env-default:"standalone"`
+		// URI is used in the standalone mode.
 		URI string `env:"CACHE_URI" env-default:"localhost:6379"`
+		// Addrs are sentinels in the sentinel mode and seed nodes in the cluster mode.
+		Addrs            []string `env:"CACHE_ADDRS"`
+		MasterName       string   `env:"CACHE_MASTER_NAME"`
+		Username         string   `env:"CACHE_USERNAME"`
+		Password         string   `env:"CACHE_PASSWORD"`
+		SentinelPassword string   `env:"CACHE_SENTINEL_PASSWORD"`
+		DB               int      `env:"CACHE_DB" env-default:"0"`
+
+		PoolSize     int           `env:"CACHE_POOL_SIZE" env-default:"0"`
+		MinIdleConns int           `env:"CACHE_MIN_IDLE_CONNS" env-default:"0"`
+		DialTimeout  time.Duration `env:"CACHE_DIAL_TIMEOUT" env-default:"5s"`
+		ReadTimeout  time.Duration `env:"CACHE_READ_TIMEOUT" env-default:"500ms"`
+		WriteTimeout time.Duration `env:"CACHE_WRITE_TIMEOUT" env-default:"500ms"`
+		PoolTimeout  time.Duration `env:"CACHE_POOL_TIMEOUT" env-default:"1s"`
+
+		TLSEnabled            bool   `env:"CACHE_TLS_ENABLED" env-default:"false"`
+		TLSCAFile             string `env:"CACHE_TLS_CA_FILE"`
+		TLSCertFile           string `env:"CACHE_TLS_CERT_FILE"`
+		TLSKeyFile            string `env:"CACHE_TLS_KEY_FILE"`
+		TLSServerName         string `env:"CACHE_TLS_SERVER_NAME"`
+		TLSInsecureSkipVerify bool   `env:"CACHE_TLS_INSECURE_SKIP_VERIFY" env-default:"false"`
+
+		BalanceTTL time.Duration `env:"CACHE_BALANCE_TTL" env-default:"5s"`
+
+		LocalEnabled bool          `env:"CACHE_LOCAL_ENABLED" env-default:"false"`
+		LocalSize    int           `env:"CACHE_LOCAL_SIZE" env-default:"10000"`
+		LocalTTL     time.Duration `env:"CACHE_LOCAL_TTL" env-default:"1s"`
+	}
+
+	BrokerConfig struct {
+		// Backend is kafka or postgres. The postgres queue uses CONSUMER_TOPIC as the topic name.
+		Backend                string        `env:"BROKER_BACKEND" env-default:"kafka"`
+		QueueVisibilityTimeout time.Duration `env:"QUEUE_VISIBILITY_TIMEOUT" env-default:"30s"`
+		QueuePollInterval      time.Duration `env:"QUEUE_POLL_INTERVAL" env-default:"1s"`
+		QueueMaxAttempts       int           `env:"QUEUE_MAX_ATTEMPTS" env-default:"5"`
+		QueueDeadLetterTopic   string        `env:"QUEUE_DEAD_LETTER_TOPIC" env-default:"wallet-transactions-dlq"`
+	}
+
+	WorkersConfig struct {
+		// Min workers consume transactions, autoscaling is enabled when Max is above Min.
+		Min           int           `env:"WORKERS_MIN" env-default:"20"`
+		Max           int           `env:"WORKERS_MAX" env-default:"20"`
+		ScaleInterval time.Duration `env:"WORKERS_SCALE_INTERVAL" env-default:"10s"`
+		TargetDrain   time.Duration `env:"WORKERS_TARGET_DRAIN" env-default:"10s"`
+		// BatchSize above one enables batch processing of hot wallets.
+		BatchSize int           `env:"WORKERS_BATCH_SIZE" env-default:"1"`
+		BatchWait time.Duration `env:"WORKERS_BATCH_WAIT" env-default:"10ms"`
 	}
 
 	ConsumerConfig struct {
-		Addr    string `env:"CONSUMER_ADDR" env-default:"localhost:29092"`
-		Topic   string `env:"CONSUMER_TOPIC" env-default:"test"`
-		GroupID string `env:"CONSUMER_GROUP_ID" env-default:"test"`
+		Brokers       []string `env:"CONSUMER_BROKERS" env-default:"localhost:29092"`
+		Topic         string   `env:"CONSUMER_TOPIC" env-default:"test"`
+		GroupID       string   `env:"CONSUMER_GROUP_ID" env-default:"test"`
+		Security      KafkaSecurityConfig
+		MinBytes      int           `env:"CONSUMER_MIN_BYTES" env-default:"1"`
+		MaxBytes      int           `env:"CONSUMER_MAX_BYTES" env-default:"1048576"`
+		StartOffset   string        `env:"CONSUMER_START_OFFSET" env-default:"first"`
+		MaxLag        int64         `env:"CONSUMER_MAX_LAG" env-default:"10000"`
+		Locking       string        `env:"CONSUMER_LOCKING" env-default:"optimistic"`
+		StatsInterval time.Duration `env:"CONSUMER_STATS_INTERVAL" env-default:"10s"`
 	}
 
 	ProducerConfig struct {
-		Addr  string `env:"PRODUCER_ADDR" env-default:"localhost:29092"`
-		Topic string `env:"PRODUCER_TOPIC" env-default:"test"`
+		Brokers       []string `env:"PRODUCER_BROKERS" env-default:"localhost:29092"`
+		Topic         string   `env:"PRODUCER_TOPIC" env-default:"test"`
+		Security      KafkaSecurityConfig
+		Acks          string        `env:"PRODUCER_ACKS" env-default:"all"`
+		Compression   string        `env:"PRODUCER_COMPRESSION" env-default:"none"`
+		BatchSize     int           `env:"PRODUCER_BATCH_SIZE" env-default:"1"`
+		BatchTimeout  time.Duration `env:"PRODUCER_BATCH_TIMEOUT" env-default:"10ms"`
+		Encoding      string        `env:"PRODUCER_ENCODING" env-default:"json"`
+		StatsInterval time.Duration `env:"PRODUCER_STATS_INTERVAL" env-default:"10s"`
+	}
+
+	TopicsConfig struct {
+		// Mode is off, validate (fail on missing topics or too few partitions) or create (also create missing topics).
+		Mode    string        `env:"TOPICS_MODE" env-default:"off"`
+		Timeout time.Duration `env:"TOPICS_TIMEOUT" env-default:"30s"`
+
+		TransactionsPartitions        int           `env:"TOPICS_TRANSACTIONS_PARTITIONS" env-default:"12"`
+		TransactionsReplicationFactor int           `env:"TOPICS_TRANSACTIONS_REPLICATION_FACTOR" env-default:"3"`
+		TransactionsRetention         time.Duration `env:"TOPICS_TRANSACTIONS_RETENTION" env-default:"168h"`
+
+		// DLQName is the topic of messages that can't be decoded, they are only logged when it is empty.
+		DLQName              string        `env:"TOPICS_DLQ_NAME" env-default:""`
+		DLQPartitions        int           `env:"TOPICS_DLQ_PARTITIONS" env-default:"1"`
+		DLQReplicationFactor int           `env:"TOPICS_DLQ_REPLICATION_FACTOR" env-default:"3"`
+		DLQRetention         time.Duration `env:"TOPICS_DLQ_RETENTION" env-default:"720h"`
+
+		EventsPartitions        int           `env:"TOPICS_EVENTS_PARTITIONS" env-default:"12"`
+		EventsReplicationFactor int           `env:"TOPICS_EVENTS_REPLICATION_FACTOR" env-default:"3"`
+		EventsRetention         time.Duration `env:"TOPICS_EVENTS_RETENTION" env-default:"168h"`
+	}
+
+	// KafkaSecurityConfig is shared by the consumer and the producers.
+	KafkaSecurityConfig struct {
+		SASLMechanism         string `env:"KAFKA_SASL_MECHANISM" env-default:""`
+		SASLUsername          string `env:"KAFKA_SASL_USERNAME" env-default:""`
+		SASLPassword          string `env:"KAFKA_SASL_PASSWORD" env-default:""`
+		TLSEnabled            bool   `env:"KAFKA_TLS_ENABLED" env-default:"false"`
+		TLSCAFile             string `env:"KAFKA_TLS_CA_FILE" env-default:""`
+		TLSServerName         string `env:"KAFKA_TLS_SERVER_NAME" env-default:""`
+		TLSInsecureSkipVerify bool   `env:"KAFKA_TLS_INSECURE_SKIP_VERIFY" env-default:"false"`
+	}
+
+	AuthConfig struct {
+		Enabled      bool          `env:"AUTH_ENABLED" env-default:"true"`
+		KeyCacheSize int           `env:"AUTH_KEY_CACHE_SIZE" env-default:"10000"`
+		KeyCacheTTL  time.Duration `env:"AUTH_KEY_CACHE_TTL" env-default:"30s"`
+		KeyRejectTTL time.Duration `env:"AUTH_KEY_REJECT_TTL" env-default:"5s"`
+	}
+
+	JWTConfig struct {
+		JWKSFile        string        `env:"JWT_JWKS_FILE" env-default:""`
+		JWKSURL         string        `env:"JWT_JWKS_URL" env-default:""`
+		RefreshInterval time.Duration `env:"JWT_JWKS_REFRESH_INTERVAL" env-default:"10m"`
+		Issuer          string        `env:"JWT_ISSUER" env-default:""`
+		Audience        string        `env:"JWT_AUDIENCE" env-default:""`
+		Leeway          time.Duration `env:"JWT_LEEWAY" env-default:"30s"`
+		ServiceScope    string        `env:"JWT_SERVICE_SCOPE" env-default:"wallet:service"`
+		DefaultScopes   []string      `env:"JWT_DEFAULT_SCOPES" env-default:"wallet:read,wallet:write"`
+	}
+
+	RateLimitConfig struct {
+		Enabled     bool    `env:"RATE_LIMIT_ENABLED" env-default:"true"`
+		Backend     string  `env:"RATE_LIMIT_BACKEND" env-default:"local"`
+		ClientRate  float64 `env:"RATE_LIMIT_CLIENT_RPS" env-default:"500"`
+		ClientBurst int     `env:"RATE_LIMIT_CLIENT_BURST" env-default:"1000"`
+		WalletRate  float64 `env:"RATE_LIMIT_WALLET_RPS" env-default:"1000"`
+		WalletBurst int     `env:"RATE_LIMIT_WALLET_BURST" env-default:"2000"`
+	}
+
+	EventsConfig struct {
+		HeartbeatInterval time.Duration `env:"EVENTS_HEARTBEAT_INTERVAL" env-default:"15s"`
+		BufferSize        int           `env:"EVENTS_BUFFER_SIZE" env-default:"64"`
+		HistorySize       int64         `env:"EVENTS_HISTORY_SIZE" env-default:"100"`
+		HistoryTTL        time.Duration `env:"EVENTS_HISTORY_TTL" env-default:"1h"`
+	}
+
+	DomainEventsConfig struct {
+		Enabled bool   `env:"DOMAIN_EVENTS_ENABLED" env-default:"true"`
+		Topic   string `env:"DOMAIN_EVENTS_TOPIC" env-default:"wallet-domain-events"`
+		Source  string `env:"DOMAIN_EVENTS_SOURCE" env-default:"/wallet"`
+	}
+
+	WebhookConfig struct {
+		WorkerEnabled bool          `env:"WEBHOOK_WORKER_ENABLED" env-default:"true"`
+		PollInterval  time.Duration `env:"WEBHOOK_POLL_INTERVAL" env-default:"1s"`
+		BatchSize     uint64        `env:"WEBHOOK_BATCH_SIZE" env-default:"20"`
+		Lease         time.Duration `env:"WEBHOOK_LEASE" env-default:"1m"`
+		Timeout       time.Duration `env:"WEBHOOK_TIMEOUT" env-default:"5s"`
+		MaxAttempts   int           `env:"WEBHOOK_MAX_ATTEMPTS" env-default:"15"`
+		BackoffBase   time.Duration `env:"WEBHOOK_BACKOFF_BASE" env-default:"10s"`
+		BackoffMax    time.Duration `env:"WEBHOOK_BACKOFF_MAX" env-default:"1h"`
+		DisableAfter  time.Duration `env:"WEBHOOK_DISABLE_AFTER" env-default:"72h"`
+		// AllowedNetworks lets webhooks reach non-public addresses of these CIDRs, e.g. loopback for local development.
+		AllowedNetworks []string `env:"WEBHOOK_ALLOWED_NETWORKS"`
 	}
 
 	PProfConfig struct {
-		//Port string `env:"PPROF_PORT" env-default:"8081"`
+		Enabled           bool          `env:"PPROF_ENABLED" env-default:"true"`
+		Addr              string        `env:"PPROF_ADDR" env-default:":8081"`
+		ReadHeaderTimeout time.Duration `env:"PPROF_READ_HEADER_TIMEOUT" env-default:"2s"`
+		IdleTimeout       time.Duration `env:"PPROF_IDLE_TIMEOUT" env-default:"60s"`
+		// Basic auth and the bearer token are optional, a request passes with either of them.
+		AuthUsername string `env:"PPROF_AUTH_USERNAME"`
+		AuthPassword string `env:"PPROF_AUTH_PASSWORD"`
+		AuthToken    string `env:"PPROF_AUTH_TOKEN"`
 	}
 
 	MetricsConfig struct {
-		//Port string `env:"METRICS_PORT" env-default:"8082"`
+		Addr              string        `env:"METRICS_ADDR" env-default:":8082"`
+		ReadHeaderTimeout time.Duration `env:"METRICS_READ_HEADER_TIMEOUT" env-default:"2s"`
+		IdleTimeout       time.Duration `env:"METRICS_IDLE_TIMEOUT" env-default:"60s"`
+		// Auth protects /metrics only, /readyz stays open for probes.
+		AuthUsername string `env:"METRICS_AUTH_USERNAME"`
+		AuthPassword string `env:"METRICS_AUTH_PASSWORD"`
+		AuthToken    string `env:"METRICS_AUTH_TOKEN"`
+	}
+
+	CORSConfig struct {
+		Enabled bool `env:"CORS_ENABLED" env-default:"true"`
+		// AllowedOrigins may contain one wildcard per origin, e.g. https://*.example.com, or * for any origin.
+		AllowedOrigins []string `env:"CORS_ALLOWED_ORIGINS" env-default:"*"`
+		AllowedMethods []string `env:"CORS_ALLOWED_METHODS" env-default:"GET,POST,PUT,DELETE,OPTIONS"`
+		AllowedHeaders []string `env:"CORS_ALLOWED_HEADERS" env-default:"Content-Type,Authorization,X-API-Key,Last-Event-ID,Idempotency-Key,X-Request-ID"`
+		ExposedHeaders []string `env:"CORS_EXPOSED_HEADERS" env-default:"Location,Idempotency-Key,Retry-After,X-Request-ID"`
+		// AllowCredentials can't be combined with the * origin, browsers reject such responses.
+		AllowCredentials bool          `env:"CORS_ALLOW_CREDENTIALS" env-default:"false"`
+		MaxAge           time.Duration `env:"CORS_MAX_AGE" env-default:"1h"`
+	}
+
+	// AdminServerConfig mounts pprof, metrics and the admin api on one port instead of
+	// PPROF_ADDR and METRICS_ADDR. The admin api is then not served on HTTP_SERVER_ADDR.
+	AdminServerConfig struct {
+		Addr              string        `env:"ADMIN_SERVER_ADDR"`
+		ReadHeaderTimeout time.Duration `env:"ADMIN_SERVER_READ_HEADER_TIMEOUT" env-default:"2s"`
+		IdleTimeout       time.Duration `env:"ADMIN_SERVER_IDLE_TIMEOUT" env-default:"60s"`
 	}
 )
 
 func (srv HTTPServerConfig) Convert() httpserver.ServerConfig {
 	return httpserver.ServerConfig{
-		Addr:         ":8080",
-		ReadTimeout:  srv.ReadTimeout,
-		WriteTimeout: srv.WriteTimeout,
+		Addr:              srv.Addr,
+		ReadTimeout:       srv.ReadTimeout,
+		ReadHeaderTimeout: srv.ReadHeaderTimeout,
+		WriteTimeout:      srv.WriteTimeout,
+		IdleTimeout:       srv.IdleTimeout,
+		TLS: httpserver.TLSConfig{
+			Enabled:        srv.TLSEnabled,
+			CertFile:       srv.TLSCertFile,
+			KeyFile:        srv.TLSKeyFile,
+			ClientCAFile:   srv.TLSClientCAFile,
+			ClientAuth:     httpserver.ClientAuth(srv.TLSClientAuth),
+			ReloadInterval: srv.TLSReloadInterval,
+		},
+	}
+}
+
+func (srv GRPCServerConfig) Convert() grpcserver.ServerConfig {
+	return grpcserver.ServerConfig{
+		Addr:       srv.Addr,
+		Reflection: srv.Reflection,
 	}
 }
 
@@ -74,38 +330,320 @@ func (db DBConfig) Convert() postgres.DBConfig {
 		User:     db.Username,
 		Pass:     db.Password,
 		Database: db.Database,
+		SSLMode:  db.SSLMode,
+		Pool: postgres.PoolConfig{
+			MinConns:         db.MinConns,
+			MaxConns:         db.MaxConns,
+			MaxConnLifetime:  db.MaxConnLifetime,
+			MaxConnIdleTime:  db.MaxConnIdleTime,
+			ConnectTimeout:   db.ConnectTimeout,
+			StatementTimeout: db.StatementTimeout,
+		},
+		Retry: postgres.RetryConfig{
+			MaxAttempts: db.TxMaxAttempts,
+			BaseDelay:   db.TxRetryBaseDelay,
+			MaxDelay:    db.TxRetryMaxDelay,
+			BudgetRatio: db.TxRetryBudget,
+		},
+	}
+}
+
+func (c CacheConfig) Wallets() wallet.Config {
+	return wallet.Config{
+		BalanceTTL: c.BalanceTTL,
+	}
+}
+
+func (c CacheConfig) Local() wallet.LocalConfig {
+	return wallet.LocalConfig{
+		Size: c.LocalSize,
+		TTL:  c.LocalTTL,
 	}
 }
 
 func (c CacheConfig) Convert() redis.Config {
 	return redis.Config{
-		URI: c.URI,
+		Mode:             redis.Mode(c.Mode),
+		URI:              c.URI,
+		Addrs:            c.Addrs,
+		MasterName:       c.MasterName,
+		Username:         c.Username,
+		Password:         c.Password,
+		SentinelPassword: c.SentinelPassword,
+		DB:               c.DB,
+		PoolSize:         c.PoolSize,
+		MinIdleConns:     c.MinIdleConns,
+		DialTimeout:      c.DialTimeout,
+		ReadTimeout:      c.ReadTimeout,
+		WriteTimeout:     c.WriteTimeout,
+		PoolTimeout:      c.PoolTimeout,
+		TLS: redis.TLSConfig{
+			Enabled:            c.TLSEnabled,
+			CAFile:             c.TLSCAFile,
+			CertFile:           c.TLSCertFile,
+			KeyFile:            c.TLSKeyFile,
+			ServerName:         c.TLSServerName,
+			InsecureSkipVerify: c.TLSInsecureSkipVerify,
+		},
+	}
+}
+
+// Enabled reports whether JWT authentication is configured.
+func (j JWTConfig) Enabled() bool {
+	return j.JWKSFile != "" || j.JWKSURL != ""
+}
+
+func (a AuthConfig) Convert() service.APIKeyConfig {
+	return service.APIKeyConfig{
+		CacheSize: a.KeyCacheSize,
+		CacheTTL:  a.KeyCacheTTL,
+		RejectTTL: a.KeyRejectTTL,
+	}
+}
+
+func (j JWTConfig) Convert() jwt.Config {
+	refresh := j.RefreshInterval
+	if j.JWKSURL == "" {
+		refresh = 0
+	}
+
+	return jwt.Config{
+		JWKSFile:        j.JWKSFile,
+		JWKSURL:         j.JWKSURL,
+		RefreshInterval: refresh,
+		Issuer:          j.Issuer,
+		Audience:        j.Audience,
+		Leeway:          j.Leeway,
+		ServiceScope:    j.ServiceScope,
+		DefaultScopes:   j.DefaultScopes,
+	}
+}
+
+const (
+	RateLimitBackendLocal = "local"
+	RateLimitBackendRedis = "redis"
+)
+
+func (rl RateLimitConfig) Client() ratelimit.Config {
+	return ratelimit.Config{
+		Rate:  rl.ClientRate,
+		Burst: rl.ClientBurst,
+	}
+}
+
+func (rl RateLimitConfig) Wallet() ratelimit.Config {
+	return ratelimit.Config{
+		Rate:  rl.WalletRate,
+		Burst: rl.WalletBurst,
+	}
+}
+
+func (e EventsConfig) Convert() event.Config {
+	return event.Config{
+		HistorySize: e.HistorySize,
+		HistoryTTL:  e.HistoryTTL,
+	}
+}
+
+func (wh WebhookConfig) Convert() service.WebhookConfig {
+	return service.WebhookConfig{
+		PollInterval: wh.PollInterval,
+		BatchSize:    wh.BatchSize,
+		Lease:        wh.Lease,
+		MaxAttempts:  wh.MaxAttempts,
+		BackoffBase:  wh.BackoffBase,
+		BackoffMax:   wh.BackoffMax,
+		DisableAfter: wh.DisableAfter,
+	}
+}
+
+func (wh WebhookConfig) Sender() webhook.Config {
+	return webhook.Config{
+		Timeout:         wh.Timeout,
+		AllowedNetworks: wh.AllowedNetworks,
 	}
 }
 
 func (p PProfConfig) Convert() pprof.Config {
 	return pprof.Config{
-		Addr: ":8081",
+		Addr:              p.Addr,
+		ReadHeaderTimeout: p.ReadHeaderTimeout,
+		IdleTimeout:       p.IdleTimeout,
+		Auth: httpauth.Config{
+			Username: p.AuthUsername,
+			Password: p.AuthPassword,
+			Token:    p.AuthToken,
+		},
 	}
 }
 
 func (ms MetricsConfig) Convert() metrics.Config {
 	return metrics.Config{
-		Addr: ":8082",
+		Addr:              ms.Addr,
+		ReadHeaderTimeout: ms.ReadHeaderTimeout,
+		IdleTimeout:       ms.IdleTimeout,
+		Auth: httpauth.Config{
+			Username: ms.AuthUsername,
+			Password: ms.AuthPassword,
+			Token:    ms.AuthToken,
+		},
+	}
+}
+
+func (c CORSConfig) Convert() cors.Options {
+	return cors.Options{
+		AllowedOrigins:   c.AllowedOrigins,
+		AllowedMethods:   c.AllowedMethods,
+		AllowedHeaders:   c.AllowedHeaders,
+		ExposedHeaders:   c.ExposedHeaders,
+		AllowCredentials: c.AllowCredentials,
+		MaxAge:           int(c.MaxAge.Seconds()),
+	}
+}
+
+func (a AdminServerConfig) Enabled() bool {
+	return a.Addr != ""
+}
+
+// Convert has no write timeout, CPU profiles and traces are written for their whole duration.
+func (a AdminServerConfig) Convert() httpserver.ServerConfig {
+	return httpserver.ServerConfig{
+		Addr:              a.Addr,
+		ReadHeaderTimeout: a.ReadHeaderTimeout,
+		IdleTimeout:       a.IdleTimeout,
+	}
+}
+
+func (w WorkersConfig) Convert() service.WorkerConfig {
+	return service.WorkerConfig{
+		Min:           w.Min,
+		Max:           w.Max,
+		ScaleInterval: w.ScaleInterval,
+		TargetDrain:   w.TargetDrain,
+		BatchSize:     w.BatchSize,
+		BatchWait:     w.BatchWait,
 	}
 }
 
 func (c ConsumerConfig) Convert() kafka.ConsumerConfig {
 	return kafka.ConsumerConfig{
-		Addr:    c.Addr,
-		Topic:   c.Topic,
-		GroupID: c.GroupID,
+		Brokers:       c.Brokers,
+		Topic:         c.Topic,
+		GroupID:       c.GroupID,
+		Security:      c.Security.Convert(),
+		MinBytes:      c.MinBytes,
+		MaxBytes:      c.MaxBytes,
+		StartOffset:   kafka.StartOffset(c.StartOffset),
+		MaxLag:        c.MaxLag,
+		StatsInterval: c.StatsInterval,
 	}
 }
 
 func (p ProducerConfig) Convert() kafka.ProducerConfig {
 	return kafka.ProducerConfig{
-		Addr:  p.Addr,
-		Topic: p.Topic,
+		Brokers:       p.Brokers,
+		Topic:         p.Topic,
+		Security:      p.Security.Convert(),
+		Acks:          kafka.Acks(p.Acks),
+		Compression:   p.Compression,
+		BatchSize:     p.BatchSize,
+		BatchTimeout:  p.BatchTimeout,
+		StatsInterval: p.StatsInterval,
 	}
 }
+
+const (
+	BrokerBackendKafka    = "kafka"
+	BrokerBackendPostgres = "postgres"
+)
+
+func (b BrokerConfig) Queue(topic string) pgqueue.Config {
+	return pgqueue.Config{
+		Topic:             topic,
+		VisibilityTimeout: b.QueueVisibilityTimeout,
+		PollInterval:      b.QueuePollInterval,
+		MaxAttempts:       b.QueueMaxAttempts,
+		DeadLetterTopic:   b.QueueDeadLetterTopic,
+	}
+}
+
+const (
+	TopicsModeOff      = "off"
+	TopicsModeValidate = "validate"
+	TopicsModeCreate   = "create"
+)
+
+// Specs returns the topics the service uses: transactions and, when enabled, the DLQ and domain events.
+func (t TopicsConfig) Specs(c ConsumerConfig, p ProducerConfig, d DomainEventsConfig) []kafka.TopicSpec {
+	transactions := func(name string) kafka.TopicSpec {
+		return kafka.TopicSpec{
+			Name:              name,
+			Partitions:        t.TransactionsPartitions,
+			ReplicationFactor: t.TransactionsReplicationFactor,
+			Retention:         t.TransactionsRetention,
+		}
+	}
+
+	specs := []kafka.TopicSpec{transactions(p.Topic)}
+	if c.Topic != p.Topic {
+		specs = append(specs, transactions(c.Topic))
+	}
+
+	if t.DLQName != "" {
+		specs = append(specs, kafka.TopicSpec{
+			Name:              t.DLQName,
+			Partitions:        t.DLQPartitions,
+			ReplicationFactor: t.DLQReplicationFactor,
+			Retention:         t.DLQRetention,
+		})
+	}
+
+	if d.Enabled {
+		specs = append(specs, kafka.TopicSpec{
+			Name:              d.Topic,
+			Partitions:        t.EventsPartitions,
+			ReplicationFactor: t.EventsReplicationFactor,
+			Retention:         t.EventsRetention,
+		})
+	}
+
+	return specs
+}
+
+func (k KafkaSecurityConfig) Convert() kafka.SecurityConfig {
+	return kafka.SecurityConfig{
+		SASLMechanism:         kafka.SASLMechanism(k.SASLMechanism),
+		Username:              k.SASLUsername,
+		Password:              k.SASLPassword,
+		TLSEnabled:            k.TLSEnabled,
+		TLSCAFile:             k.TLSCAFile,
+		TLSServerName:         k.TLSServerName,
+		TLSInsecureSkipVerify: k.TLSInsecureSkipVerify,
+	}
+}
+
+func (p ProducerConfig) Transactions() transaction.Config {
+	return transaction.Config{
+		Encoding: transaction.Encoding(p.Encoding),
+	}
+}
+
+func (d DomainEventsConfig) Convert() domainevent.Config {
+	return domainevent.Config{
+		Source: d.Source,
+	}
+}
+
+// DLQProducer returns the producer of messages that can't be decoded, it shares the settings of the transactions producer.
+func (t TopicsConfig) DLQProducer(p ProducerConfig) kafka.ProducerConfig {
+	cfg := p.Convert()
+	cfg.Topic = t.DLQName
+	return cfg
+}
+
+// Producer returns the config of the domain events producer, it shares the brokers and settings with the transactions producer.
+func (d DomainEventsConfig) Producer(p ProducerConfig) kafka.ProducerConfig {
+	cfg := p.Convert()
+	cfg.Topic = d.Topic
+	return cfg
+}
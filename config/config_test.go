@@ -0,0 +1,26 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopicsConfig_Specs(t *testing.T) {
+	cfg := defaultConfig(t)
+
+	names := func() []string {
+		var names []string
+		for _, spec := range cfg.Topics.Specs(cfg.Consumer, cfg.Producer, cfg.DomainEvents) {
+			names = append(names, spec.Name)
+		}
+		return names
+	}
+
+	// the DLQ is off by default, so existing deployments don't need the topic
+	assert.NotContains(t, names(), "")
+	assert.Len(t, names(), 2)
+
+	cfg.Topics.DLQName = "wallet-transactions-dlq"
+	assert.Contains(t, names(), "wallet-transactions-dlq")
+}
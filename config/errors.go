@@ -0,0 +1,7 @@
+package config
+
+import "errors"
+
+var (
+	ErrInvalidConfig = errors.New("invalid config")
+)
@@ -0,0 +1,324 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"wallet/internal/infrastructure/broker/kafka"
+	"wallet/internal/infrastructure/cache/redis"
+	"wallet/internal/repository/transaction"
+	"wallet/internal/service"
+	"wallet/internal/utils/httpserver"
+)
+
+var sslModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// Validate checks the whole config and reports every invalid field by its environment variable.
+func (c *Config) Validate() error {
+	v := new(validator)
+
+	v.addr("HTTP_SERVER_ADDR", c.HTTPServer.Addr)
+	v.nonNegative("HTTP_SERVER_READ_TIMEOUT", c.HTTPServer.ReadTimeout)
+	v.nonNegative("HTTP_SERVER_READ_HEADER_TIMEOUT", c.HTTPServer.ReadHeaderTimeout)
+	v.nonNegative("HTTP_SERVER_WRITE_TIMEOUT", c.HTTPServer.WriteTimeout)
+	v.nonNegative("HTTP_SERVER_IDLE_TIMEOUT", c.HTTPServer.IdleTimeout)
+	c.HTTPServer.validateTLS(v)
+
+	if c.GRPCServer.Enabled {
+		v.addr("GRPC_SERVER_ADDR", c.GRPCServer.Addr)
+	}
+
+	if c.PProf.Enabled && !c.AdminServer.Enabled() {
+		v.addr("PPROF_ADDR", c.PProf.Addr)
+	}
+	v.nonNegative("PPROF_READ_HEADER_TIMEOUT", c.PProf.ReadHeaderTimeout)
+	v.nonNegative("PPROF_IDLE_TIMEOUT", c.PProf.IdleTimeout)
+	v.basicAuth("PPROF_AUTH", c.PProf.AuthUsername, c.PProf.AuthPassword)
+
+	if !c.AdminServer.Enabled() {
+		v.addr("METRICS_ADDR", c.Metrics.Addr)
+	}
+	v.nonNegative("METRICS_READ_HEADER_TIMEOUT", c.Metrics.ReadHeaderTimeout)
+	v.nonNegative("METRICS_IDLE_TIMEOUT", c.Metrics.IdleTimeout)
+	v.basicAuth("METRICS_AUTH", c.Metrics.AuthUsername, c.Metrics.AuthPassword)
+
+	listeners := map[string]string{
+		"HTTP_SERVER_ADDR": c.HTTPServer.Addr,
+	}
+	if c.GRPCServer.Enabled {
+		listeners["GRPC_SERVER_ADDR"] = c.GRPCServer.Addr
+	}
+	if c.AdminServer.Enabled() {
+		v.addr("ADMIN_SERVER_ADDR", c.AdminServer.Addr)
+		v.nonNegative("ADMIN_SERVER_READ_HEADER_TIMEOUT", c.AdminServer.ReadHeaderTimeout)
+		v.nonNegative("ADMIN_SERVER_IDLE_TIMEOUT", c.AdminServer.IdleTimeout)
+		listeners["ADMIN_SERVER_ADDR"] = c.AdminServer.Addr
+	} else {
+		listeners["METRICS_ADDR"] = c.Metrics.Addr
+		if c.PProf.Enabled {
+			listeners["PPROF_ADDR"] = c.PProf.Addr
+		}
+	}
+	v.distinct(listeners)
+
+	c.CORS.validate(v)
+	c.Database.validate(v)
+	c.Cache.validate(v)
+	c.Broker.validate(v, c.Consumer.Topic)
+	c.Workers.validate(v)
+
+	if c.Broker.Backend == BrokerBackendKafka {
+		c.Consumer.validate(v)
+		c.Topics.validate(v)
+		c.Producer.validate(v)
+	}
+	v.check(c.Consumer.Topic != "", "CONSUMER_TOPIC", "must not be empty")
+	if _, err := service.ParseLockingMode(c.Consumer.Locking); err != nil {
+		v.check(false, "CONSUMER_LOCKING", "%v", err)
+	}
+	// the postgres queue stores messages in the producer encoding as well
+	v.oneOf("PRODUCER_ENCODING", c.Producer.Encoding,
+		string(transaction.EncodingLegacy), string(transaction.EncodingJSON), string(transaction.EncodingProtobuf))
+
+	if c.Auth.Enabled {
+		v.check(c.Auth.KeyCacheSize > 0, "AUTH_KEY_CACHE_SIZE", "must be positive")
+		v.positive("AUTH_KEY_CACHE_TTL", c.Auth.KeyCacheTTL)
+		v.positive("AUTH_KEY_REJECT_TTL", c.Auth.KeyRejectTTL)
+	}
+
+	v.oneOf("RATE_LIMIT_BACKEND", c.RateLimit.Backend, RateLimitBackendLocal, RateLimitBackendRedis)
+	if c.RateLimit.Enabled {
+		v.check(c.RateLimit.ClientRate > 0, "RATE_LIMIT_CLIENT_RPS", "must be positive")
+		v.check(c.RateLimit.ClientBurst > 0, "RATE_LIMIT_CLIENT_BURST", "must be positive")
+		v.check(c.RateLimit.WalletRate > 0, "RATE_LIMIT_WALLET_RPS", "must be positive")
+		v.check(c.RateLimit.WalletBurst > 0, "RATE_LIMIT_WALLET_BURST", "must be positive")
+	}
+
+	v.positive("EVENTS_HEARTBEAT_INTERVAL", c.Events.HeartbeatInterval)
+	v.check(c.Events.BufferSize > 0, "EVENTS_BUFFER_SIZE", "must be positive")
+
+	if c.DomainEvents.Enabled {
+		v.check(c.Broker.Backend == BrokerBackendKafka, "DOMAIN_EVENTS_ENABLED", "requires BROKER_BACKEND=kafka")
+		v.check(c.DomainEvents.Topic != "", "DOMAIN_EVENTS_TOPIC", "must not be empty")
+	}
+
+	v.positive("WEBHOOK_POLL_INTERVAL", c.Webhook.PollInterval)
+	v.positive("WEBHOOK_TIMEOUT", c.Webhook.Timeout)
+	v.check(c.Webhook.BatchSize > 0, "WEBHOOK_BATCH_SIZE", "must be positive")
+	// a lease running out while the delivery is still being sent lets another replica send it again
+	v.check(c.Webhook.Lease > c.Webhook.Timeout, "WEBHOOK_LEASE",
+		"%v must be longer than WEBHOOK_TIMEOUT %v", c.Webhook.Lease, c.Webhook.Timeout)
+	v.check(c.Webhook.MaxAttempts > 0, "WEBHOOK_MAX_ATTEMPTS", "must be positive")
+	v.positive("WEBHOOK_BACKOFF_BASE", c.Webhook.BackoffBase)
+	v.check(c.Webhook.BackoffBase <= c.Webhook.BackoffMax, "WEBHOOK_BACKOFF_MAX",
+		"%v is below WEBHOOK_BACKOFF_BASE %v", c.Webhook.BackoffMax, c.Webhook.BackoffBase)
+	v.positive("WEBHOOK_DISABLE_AFTER", c.Webhook.DisableAfter)
+	for _, network := range c.Webhook.AllowedNetworks {
+		_, err := netip.ParsePrefix(network)
+		v.check(err == nil, "WEBHOOK_ALLOWED_NETWORKS", "%q is not a CIDR", network)
+	}
+
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return errors.Join(append([]error{ErrInvalidConfig}, v.errs...)...)
+}
+
+func (srv HTTPServerConfig) validateTLS(v *validator) {
+	if !srv.TLSEnabled {
+		v.check(len(srv.TLSClientAllowed) == 0, "HTTP_SERVER_TLS_CLIENT_ALLOWED", "requires HTTP_SERVER_TLS_ENABLED")
+		return
+	}
+
+	v.check(srv.TLSCertFile != "", "HTTP_SERVER_TLS_CERT_FILE", "is required with HTTP_SERVER_TLS_ENABLED")
+	v.check(srv.TLSKeyFile != "", "HTTP_SERVER_TLS_KEY_FILE", "is required with HTTP_SERVER_TLS_ENABLED")
+	v.oneOf("HTTP_SERVER_TLS_CLIENT_AUTH", srv.TLSClientAuth,
+		string(httpserver.ClientAuthNone), string(httpserver.ClientAuthOptional), string(httpserver.ClientAuthRequire))
+	if httpserver.ClientAuth(srv.TLSClientAuth) == httpserver.ClientAuthNone {
+		v.check(len(srv.TLSClientAllowed) == 0, "HTTP_SERVER_TLS_CLIENT_ALLOWED", "requires HTTP_SERVER_TLS_CLIENT_AUTH optional or require")
+	} else {
+		v.check(srv.TLSClientCAFile != "", "HTTP_SERVER_TLS_CLIENT_CA_FILE", "is required to verify client certificates")
+	}
+	v.nonNegative("HTTP_SERVER_TLS_RELOAD_INTERVAL", srv.TLSReloadInterval)
+}
+
+func (c CORSConfig) validate(v *validator) {
+	if !c.Enabled {
+		return
+	}
+
+	v.check(len(c.AllowedOrigins) > 0, "CORS_ALLOWED_ORIGINS", "must not be empty, set CORS_ENABLED=false to disable CORS")
+	for _, origin := range c.AllowedOrigins {
+		v.check(strings.Count(origin, "*") <= 1, "CORS_ALLOWED_ORIGINS", "%q has more than one wildcard", origin)
+	}
+	v.check(!c.AllowCredentials || !slices.Contains(c.AllowedOrigins, "*"), "CORS_ALLOW_CREDENTIALS",
+		"can't be combined with the * origin, list the allowed origins")
+	v.check(len(c.AllowedMethods) > 0, "CORS_ALLOWED_METHODS", "must not be empty")
+	v.nonNegative("CORS_MAX_AGE", c.MaxAge)
+}
+
+func (db DBConfig) validate(v *validator) {
+	v.check(db.Host != "", "DB_HOST", "must not be empty")
+	if db.Port != "" {
+		port, err := strconv.Atoi(db.Port)
+		v.check(err == nil && port > 0 && port < 1<<16, "DB_PORT", "%q is not a port", db.Port)
+	}
+	v.oneOf("DB_SSLMODE", db.SSLMode, sslModes...)
+	v.check(db.MinConns >= 0, "DB_MIN_CONNS", "must not be negative")
+	v.check(db.MaxConns >= 0, "DB_MAX_CONNS", "must not be negative")
+	v.check(db.MaxConns == 0 || db.MinConns <= db.MaxConns, "DB_MIN_CONNS", "%d is above DB_MAX_CONNS %d", db.MinConns, db.MaxConns)
+	v.nonNegative("DB_MAX_CONN_LIFETIME", db.MaxConnLifetime)
+	v.nonNegative("DB_MAX_CONN_IDLE_TIME", db.MaxConnIdleTime)
+	v.nonNegative("DB_CONNECT_TIMEOUT", db.ConnectTimeout)
+	v.nonNegative("DB_STATEMENT_TIMEOUT", db.StatementTimeout)
+	v.check(db.TxMaxAttempts > 0, "DB_TX_MAX_ATTEMPTS", "must be positive")
+	v.check(db.TxRetryBudget >= 0, "DB_TX_RETRY_BUDGET", "must not be negative")
+}
+
+func (c CacheConfig) validate(v *validator) {
+	v.oneOf("CACHE_MODE", c.Mode, string(redis.ModeStandalone), string(redis.ModeSentinel), string(redis.ModeCluster))
+	switch redis.Mode(c.Mode) {
+	case redis.ModeSentinel:
+		v.check(len(c.Addrs) > 0, "CACHE_ADDRS", "sentinel addresses are required in the sentinel mode")
+		v.check(c.MasterName != "", "CACHE_MASTER_NAME", "is required in the sentinel mode")
+	case redis.ModeCluster:
+		v.check(len(c.Addrs) > 0, "CACHE_ADDRS", "seed nodes are required in the cluster mode")
+	}
+	v.check(c.PoolSize >= 0, "CACHE_POOL_SIZE", "must not be negative")
+	v.check(c.MinIdleConns >= 0, "CACHE_MIN_IDLE_CONNS", "must not be negative")
+	v.positive("CACHE_BALANCE_TTL", c.BalanceTTL)
+	if c.LocalEnabled {
+		v.check(c.LocalSize > 0, "CACHE_LOCAL_SIZE", "must be positive")
+		v.positive("CACHE_LOCAL_TTL", c.LocalTTL)
+	}
+}
+
+func (b BrokerConfig) validate(v *validator, topic string) {
+	v.oneOf("BROKER_BACKEND", b.Backend, BrokerBackendKafka, BrokerBackendPostgres)
+	if b.Backend == BrokerBackendPostgres {
+		v.positive("QUEUE_VISIBILITY_TIMEOUT", b.QueueVisibilityTimeout)
+		v.positive("QUEUE_POLL_INTERVAL", b.QueuePollInterval)
+		v.check(b.QueueMaxAttempts > 0, "QUEUE_MAX_ATTEMPTS", "must be positive")
+		v.check(b.QueueDeadLetterTopic != "" && b.QueueDeadLetterTopic != topic,
+			"QUEUE_DEAD_LETTER_TOPIC", "must be set and differ from CONSUMER_TOPIC")
+	}
+}
+
+func (w WorkersConfig) validate(v *validator) {
+	v.check(w.Min > 0, "WORKERS_MIN", "must be positive")
+	v.check(w.Max >= w.Min, "WORKERS_MAX", "%d is below WORKERS_MIN %d", w.Max, w.Min)
+	if w.Max > w.Min {
+		v.positive("WORKERS_SCALE_INTERVAL", w.ScaleInterval)
+	}
+	v.check(w.BatchSize > 0, "WORKERS_BATCH_SIZE", "must be positive")
+	if w.BatchSize > 1 {
+		v.positive("WORKERS_BATCH_WAIT", w.BatchWait)
+	}
+}
+
+func (c ConsumerConfig) validate(v *validator) {
+	v.check(len(c.Brokers) > 0, "CONSUMER_BROKERS", "must not be empty")
+	v.check(c.GroupID != "", "CONSUMER_GROUP_ID", "must not be empty")
+	v.oneOf("CONSUMER_START_OFFSET", c.StartOffset, string(kafka.StartOffsetFirst), string(kafka.StartOffsetLast))
+	v.check(c.MinBytes > 0 && c.MinBytes <= c.MaxBytes, "CONSUMER_MIN_BYTES", "must be positive and not above CONSUMER_MAX_BYTES")
+	c.Security.validate(v)
+}
+
+func (p ProducerConfig) validate(v *validator) {
+	v.check(len(p.Brokers) > 0, "PRODUCER_BROKERS", "must not be empty")
+	v.check(p.Topic != "", "PRODUCER_TOPIC", "must not be empty")
+	v.oneOf("PRODUCER_ACKS", p.Acks, string(kafka.AcksNone), string(kafka.AcksOne), string(kafka.AcksAll))
+	v.oneOf("PRODUCER_COMPRESSION", p.Compression, "none", "gzip", "snappy", "lz4", "zstd")
+	v.check(p.BatchSize > 0, "PRODUCER_BATCH_SIZE", "must be positive")
+	p.Security.validate(v)
+}
+
+func (t TopicsConfig) validate(v *validator) {
+	v.oneOf("TOPICS_MODE", t.Mode, TopicsModeOff, TopicsModeValidate, TopicsModeCreate)
+	if t.Mode == TopicsModeOff {
+		return
+	}
+	v.positive("TOPICS_TIMEOUT", t.Timeout)
+	v.check(t.TransactionsPartitions > 0, "TOPICS_TRANSACTIONS_PARTITIONS", "must be positive")
+	v.check(t.TransactionsReplicationFactor > 0, "TOPICS_TRANSACTIONS_REPLICATION_FACTOR", "must be positive")
+	if t.DLQName != "" {
+		v.check(t.DLQPartitions > 0, "TOPICS_DLQ_PARTITIONS", "must be positive")
+		v.check(t.DLQReplicationFactor > 0, "TOPICS_DLQ_REPLICATION_FACTOR", "must be positive")
+	}
+	v.check(t.EventsPartitions > 0, "TOPICS_EVENTS_PARTITIONS", "must be positive")
+	v.check(t.EventsReplicationFactor > 0, "TOPICS_EVENTS_REPLICATION_FACTOR", "must be positive")
+}
+
+func (k KafkaSecurityConfig) validate(v *validator) {
+	v.oneOf("KAFKA_SASL_MECHANISM", k.SASLMechanism,
+		string(kafka.SASLNone), string(kafka.SASLPlain), string(kafka.SASLScramSHA256), string(kafka.SASLScramSHA512))
+	if k.SASLMechanism != string(kafka.SASLNone) {
+		v.check(k.SASLUsername != "", "KAFKA_SASL_USERNAME", "is required with KAFKA_SASL_MECHANISM")
+	}
+}
+
+// validator collects every invalid field instead of stopping at the first one. Repeated errors,
+// e.g. of the kafka security settings shared by the consumer and the producer, are reported once.
+type validator struct {
+	errs []error
+}
+
+func (v *validator) check(ok bool, env, format string, args ...any) {
+	if ok {
+		return
+	}
+	err := fmt.Errorf("%s: %s", env, fmt.Sprintf(format, args...))
+	for _, e := range v.errs {
+		if e.Error() == err.Error() {
+			return
+		}
+	}
+	v.errs = append(v.errs, err)
+}
+
+func (v *validator) oneOf(env, value string, allowed ...string) {
+	v.check(slices.Contains(allowed, value), env, "%q is not one of %q", value, allowed)
+}
+
+func (v *validator) positive(env string, d time.Duration) {
+	v.check(d > 0, env, "must be positive, got %v", d)
+}
+
+func (v *validator) nonNegative(env string, d time.Duration) {
+	v.check(d >= 0, env, "must not be negative, got %v", d)
+}
+
+// basicAuth requires the username and the password of prefix_USERNAME and prefix_PASSWORD together.
+func (v *validator) basicAuth(prefix, username, password string) {
+	v.check((username == "") == (password == ""), prefix+"_PASSWORD", "must be set together with %s_USERNAME", prefix)
+}
+
+func (v *validator) addr(env, addr string) {
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		v.check(false, env, "%q is not a listen address: %v", addr, err)
+		return
+	}
+	p, err := strconv.Atoi(port)
+	v.check(err == nil && p >= 0 && p < 1<<16, env, "%q has an invalid port", addr)
+}
+
+// distinct reports listen addresses used by more than one server.
+func (v *validator) distinct(listeners map[string]string) {
+	envs := make([]string, 0, len(listeners))
+	for env := range listeners {
+		envs = append(envs, env)
+	}
+	slices.Sort(envs)
+
+	for i, env := range envs {
+		for _, other := range envs[i+1:] {
+			v.check(listeners[env] != listeners[other], env, "%q is also used by %s", listeners[env], other)
+		}
+	}
+}
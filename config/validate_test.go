@@ -0,0 +1,77 @@
+package config
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ilyakaznacheev/cleanenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func defaultConfig(t *testing.T) *Config {
+	cfg := new(Config)
+	require.NoError(t, cleanenv.ReadEnv(cfg))
+	return cfg
+}
+
+func TestConfig_Validate(t *testing.T) {
+	assert.NoError(t, defaultConfig(t).Validate())
+
+	cfg := defaultConfig(t)
+	cfg.HTTPServer.Addr = "8080"
+	cfg.Metrics.Addr = cfg.PProf.Addr
+	cfg.Database.SSLMode = "off"
+	cfg.Database.MinConns = 10
+	cfg.Database.MaxConns = 5
+	cfg.Workers.Max = 1
+	cfg.Broker.Backend = "rabbitmq"
+	cfg.CORS.AllowCredentials = true
+
+	err := cfg.Validate()
+	assert.True(t, errors.Is(err, ErrInvalidConfig))
+	for _, env := range []string{"HTTP_SERVER_ADDR", "METRICS_ADDR", "DB_SSLMODE", "DB_MIN_CONNS", "WORKERS_MAX", "BROKER_BACKEND", "CORS_ALLOW_CREDENTIALS"} {
+		assert.Contains(t, err.Error(), env+":")
+	}
+}
+
+func TestConfig_ValidateWebhook(t *testing.T) {
+	cfg := defaultConfig(t)
+	cfg.Webhook.BatchSize = 0
+	cfg.Webhook.Timeout = 30 * time.Second
+	cfg.Webhook.Lease = 30 * time.Second
+	cfg.Webhook.BackoffBase = time.Hour
+	cfg.Webhook.BackoffMax = time.Minute
+	cfg.Webhook.AllowedNetworks = []string{"10.0.0.0/8", "localhost"}
+
+	err := cfg.Validate()
+	assert.True(t, errors.Is(err, ErrInvalidConfig))
+	for _, env := range []string{"WEBHOOK_BATCH_SIZE", "WEBHOOK_LEASE", "WEBHOOK_BACKOFF_MAX", "WEBHOOK_ALLOWED_NETWORKS"} {
+		assert.Contains(t, err.Error(), env+":")
+	}
+	assert.NotContains(t, err.Error(), "WEBHOOK_TIMEOUT:")
+	assert.NotContains(t, err.Error(), `"10.0.0.0/8"`)
+}
+
+func TestConfig_ValidatePostgresBroker(t *testing.T) {
+	cfg := defaultConfig(t)
+	cfg.Broker.Backend = BrokerBackendPostgres
+	cfg.Producer.Brokers = nil
+	cfg.Producer.Acks = ""
+	cfg.Topics.Mode = "unknown"
+
+	// domain events need Kafka, other kafka settings are not used
+	err := cfg.Validate()
+	assert.True(t, errors.Is(err, ErrInvalidConfig))
+	assert.Contains(t, err.Error(), "DOMAIN_EVENTS_ENABLED:")
+	for _, env := range []string{"PRODUCER_BROKERS", "PRODUCER_ACKS", "TOPICS_MODE"} {
+		assert.NotContains(t, err.Error(), env+":")
+	}
+
+	cfg.DomainEvents.Enabled = false
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Producer.Encoding = "xml"
+	assert.ErrorContains(t, cfg.Validate(), "PRODUCER_ENCODING:")
+}
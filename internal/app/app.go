@@ -4,30 +4,46 @@ import (
 	"context"
 	"github.com/gorilla/mux"
 	"github.com/rs/cors"
+	"google.golang.org/grpc"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 	"wallet/config"
+	"wallet/internal/entity"
+	"wallet/internal/infrastructure/auth/jwt"
+	"wallet/internal/infrastructure/broker"
 	"wallet/internal/infrastructure/broker/kafka"
+	"wallet/internal/infrastructure/broker/pgqueue"
 	"wallet/internal/infrastructure/cache/redis"
 	"wallet/internal/infrastructure/database/postgres"
+	"wallet/internal/infrastructure/webhook"
+	grpcAPI "wallet/internal/interface/grpc/v1/api"
+	"wallet/internal/interface/http/middleware"
 	"wallet/internal/interface/http/v1/api"
 	"wallet/internal/presenter"
+	apiKeyRepository "wallet/internal/repository/apikey"
+	domainEventRepository "wallet/internal/repository/domainevent"
+	eventRepository "wallet/internal/repository/event"
 	transactionRepository "wallet/internal/repository/transaction"
 	walletRepository "wallet/internal/repository/wallet"
+	webhookRepository "wallet/internal/repository/webhook"
 	"wallet/internal/service"
+	"wallet/internal/utils/grpcserver"
 	"wallet/internal/utils/httpserver"
 	"wallet/internal/utils/metrics"
 	"wallet/internal/utils/pprof"
+	"wallet/internal/utils/ratelimit"
 
 	httpSwagger "github.com/swaggo/http-swagger/v2"
 	_ "wallet/docs" // docs generated by Swag CLI, you have to import it.
 )
 
 const (
-	pathToAPI = "/api/v1"
+	pathToAPI   = "/api/v1"
+	pathToAdmin = "/admin"
 
 	shutdownTimeout = 5 * time.Second
 )
@@ -52,65 +68,292 @@ func Run(cfg *config.Config) {
 		}
 	}()
 
-	consumer, err := kafka.NewConsumer(cfg.Consumer.Convert())
+	var (
+		transactionRepo *transactionRepository.Repository
+		consumerReady   metrics.ReadinessCheck
+		serviceOpts     []service.Option
+	)
+	switch cfg.Broker.Backend {
+	case config.BrokerBackendPostgres:
+		queue, err := pgqueue.New(store.Pool(), cfg.Broker.Queue(cfg.Consumer.Topic))
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer func() {
+			if err := queue.Close(); err != nil {
+				log.Println(err)
+			}
+		}()
+
+		// messages that can't be decoded are delivered again and moved to the dead letter topic of the queue
+		transactionRepo, err = transactionRepository.New(queue, queue, nil, cfg.Producer.Transactions())
+		if err != nil {
+			log.Fatal(err)
+		}
+		consumerReady = queue.Ready
+	case config.BrokerBackendKafka:
+		switch cfg.Topics.Mode {
+		case config.TopicsModeOff:
+		case config.TopicsModeValidate, config.TopicsModeCreate:
+			if err := ensureTopics(ctx, cfg); err != nil {
+				log.Fatal(err)
+			}
+		default:
+			log.Fatalf("unknown topics mode %q", cfg.Topics.Mode)
+		}
+
+		consumer, err := kafka.NewConsumer(cfg.Consumer.Convert())
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer func() {
+			if err := consumer.Close(); err != nil {
+				log.Println(err)
+			}
+		}()
+
+		producer, err := kafka.NewProducer(cfg.Producer.Convert())
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer func() {
+			if err := producer.Close(); err != nil {
+				log.Println(err)
+			}
+		}()
+
+		// without a DLQ topic undecodable messages are only logged
+		var deadLetters interface {
+			Publish(context.Context, broker.Message) error
+		}
+		if cfg.Topics.DLQName != "" {
+			dlqProducer, err := kafka.NewProducer(cfg.Topics.DLQProducer(cfg.Producer))
+			if err != nil {
+				log.Fatal(err)
+			}
+			defer func() {
+				if err := dlqProducer.Close(); err != nil {
+					log.Println(err)
+				}
+			}()
+			deadLetters = dlqProducer
+		}
+
+		transactionRepo, err = transactionRepository.New(consumer, producer, deadLetters, cfg.Producer.Transactions())
+		if err != nil {
+			log.Fatal(err)
+		}
+		consumerReady = consumer.Ready
+		serviceOpts = append(serviceOpts, service.WithConsumerLag(consumer))
+	default:
+		log.Fatalf("unknown broker backend %q", cfg.Broker.Backend)
+	}
+
+	walletRepo := walletRepository.New(cache, cfg.Cache.Wallets())
+
+	var walletCache walletRepository.BalanceCache = walletRepo
+	if cfg.Cache.LocalEnabled {
+		walletCache = walletRepository.NewLocalCache(ctx, walletRepo, cache, cfg.Cache.Local())
+	}
+
+	eventRepo := eventRepository.New(cache, cfg.Events.Convert())
+	eventHub := service.NewEventHub(ctx, eventRepo, cfg.Events.BufferSize)
+
+	webhookRepo := webhookRepository.New()
+
+	locking, err := service.ParseLockingMode(cfg.Consumer.Locking)
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer func() {
-		if err := consumer.Close(); err != nil {
-			log.Println(err)
+
+	serviceOpts = append(serviceOpts,
+		service.WithLocking(locking),
+		service.WithEventPublisher(eventRepo),
+		service.WithWebhookOutbox(webhookRepo),
+	)
+
+	if cfg.DomainEvents.Enabled {
+		domainEventProducer, err := kafka.NewProducer(cfg.DomainEvents.Producer(cfg.Producer))
+		if err != nil {
+			log.Fatal(err)
 		}
-	}()
+		defer func() {
+			if err := domainEventProducer.Close(); err != nil {
+				log.Println(err)
+			}
+		}()
 
-	producer, err := kafka.NewProducer(cfg.Producer.Convert())
+		serviceOpts = append(serviceOpts, service.WithDomainEvents(
+			domainEventRepository.New(domainEventProducer, cfg.DomainEvents.Convert()),
+		))
+	}
+
+	walletService := service.New(ctx, walletRepo, transactionRepo, transactionRepo, walletCache, store, cfg.Workers.Convert(), serviceOpts...)
+
+	webhookSender, err := webhook.NewSender(cfg.Webhook.Sender())
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer func() {
-		if err := producer.Close(); err != nil {
-			log.Println(err)
-		}
-	}()
 
-	walletRepo := walletRepository.New(cache)
-	transactionRepo := transactionRepository.New(consumer, producer)
+	webhookService := service.NewWebhookService(webhookRepo, webhookSender, store, cfg.Webhook.Convert())
+	if cfg.Webhook.WorkerEnabled {
+		go webhookService.Run(ctx)
+	}
 
-	walletService := service.New(ctx, walletRepo, transactionRepo, transactionRepo, walletRepo, store, 20)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepository.New(), store, cfg.Auth.Convert())
 
-	walletPresenter := presenter.NewPresenter(walletService)
+	walletPresenter := presenter.NewPresenter(walletService, eventHub)
+	apiKeyPresenter := presenter.NewAPIKeyPresenter(apiKeyService)
+	webhookPresenter := presenter.NewWebhookPresenter(webhookService, walletService)
 
 	router := mux.NewRouter()
 	router.Use(
+		middleware.RequestID,
+		metrics.MW,
+		middleware.ClientCertificate,
+	)
+
+	// opsRouter serves pprof, metrics and the admin api when they share the admin port
+	opsRouter := mux.NewRouter()
+	opsRouter.Use(
+		middleware.RequestID,
 		metrics.MW,
 	)
 
+	router.PathPrefix(pathToAPI + "/swagger/").HandlerFunc(httpSwagger.WrapHandler)
+
 	walletRouter := router.PathPrefix(pathToAPI).Subrouter()
-	walletRouter.PathPrefix("/swagger/").HandlerFunc(httpSwagger.WrapHandler)
 	api.RegisterRouter(walletRouter, walletPresenter)
+	api.RegisterEventsRouter(walletRouter, walletPresenter, cfg.Events.HeartbeatInterval)
+	api.RegisterWebhookRouter(walletRouter, webhookPresenter)
+
+	if len(cfg.HTTPServer.TLSClientAllowed) > 0 {
+		walletRouter.Use(middleware.RequireClientCertificate(cfg.HTTPServer.TLSClientAllowed))
+	}
+
+	var grpcOptions []grpc.ServerOption
 
-	profilerServer := pprof.NewPProfServer(cfg.PProf.Convert())
-	go profilerServer.Run()
+	if cfg.Auth.Enabled {
+		authenticate := middleware.Authenticate(apiKeyService, nil)
+		grpcAuth := grpcAPI.NewAuth(apiKeyService, nil)
+		if cfg.JWT.Enabled() {
+			verifier, err := jwt.NewVerifier(ctx, cfg.JWT.Convert())
+			if err != nil {
+				log.Fatal(err)
+			}
+			defer verifier.Close()
+
+			authenticate = middleware.Authenticate(apiKeyService, verifier)
+			grpcAuth = grpcAPI.NewAuth(apiKeyService, verifier)
+		}
+
+		grpcOptions = append(grpcOptions,
+			grpc.ChainUnaryInterceptor(grpcAuth.Unary),
+			grpc.ChainStreamInterceptor(grpcAuth.Stream),
+		)
+
+		walletRouter.Use(
+			authenticate,
+			middleware.Authorize(middleware.ScopeByMethod),
+		)
+
+		adminRouter := walletRouter.PathPrefix(pathToAdmin).Subrouter()
+		if cfg.AdminServer.Enabled() {
+			adminRouter = opsRouter.PathPrefix(pathToAPI + pathToAdmin).Subrouter()
+			adminRouter.Use(authenticate)
+		}
+		adminRouter.Use(
+			middleware.Authorize(middleware.FixedScope(entity.ScopeWalletAdmin)),
+		)
+		api.RegisterAdminRouter(adminRouter, apiKeyPresenter, walletPresenter, walletPresenter)
+	} else {
+		log.Println("authentication is disabled, admin api is not mounted")
+	}
+
+	if cfg.RateLimit.Enabled {
+		var clientLimiter, walletLimiter ratelimit.Limiter
+		switch cfg.RateLimit.Backend {
+		case config.RateLimitBackendRedis:
+			clientLimiter = ratelimit.NewRedis(cache, cfg.RateLimit.Client())
+			walletLimiter = ratelimit.NewRedis(cache, cfg.RateLimit.Wallet())
+		default:
+			clientLimiter = ratelimit.NewLocal(cfg.RateLimit.Client())
+			walletLimiter = ratelimit.NewLocal(cfg.RateLimit.Wallet())
+		}
+
+		walletRouter.Use(
+			middleware.RateLimit(clientLimiter, "client", middleware.ClientKey),
+			middleware.RateLimit(walletLimiter, "wallet", middleware.WalletKey),
+		)
+
+		// chained after the auth interceptors, so clients are limited by principal
+		grpcClientLimit := grpcAPI.NewRateLimit(clientLimiter, "client", grpcAPI.ClientKey)
+		grpcWalletLimit := grpcAPI.NewRateLimit(walletLimiter, "wallet", grpcAPI.WalletKey)
+		grpcOptions = append(grpcOptions,
+			grpc.ChainUnaryInterceptor(grpcClientLimit.Unary, grpcWalletLimit.Unary),
+			grpc.ChainStreamInterceptor(grpcClientLimit.Stream, grpcWalletLimit.Stream),
+		)
+	}
 
 	metricsServer := metrics.NewMetricsServer(cfg.Metrics.Convert())
-	go metricsServer.Run()
+	metricsServer.AddReadinessCheck("consumer", consumerReady)
+
+	var profilerServer *pprof.Server
+	if cfg.PProf.Enabled {
+		profilerServer = pprof.NewPProfServer(cfg.PProf.Convert())
+	}
+
+	var metricsNotify, profilerNotify, adminNotify chan error
+	var adminServer *httpserver.Server
+	if cfg.AdminServer.Enabled() {
+		opsRouter.Handle("/metrics", metricsServer)
+		opsRouter.Handle("/readyz", metricsServer)
+		if profilerServer != nil {
+			opsRouter.PathPrefix("/debug/pprof").Handler(profilerServer)
+		}
 
-	// Настройка CORS с необходимыми параметрами для работы со Swagger контейнеризированного приложения
-	c := cors.New(cors.Options{
-		AllowedOrigins:   []string{"*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Content-Type", "Authorization"},
-		ExposedHeaders:   []string{},
-		AllowCredentials: true,
-		MaxAge:           3600, // Кеширование CORS настроек
-	})
+		adminServer, err = httpserver.NewHTTPServer(cfg.AdminServer.Convert(), opsRouter)
+		if err != nil {
+			log.Fatal(err)
+		}
+		adminNotify = adminServer.Notify()
+		go adminServer.Run()
 
-	handler := c.Handler(router)
+		log.Println("admin server started on", cfg.AdminServer.Addr)
+	} else {
+		metricsNotify = metricsServer.Notify()
+		go metricsServer.Run()
 
-	server := httpserver.NewHTTPServer(cfg.HTTPServer.Convert(), handler)
+		if profilerServer != nil {
+			profilerNotify = profilerServer.Notify()
+			go profilerServer.Run()
+		}
+	}
+
+	var handler http.Handler = router
+	if cfg.CORS.Enabled {
+		handler = cors.New(cfg.CORS.Convert()).Handler(router)
+	}
+
+	server, err := httpserver.NewHTTPServer(cfg.HTTPServer.Convert(), handler)
+	if err != nil {
+		log.Fatal(err)
+	}
 	go server.Run()
 
 	log.Println("server started on", cfg.HTTPServer.Convert().Addr)
 
+	var grpcNotify chan error
+	var grpcServer *grpcserver.Server
+	if cfg.GRPCServer.Enabled {
+		grpcServer = grpcserver.NewGRPCServer(cfg.GRPCServer.Convert(), grpcOptions...)
+		grpcAPI.RegisterServer(grpcServer, walletPresenter)
+		grpcNotify = grpcServer.Notify()
+		go grpcServer.Run()
+
+		log.Println("grpc server started on", cfg.GRPCServer.Convert().Addr)
+	}
+
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, syscall.SIGTERM, syscall.SIGINT, os.Interrupt)
 
@@ -119,10 +362,14 @@ func Run(cfg *config.Config) {
 		log.Println("syscall stop", sig.String())
 	case err := <-server.Notify():
 		log.Println("http server notify: ", err)
-	case err := <-metricsServer.Notify():
+	case err := <-grpcNotify:
+		log.Println("grpc server notify: ", err)
+	case err := <-metricsNotify:
 		log.Println("metrics notify: ", err)
-	case err := <-profilerServer.Notify():
+	case err := <-profilerNotify:
 		log.Println("profiler notify: ", err)
+	case err := <-adminNotify:
+		log.Println("admin server notify: ", err)
 	}
 
 	shutdownCtx, shutdownCancel := context.WithTimeout(ctx, shutdownTimeout)
@@ -132,13 +379,41 @@ func Run(cfg *config.Config) {
 		log.Printf("http server shutdown error: %v\n", err)
 	}
 
+	if grpcServer != nil {
+		if err := grpcServer.Shutdown(shutdownCtx); err != nil {
+			log.Printf("grpc server shutdown error: %v\n", err)
+		}
+	}
+
 	if err := metricsServer.Shutdown(shutdownCtx); err != nil {
 		log.Printf("metrics server shutdown error: %v\n", err)
 	}
 
-	if err := profilerServer.Shutdown(shutdownCtx); err != nil {
-		log.Printf("pprof server shutdown error: %v\n", err)
+	if profilerServer != nil {
+		if err := profilerServer.Shutdown(shutdownCtx); err != nil {
+			log.Printf("pprof server shutdown error: %v\n", err)
+		}
+	}
+
+	if adminServer != nil {
+		if err := adminServer.Shutdown(shutdownCtx); err != nil {
+			log.Printf("admin server shutdown error: %v\n", err)
+		}
 	}
 
 	log.Println("service exit")
 }
+
+// ensureTopics runs before the consumer and the producers are created, they fail on missing topics.
+func ensureTopics(ctx context.Context, cfg *config.Config) error {
+	admin, err := kafka.NewAdmin(cfg.Producer.Brokers, cfg.Producer.Security.Convert())
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Topics.Timeout)
+	defer cancel()
+
+	specs := cfg.Topics.Specs(cfg.Consumer, cfg.Producer, cfg.DomainEvents)
+	return admin.EnsureTopics(ctx, specs, cfg.Topics.Mode == config.TopicsModeCreate)
+}
@@ -1,12 +1,26 @@
 package dto
 
+import "time"
+
 type GetBalanceResponse struct {
 	Amount int64 `json:"amount"`
 }
 
+type CreateWalletRequest struct {
+	Owner string `json:"owner,omitempty"`
+}
+
 type WalletResponse struct {
 	UUID   string `json:"uuid"`
+	Owner  string `json:"owner,omitempty"`
 	Amount int64  `json:"amount"`
+	Frozen bool   `json:"frozen"`
+}
+
+type WorkerStatusResponse struct {
+	Paused bool `json:"paused"`
+	Active int  `json:"active"`
+	Busy   int  `json:"busy"`
 }
 
 type PostOperationRequest struct {
@@ -15,6 +29,93 @@ type PostOperationRequest struct {
 	Amount        int64  `json:"amount"`
 }
 
-type ErrorResponse struct {
-	Message string `json:"message"`
+type TransactionResponse struct {
+	IdempotencyKey string    `json:"idempotencyKey"`
+	WalletId       string    `json:"walletId"`
+	OperationType  string    `json:"operationType"`
+	Amount         int64     `json:"amount"`
+	Status         string    `json:"status"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+type WalletEvent struct {
+	ID             string `json:"-"`
+	Type           string `json:"type"`
+	WalletId       string `json:"walletId"`
+	Balance        *int64 `json:"balance,omitempty"`
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
+	Status         string `json:"status,omitempty"`
+}
+
+// Problem is an RFC 7807 error response, served as application/problem+json.
+type Problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	// Code is stable and meant for clients to branch on, unlike Detail.
+	Code   string `json:"code"`
+	Detail string `json:"detail,omitempty"`
+	// Instance is the id of the request, the same as the X-Request-ID header.
+	Instance      string         `json:"instance,omitempty"`
+	InvalidParams []InvalidParam `json:"invalidParams,omitempty"`
+}
+
+// InvalidParam is an invalid field of the request body.
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Code   string `json:"code"`
+	Reason string `json:"reason"`
+}
+
+type IssueAPIKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+type APIKeyResponse struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Prefix    string     `json:"prefix"`
+	Scopes    []string   `json:"scopes"`
+	CreatedAt time.Time  `json:"createdAt"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+}
+
+type IssueAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+type CreateWebhookRequest struct {
+	URL      string `json:"url"`
+	WalletId string `json:"walletId,omitempty"`
+}
+
+type WebhookResponse struct {
+	ID           string     `json:"id"`
+	URL          string     `json:"url"`
+	WalletId     string     `json:"walletId,omitempty"`
+	Enabled      bool       `json:"enabled"`
+	FailingSince *time.Time `json:"failingSince,omitempty"`
+	DisabledAt   *time.Time `json:"disabledAt,omitempty"`
+	CreatedAt    time.Time  `json:"createdAt"`
+}
+
+type CreateWebhookResponse struct {
+	WebhookResponse
+	Secret string `json:"secret"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID             int64      `json:"id"`
+	EventID        string     `json:"eventId"`
+	EventType      string     `json:"eventType"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"nextAttemptAt"`
+	LastStatusCode *int       `json:"lastStatusCode,omitempty"`
+	LastError      *string    `json:"lastError,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	DeliveredAt    *time.Time `json:"deliveredAt,omitempty"`
 }
@@ -0,0 +1,107 @@
+package entity
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Scope string
+
+var (
+	ScopeWalletRead  Scope = "wallet:read"
+	ScopeWalletWrite Scope = "wallet:write"
+	ScopeWalletAdmin Scope = "wallet:admin"
+)
+
+const (
+	apiKeyPrefix      = "wk_"
+	apiKeyPrefixBytes = 4
+	apiKeySecretBytes = 32
+)
+
+type APIKey struct {
+	ID        uuid.UUID
+	Name      string
+	Prefix    string
+	Hash      string
+	Scopes    []Scope
+	CreatedAt time.Time
+	RevokedAt *time.Time
+}
+
+// NewAPIKey generates a new key and returns it together with the raw secret.
+// The raw secret is shown to the caller once and only its hash is kept.
+func NewAPIKey(name string, scopes []string) (*APIKey, string, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, "", ErrAPIKeyNameIsEmpty
+	}
+
+	parsed, err := ParseScopes(scopes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	buf := make([]byte, apiKeyPrefixBytes+apiKeySecretBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, "", err
+	}
+
+	prefix := apiKeyPrefix + hex.EncodeToString(buf[:apiKeyPrefixBytes])
+	raw := prefix + "_" + hex.EncodeToString(buf[apiKeyPrefixBytes:])
+
+	return &APIKey{
+		Name:      name,
+		Prefix:    prefix,
+		Hash:      HashAPIKey(raw),
+		Scopes:    parsed,
+		CreatedAt: time.Now(),
+	}, raw, nil
+}
+
+// HashAPIKey returns the stored representation of a raw key.
+// Keys carry 256 bits of entropy, so a fast hash is sufficient.
+func HashAPIKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+func (k *APIKey) IsRevoked() bool {
+	return k.RevokedAt != nil
+}
+
+func (k *APIKey) Revoke() {
+	now := time.Now()
+	k.RevokedAt = &now
+}
+
+func (k *APIKey) Principal() *Principal {
+	return &Principal{
+		Subject: "apikey:" + k.ID.String(),
+		Scopes:  k.Scopes,
+		Service: true,
+	}
+}
+
+func ParseScopes(scopes []string) ([]Scope, error) {
+	if len(scopes) == 0 {
+		return nil, ErrInvalidScope
+	}
+
+	parsed := make([]Scope, 0, len(scopes))
+	for _, s := range scopes {
+		scope := Scope(strings.ToLower(strings.TrimSpace(s)))
+		if !slices.Contains([]Scope{ScopeWalletRead, ScopeWalletWrite, ScopeWalletAdmin}, scope) {
+			return nil, ErrInvalidScope
+		}
+		if !slices.Contains(parsed, scope) {
+			parsed = append(parsed, scope)
+		}
+	}
+	return parsed, nil
+}
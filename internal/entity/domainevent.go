@@ -0,0 +1,72 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type DomainEventType string
+
+var (
+	WalletCreated      DomainEventType = "wallet.created"
+	OperationSucceeded DomainEventType = "wallet.operation.succeeded"
+	OperationFailed    DomainEventType = "wallet.operation.failed"
+	WalletFrozen       DomainEventType = "wallet.frozen"
+	WalletUnfrozen     DomainEventType = "wallet.unfrozen"
+)
+
+// DomainEvent is a committed fact about a wallet for downstream consumers.
+// Balance, Version and Frozen describe the wallet after the event.
+type DomainEvent struct {
+	ID         uuid.UUID
+	Type       DomainEventType
+	WalletUUID uuid.UUID
+	Owner      string
+	Balance    int64
+	Version    int64
+	Frozen     bool
+	Operation  *Transaction
+	Reason     string
+	OccurredAt time.Time
+}
+
+func newDomainEvent(eventType DomainEventType, w *Wallet) *DomainEvent {
+	return &DomainEvent{
+		ID:         uuid.New(),
+		Type:       eventType,
+		WalletUUID: w.UUID,
+		Owner:      w.Owner,
+		Balance:    w.Amount,
+		Version:    w.Version,
+		Frozen:     w.Frozen,
+		OccurredAt: time.Now(),
+	}
+}
+
+func NewWalletCreatedEvent(w *Wallet) *DomainEvent {
+	return newDomainEvent(WalletCreated, w)
+}
+
+func NewOperationSucceededEvent(w *Wallet, t *Transaction) *DomainEvent {
+	e := newDomainEvent(OperationSucceeded, w)
+	e.Operation = t
+	return e
+}
+
+// NewOperationFailedEvent describes a rejected operation, w is the wallet the operation was checked against.
+func NewOperationFailedEvent(w *Wallet, t *Transaction, reason error) *DomainEvent {
+	e := newDomainEvent(OperationFailed, w)
+	e.Operation = t
+	if reason != nil {
+		e.Reason = reason.Error()
+	}
+	return e
+}
+
+// NewFreezeEvent returns WalletFrozen or WalletUnfrozen depending on the state of the wallet.
+func NewFreezeEvent(w *Wallet) *DomainEvent {
+	if w.Frozen {
+		return newDomainEvent(WalletFrozen, w)
+	}
+	return newDomainEvent(WalletUnfrozen, w)
+}
@@ -10,8 +10,10 @@ import (
 
 type Wallet struct {
 	UUID      uuid.UUID
+	Owner     string
 	Amount    int64
 	Version   int64
+	Frozen    bool
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
@@ -32,11 +34,16 @@ func (w *Wallet) DoTransaction(t *Transaction) (*Wallet, error) {
 	if w.UUID != t.WalletUUID {
 		return nil, ErrInvalidOperationUUID
 	}
+	if w.Frozen {
+		return nil, ErrWalletFrozen
+	}
 
 	copyWallet := &Wallet{
 		UUID:      w.UUID,
+		Owner:     w.Owner,
 		Amount:    w.Amount,
 		Version:   w.Version,
+		Frozen:    w.Frozen,
 		CreatedAt: w.CreatedAt,
 		UpdatedAt: w.UpdatedAt,
 	}
@@ -77,6 +84,7 @@ type Transaction struct {
 	Operation      OperationType `json:"operation"`
 	Amount         int64         `json:"amount"`
 	Status         Status        `json:"status"`
+	Initiator      string        `json:"initiator,omitempty"`
 	CreatedAt      time.Time     `json:"created-at"`
 	UpdatedAt      time.Time     `json:"updated-at"`
 }
@@ -96,6 +104,10 @@ var (
 	Deposit  OperationType = "deposit"
 )
 
+func (o OperationType) IsValid() bool {
+	return o == Withdraw || o == Deposit
+}
+
 func NewOperation(walletUUID uuid.UUID, operationType string, amount int64) (*Transaction, error) {
 	operationType = strings.ToLower(operationType)
 
@@ -119,7 +131,7 @@ func (t *Transaction) isValid() error {
 	if t.WalletUUID == uuid.Nil {
 		return ErrWalletUUIDIsEmpty
 	}
-	if !slices.Contains([]OperationType{Withdraw, Deposit}, t.Operation) {
+	if !t.Operation.IsValid() {
 		return ErrInvalidOperationType
 	}
 	if !slices.Contains([]Status{New, Success, Failure}, t.Status) {
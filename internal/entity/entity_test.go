@@ -88,6 +88,21 @@ func TestWallet_Withdraw_InsufficientFunds(t *testing.T) {
 	}
 }
 
+func TestWallet_Frozen(t *testing.T) {
+	wallet := NewWallet()
+	wallet.UUID = uuid.New()
+	wallet.Amount = 200
+	wallet.Frozen = true
+
+	transaction, err := NewOperation(wallet.UUID, "DEPOSIT", 100)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	_, err = wallet.DoTransaction(transaction)
+	assert.ErrorIs(t, err, ErrWalletFrozen)
+}
+
 func TestNewOperation(t *testing.T) {
 	walletUUID := uuid.New()
 	transaction, err := NewOperation(walletUUID, "deposit", 100)
@@ -252,3 +267,52 @@ func TestTransaction_Unmarshall(t *testing.T) {
 		t.Errorf("Expected WalletUUID to be %v, got %v", transaction.WalletUUID, newTransaction.WalletUUID)
 	}
 }
+
+func TestNewAPIKey(t *testing.T) {
+	key, raw, err := NewAPIKey("merchant", []string{"WALLET:READ", "wallet:write", "wallet:read"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	assert.Equal(t, []Scope{ScopeWalletRead, ScopeWalletWrite}, key.Scopes)
+	assert.Equal(t, HashAPIKey(raw), key.Hash)
+	assert.NotEqual(t, raw, key.Hash)
+	assert.True(t, len(raw) > len(key.Prefix))
+	assert.Equal(t, key.Prefix, raw[:len(key.Prefix)])
+	assert.False(t, key.IsRevoked())
+
+	key.Revoke()
+	assert.True(t, key.IsRevoked())
+}
+
+func TestNewAPIKey_Invalid(t *testing.T) {
+	_, _, err := NewAPIKey("", []string{"wallet:read"})
+	assert.ErrorIs(t, err, ErrAPIKeyNameIsEmpty)
+
+	_, _, err = NewAPIKey("merchant", []string{"wallet:delete"})
+	assert.ErrorIs(t, err, ErrInvalidScope)
+
+	_, _, err = NewAPIKey("merchant", nil)
+	assert.ErrorIs(t, err, ErrInvalidScope)
+}
+
+func TestPrincipal_HasScope(t *testing.T) {
+	reader := &Principal{Scopes: []Scope{ScopeWalletRead}}
+	admin := &Principal{Scopes: []Scope{ScopeWalletAdmin}}
+
+	assert.True(t, reader.HasScope(ScopeWalletRead))
+	assert.False(t, reader.HasScope(ScopeWalletWrite))
+	assert.True(t, admin.HasScope(ScopeWalletWrite))
+	assert.True(t, admin.HasScope(ScopeWalletRead))
+}
+
+func TestPrincipal_CanAccess(t *testing.T) {
+	user := &Principal{Subject: "user-1", Scopes: []Scope{ScopeWalletRead}}
+	service := &Principal{Subject: "apikey:1", Scopes: []Scope{ScopeWalletRead}, Service: true}
+
+	assert.True(t, user.CanAccess("user-1"))
+	assert.False(t, user.CanAccess("user-2"))
+	assert.False(t, user.CanAccess(""))
+	assert.True(t, service.CanAccess("user-2"))
+	assert.True(t, service.CanAccess(""))
+}
@@ -5,8 +5,12 @@ import "errors"
 var (
 	ErrInvalidOperationType = errors.New("invalid operation type")
 	ErrNotEnoughFunds       = errors.New("not enough funds")
+	ErrWalletFrozen         = errors.New("wallet is frozen")
 	ErrWalletUUIDIsEmpty    = errors.New("wallet uuid is empty")
 	ErrInvalidOperationUUID = errors.New("invalid operation uuid")
 	ErrInvalidStatus        = errors.New("invalid operation status")
 	ErrAmountIsOrBelowZero  = errors.New("amount is or below zero")
+	ErrInvalidScope         = errors.New("invalid scope")
+	ErrAPIKeyNameIsEmpty    = errors.New("api key name is empty")
+	ErrInvalidWebhookURL    = errors.New("invalid webhook url")
 )
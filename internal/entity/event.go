@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type WalletEventType string
+
+var (
+	BalanceChanged           WalletEventType = "balance.changed"
+	TransactionStatusChanged WalletEventType = "transaction.status"
+)
+
+// WalletEvent notifies subscribers about a committed change of the wallet.
+// ID is assigned by the event store and orders events of one wallet.
+type WalletEvent struct {
+	ID             string          `json:"-"`
+	Type           WalletEventType `json:"type"`
+	WalletUUID     uuid.UUID       `json:"wallet-uuid"`
+	Balance        int64           `json:"balance,omitempty"`
+	IdempotencyKey uuid.UUID       `json:"idempotency-key,omitempty"`
+	Status         Status          `json:"status,omitempty"`
+	CreatedAt      time.Time       `json:"created-at"`
+}
+
+func NewBalanceChangedEvent(w *Wallet) *WalletEvent {
+	return &WalletEvent{
+		Type:       BalanceChanged,
+		WalletUUID: w.UUID,
+		Balance:    w.Amount,
+		CreatedAt:  time.Now(),
+	}
+}
+
+func NewTransactionStatusEvent(t *Transaction) *WalletEvent {
+	return &WalletEvent{
+		Type:           TransactionStatusChanged,
+		WalletUUID:     t.WalletUUID,
+		IdempotencyKey: t.IdempotencyKey,
+		Status:         t.Status,
+		CreatedAt:      time.Now(),
+	}
+}
@@ -0,0 +1,67 @@
+package entity
+
+import (
+	"context"
+	"slices"
+)
+
+// Principal is an authenticated caller of the api.
+type Principal struct {
+	Subject string
+	Scopes  []Scope
+	// Service principals act on behalf of other subjects and are not restricted to their own wallets.
+	Service bool
+}
+
+// HasScope reports whether the principal is granted the scope. wallet:admin grants every scope.
+func (p *Principal) HasScope(scope Scope) bool {
+	return slices.Contains(p.Scopes, scope) || slices.Contains(p.Scopes, ScopeWalletAdmin)
+}
+
+// CanAccess reports whether the principal may operate on a wallet of the owner.
+// Wallets without owner are accessible to service principals only.
+func (p *Principal) CanAccess(owner string) bool {
+	return p.Service || p.HasScope(ScopeWalletAdmin) || (owner != "" && owner == p.Subject)
+}
+
+type principalKey struct{}
+
+func ContextWithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
+
+// ClientIdentity is the caller identified by a verified TLS client certificate.
+type ClientIdentity struct {
+	// Subject is the common name of the certificate.
+	Subject  string
+	DNSNames []string
+	// URIs hold e.g. SPIFFE ids of workloads.
+	URIs         []string
+	SerialNumber string
+}
+
+// Names are the names the client may be authorized by: the subject, DNS names and URIs.
+func (c *ClientIdentity) Names() []string {
+	names := make([]string, 0, 1+len(c.DNSNames)+len(c.URIs))
+	if c.Subject != "" {
+		names = append(names, c.Subject)
+	}
+	names = append(names, c.DNSNames...)
+	return append(names, c.URIs...)
+}
+
+type clientIdentityKey struct{}
+
+func ContextWithClientIdentity(ctx context.Context, c *ClientIdentity) context.Context {
+	return context.WithValue(ctx, clientIdentityKey{}, c)
+}
+
+func ClientIdentityFromContext(ctx context.Context) (*ClientIdentity, bool) {
+	c, ok := ctx.Value(clientIdentityKey{}).(*ClientIdentity)
+	return c, ok
+}
@@ -0,0 +1,135 @@
+package entity
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	webhookSecretPrefix = "whsec_"
+	webhookSecretBytes  = 32
+	webhookSignatureV1  = "v1="
+)
+
+// Webhook subscribes an endpoint to transaction outcomes. Webhooks bound to a wallet receive
+// every transaction of the wallet, the others receive transactions initiated by their Subject.
+type Webhook struct {
+	ID           uuid.UUID
+	Subject      string
+	WalletUUID   *uuid.UUID
+	URL          string
+	Secret       string
+	Enabled      bool
+	FailingSince *time.Time
+	DisabledAt   *time.Time
+	CreatedAt    time.Time
+}
+
+func NewWebhook(subject string, walletUUID *uuid.UUID, rawURL string) (*Webhook, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, ErrInvalidWebhookURL
+	}
+
+	buf := make([]byte, webhookSecretBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+
+	return &Webhook{
+		Subject:    subject,
+		WalletUUID: walletUUID,
+		URL:        u.String(),
+		Secret:     webhookSecretPrefix + hex.EncodeToString(buf),
+		Enabled:    true,
+		CreatedAt:  time.Now(),
+	}, nil
+}
+
+// SignWebhook returns the signature of a delivery: HMAC-SHA256 of "<timestamp>.<body>" keyed by the webhook secret.
+// Receivers should reject deliveries with a timestamp too far from their clock to prevent replays.
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return webhookSignatureV1 + hex.EncodeToString(mac.Sum(nil))
+}
+
+type WebhookEventType string
+
+var (
+	WebhookTransactionSucceeded WebhookEventType = "transaction.succeeded"
+	WebhookTransactionFailed    WebhookEventType = "transaction.failed"
+)
+
+// WebhookEvent is the body posted to webhook endpoints.
+type WebhookEvent struct {
+	ID        uuid.UUID          `json:"id"`
+	Type      WebhookEventType   `json:"type"`
+	CreatedAt time.Time          `json:"createdAt"`
+	Data      WebhookTransaction `json:"data"`
+}
+
+type WebhookTransaction struct {
+	IdempotencyKey uuid.UUID     `json:"idempotencyKey"`
+	WalletUUID     uuid.UUID     `json:"walletId"`
+	Operation      OperationType `json:"operationType"`
+	Amount         int64         `json:"amount"`
+	Status         Status        `json:"status"`
+	Initiator      string        `json:"initiator,omitempty"`
+	UpdatedAt      time.Time     `json:"updatedAt"`
+}
+
+func NewWebhookEvent(t *Transaction) *WebhookEvent {
+	eventType := WebhookTransactionSucceeded
+	if t.Status == Failure {
+		eventType = WebhookTransactionFailed
+	}
+
+	return &WebhookEvent{
+		ID:        uuid.New(),
+		Type:      eventType,
+		CreatedAt: time.Now(),
+		Data: WebhookTransaction{
+			IdempotencyKey: t.IdempotencyKey,
+			WalletUUID:     t.WalletUUID,
+			Operation:      t.Operation,
+			Amount:         t.Amount,
+			Status:         t.Status,
+			Initiator:      t.Initiator,
+			UpdatedAt:      t.UpdatedAt,
+		},
+	}
+}
+
+type DeliveryStatus string
+
+var (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliveryDelivered DeliveryStatus = "delivered"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+// WebhookDelivery is a queued or finished delivery of one event to one webhook.
+type WebhookDelivery struct {
+	ID             int64
+	WebhookID      uuid.UUID
+	EventID        uuid.UUID
+	EventType      WebhookEventType
+	Payload        []byte
+	Status         DeliveryStatus
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastStatusCode *int
+	LastError      *string
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+}
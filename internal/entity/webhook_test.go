@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewWebhook(t *testing.T) {
+	walletUUID := uuid.New()
+
+	w, err := NewWebhook("apikey:1", &walletUUID, "https://merchant.example/hooks")
+	assert.NoError(t, err)
+	assert.True(t, w.Enabled)
+	assert.Equal(t, &walletUUID, w.WalletUUID)
+	assert.Regexp(t, "^whsec_[0-9a-f]{64}$", w.Secret)
+
+	for _, url := range []string{"", "merchant.example/hooks", "ftp://merchant.example", "https://"} {
+		_, err := NewWebhook("apikey:1", nil, url)
+		assert.ErrorIs(t, err, ErrInvalidWebhookURL, url)
+	}
+}
+
+func TestSignWebhook(t *testing.T) {
+	body := []byte(`{"id":"1"}`)
+
+	mac := hmac.New(sha256.New, []byte("whsec_test"))
+	mac.Write([]byte(`1700000000.{"id":"1"}`))
+
+	assert.Equal(t, "v1="+hex.EncodeToString(mac.Sum(nil)), SignWebhook("whsec_test", 1700000000, body))
+	assert.NotEqual(t, SignWebhook("whsec_test", 1700000000, body), SignWebhook("whsec_test", 1700000001, body))
+}
+
+func TestNewWebhookEvent(t *testing.T) {
+	tr := &Transaction{WalletUUID: uuid.New(), IdempotencyKey: uuid.New(), Operation: Withdraw, Amount: 10, Status: Success}
+	assert.Equal(t, WebhookTransactionSucceeded, NewWebhookEvent(tr).Type)
+
+	tr.StatusFailure()
+	assert.Equal(t, WebhookTransactionFailed, NewWebhookEvent(tr).Type)
+}
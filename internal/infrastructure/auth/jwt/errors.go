@@ -0,0 +1,10 @@
+package jwt
+
+import "errors"
+
+var (
+	ErrInvalidToken   = errors.New("invalid token")
+	ErrUnknownKey     = errors.New("unknown signing key")
+	ErrNoJWKSSource   = errors.New("jwks file or url is required")
+	ErrUnsupportedJWK = errors.New("unsupported jwk")
+)
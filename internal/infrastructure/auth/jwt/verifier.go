@@ -0,0 +1,200 @@
+package jwt
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+	"wallet/internal/entity"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+)
+
+const fetchTimeout = 10 * time.Second
+
+var validMethods = []string{
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+	"EdDSA",
+}
+
+type Config struct {
+	JWKSFile        string
+	JWKSURL         string
+	RefreshInterval time.Duration
+	Issuer          string
+	Audience        string
+	Leeway          time.Duration
+	// ServiceScope marks tokens of internal services which are not restricted to their own wallets.
+	ServiceScope string
+	// DefaultScopes are granted to tokens without any known wallet scope.
+	DefaultScopes []string
+}
+
+type claims struct {
+	gojwt.RegisteredClaims
+	Scope string   `json:"scope"`
+	Scp   []string `json:"scp"`
+}
+
+// Verifier validates bearer tokens against a JWKS loaded from a file or url.
+type Verifier struct {
+	cfg    Config
+	client *http.Client
+	parser *gojwt.Parser
+	keys   atomic.Pointer[map[string]any]
+	done   chan struct{}
+}
+
+func NewVerifier(ctx context.Context, cfg Config) (*Verifier, error) {
+	if cfg.JWKSFile == "" && cfg.JWKSURL == "" {
+		return nil, ErrNoJWKSSource
+	}
+
+	opts := []gojwt.ParserOption{
+		gojwt.WithValidMethods(validMethods),
+		gojwt.WithExpirationRequired(),
+		gojwt.WithLeeway(cfg.Leeway),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, gojwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, gojwt.WithAudience(cfg.Audience))
+	}
+
+	v := &Verifier{
+		cfg:    cfg,
+		client: &http.Client{Timeout: fetchTimeout},
+		parser: gojwt.NewParser(opts...),
+		done:   make(chan struct{}),
+	}
+
+	if err := v.refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	if cfg.RefreshInterval > 0 {
+		go v.refreshLoop()
+	}
+
+	return v, nil
+}
+
+func (v *Verifier) Close() {
+	select {
+	case <-v.done:
+	default:
+		close(v.done)
+	}
+}
+
+// Verify validates the token and returns the principal of its subject.
+func (v *Verifier) Verify(_ context.Context, raw string) (*entity.Principal, error) {
+	c := new(claims)
+
+	if _, err := v.parser.ParseWithClaims(raw, c, v.keyFunc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if c.Subject == "" {
+		return nil, fmt.Errorf("%w: empty subject", ErrInvalidToken)
+	}
+
+	granted := append(strings.Fields(c.Scope), c.Scp...)
+
+	p := &entity.Principal{
+		Subject: c.Subject,
+		Service: v.cfg.ServiceScope != "" && slices.Contains(granted, v.cfg.ServiceScope),
+	}
+	for _, s := range granted {
+		if scopes, err := entity.ParseScopes([]string{s}); err == nil {
+			p.Scopes = append(p.Scopes, scopes...)
+		}
+	}
+	if len(p.Scopes) == 0 {
+		p.Scopes, _ = entity.ParseScopes(v.cfg.DefaultScopes)
+	}
+
+	return p, nil
+}
+
+func (v *Verifier) keyFunc(t *gojwt.Token) (any, error) {
+	keys := *v.keys.Load()
+
+	kid, _ := t.Header["kid"].(string)
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, nil
+		}
+	}
+
+	return nil, ErrUnknownKey
+}
+
+func (v *Verifier) refreshLoop() {
+	ticker := time.NewTicker(v.cfg.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-v.done:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+			if err := v.refresh(ctx); err != nil {
+				log.Println("error refreshing jwks, keeping previous keys: ", err)
+			}
+			cancel()
+		}
+	}
+}
+
+func (v *Verifier) refresh(ctx context.Context) error {
+	data, err := v.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return err
+	}
+
+	v.keys.Store(&keys)
+	return nil
+}
+
+func (v *Verifier) load(ctx context.Context) ([]byte, error) {
+	if v.cfg.JWKSFile != "" {
+		return os.ReadFile(v.cfg.JWKSFile)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.cfg.JWKSURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch jwks: unexpected status %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
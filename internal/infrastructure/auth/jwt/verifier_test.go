@@ -0,0 +1,120 @@
+package jwt
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"wallet/internal/entity"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestVerifier(t *testing.T, key *rsa.PrivateKey) *Verifier {
+	set := jwks{Keys: []jwk{{
+		Kty: "RSA",
+		Kid: "test",
+		Use: "sig",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}}
+	data, err := jsoniter.Marshal(set)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	v, err := NewVerifier(context.Background(), Config{
+		JWKSFile:      path,
+		Issuer:        "https://issuer",
+		Audience:      "wallet",
+		ServiceScope:  "wallet:service",
+		DefaultScopes: []string{"wallet:read"},
+	})
+	require.NoError(t, err)
+	t.Cleanup(v.Close)
+
+	return v
+}
+
+func sign(t *testing.T, key *rsa.PrivateKey, c gojwt.MapClaims) string {
+	token := gojwt.NewWithClaims(gojwt.SigningMethodRS256, c)
+	token.Header["kid"] = "test"
+
+	raw, err := token.SignedString(key)
+	require.NoError(t, err)
+	return raw
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	v := newTestVerifier(t, key)
+
+	valid := gojwt.MapClaims{
+		"sub": "user-1",
+		"iss": "https://issuer",
+		"aud": "wallet",
+		"exp": time.Now().Add(time.Minute).Unix(),
+	}
+
+	t.Run("end user token", func(t *testing.T) {
+		p, err := v.Verify(context.Background(), sign(t, key, valid))
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", p.Subject)
+		assert.False(t, p.Service)
+		assert.Equal(t, []entity.Scope{entity.ScopeWalletRead}, p.Scopes)
+	})
+
+	t.Run("service token", func(t *testing.T) {
+		c := gojwt.MapClaims{"scope": "wallet:service wallet:write"}
+		for k, val := range valid {
+			c[k] = val
+		}
+
+		p, err := v.Verify(context.Background(), sign(t, key, c))
+		require.NoError(t, err)
+		assert.True(t, p.Service)
+		assert.Equal(t, []entity.Scope{entity.ScopeWalletWrite}, p.Scopes)
+	})
+
+	t.Run("wrong audience", func(t *testing.T) {
+		c := gojwt.MapClaims{"aud": "other"}
+		for k, val := range valid {
+			if k != "aud" {
+				c[k] = val
+			}
+		}
+
+		_, err := v.Verify(context.Background(), sign(t, key, c))
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		c := gojwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()}
+		for k, val := range valid {
+			if k != "exp" {
+				c[k] = val
+			}
+		}
+
+		_, err := v.Verify(context.Background(), sign(t, key, c))
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("foreign key", func(t *testing.T) {
+		other, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+
+		_, err = v.Verify(context.Background(), sign(t, other, valid))
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+}
@@ -0,0 +1,130 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+type TopicSpec struct {
+	Name string
+	// Partitions is created for missing topics and is the minimum for existing ones.
+	Partitions        int
+	ReplicationFactor int
+	// Retention sets retention.ms of created topics, zero keeps the broker default.
+	Retention time.Duration
+}
+
+type Admin struct {
+	client *kafka.Client
+}
+
+func NewAdmin(brokers []string, security SecurityConfig) (*Admin, error) {
+	if len(brokers) == 0 {
+		return nil, ErrNoBrokers
+	}
+
+	transport, err := security.transport()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Admin{client: &kafka.Client{Addr: kafka.TCP(brokers...), Transport: transport}}, nil
+}
+
+// EnsureTopics checks that the topics exist and have enough partitions. With create missing topics
+// are created, otherwise they are reported. Settings of existing topics are never changed.
+func (a *Admin) EnsureTopics(ctx context.Context, specs []TopicSpec, create bool) error {
+	names := make([]string, 0, len(specs))
+	for _, spec := range specs {
+		names = append(names, spec.Name)
+	}
+
+	meta, err := a.client.Metadata(ctx, &kafka.MetadataRequest{Topics: names})
+	if err != nil {
+		return fmt.Errorf("failed to connect to Kafka: %w", err)
+	}
+
+	missing, err := checkTopics(specs, meta.Topics)
+	if err != nil {
+		return err
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	if !create {
+		errs := make([]error, 0, len(missing))
+		for _, spec := range missing {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrTopicNotFound, spec.Name))
+		}
+		return errors.Join(errs...)
+	}
+
+	return a.createTopics(ctx, missing)
+}
+
+// checkTopics returns the specs of topics that don't exist yet and the errors of topics that can't be used.
+func checkTopics(specs []TopicSpec, topics []kafka.Topic) ([]TopicSpec, error) {
+	byName := make(map[string]kafka.Topic, len(topics))
+	for _, t := range topics {
+		byName[t.Name] = t
+	}
+
+	var (
+		missing []TopicSpec
+		errs    []error
+	)
+	for _, spec := range specs {
+		t, ok := byName[spec.Name]
+		switch {
+		case !ok, errors.Is(t.Error, kafka.UnknownTopicOrPartition):
+			missing = append(missing, spec)
+		case t.Error != nil:
+			errs = append(errs, fmt.Errorf("topic %s: %w", spec.Name, t.Error))
+		case len(t.Partitions) < spec.Partitions:
+			errs = append(errs, fmt.Errorf("%w: topic %s has %d, at least %d required",
+				ErrTooFewPartitions, spec.Name, len(t.Partitions), spec.Partitions))
+		}
+	}
+
+	return missing, errors.Join(errs...)
+}
+
+func (a *Admin) createTopics(ctx context.Context, specs []TopicSpec) error {
+	topics := make([]kafka.TopicConfig, 0, len(specs))
+	for _, spec := range specs {
+		topic := kafka.TopicConfig{
+			Topic:             spec.Name,
+			NumPartitions:     spec.Partitions,
+			ReplicationFactor: spec.ReplicationFactor,
+		}
+		if spec.Retention > 0 {
+			topic.ConfigEntries = append(topic.ConfigEntries, kafka.ConfigEntry{
+				ConfigName:  "retention.ms",
+				ConfigValue: strconv.FormatInt(spec.Retention.Milliseconds(), 10),
+			})
+		}
+		topics = append(topics, topic)
+	}
+
+	res, err := a.client.CreateTopics(ctx, &kafka.CreateTopicsRequest{Topics: topics})
+	if err != nil {
+		return fmt.Errorf("failed to create topics: %w", err)
+	}
+
+	var errs []error
+	for name, err := range res.Errors {
+		// another replica created the topic concurrently
+		if err == nil || errors.Is(err, kafka.TopicAlreadyExists) {
+			continue
+		}
+		errs = append(errs, fmt.Errorf("create topic %s: %w", name, err))
+	}
+
+	return errors.Join(errs...)
+}
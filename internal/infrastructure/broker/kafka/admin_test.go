@@ -0,0 +1,30 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckTopics(t *testing.T) {
+	specs := []TopicSpec{
+		{Name: "transactions", Partitions: 12},
+		{Name: "dlq", Partitions: 1},
+		{Name: "events", Partitions: 6},
+		{Name: "broken", Partitions: 1},
+	}
+	topics := []kafka.Topic{
+		{Name: "transactions", Partitions: make([]kafka.Partition, 12)},
+		{Name: "dlq", Error: kafka.UnknownTopicOrPartition},
+		{Name: "events", Partitions: make([]kafka.Partition, 3)},
+		{Name: "broken", Error: kafka.TopicAuthorizationFailed},
+	}
+
+	missing, err := checkTopics(specs, topics)
+
+	assert.Equal(t, []TopicSpec{{Name: "dlq", Partitions: 1}}, missing)
+	assert.ErrorIs(t, err, ErrTooFewPartitions)
+	assert.ErrorIs(t, err, kafka.TopicAuthorizationFailed)
+	assert.ErrorContains(t, err, "topic events has 3, at least 6 required")
+}
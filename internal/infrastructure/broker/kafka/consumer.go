@@ -4,44 +4,240 @@ import (
 	"context"
 	"fmt"
 	"github.com/segmentio/kafka-go"
+	"log"
+	"sync/atomic"
+	"time"
+	"wallet/internal/infrastructure/broker"
+	"wallet/internal/utils/metrics"
+)
+
+type StartOffset string
+
+const (
+	StartOffsetFirst StartOffset = "first"
+	StartOffsetLast  StartOffset = "last"
 )
 
 type ConsumerConfig struct {
-	Addr    string
-	Topic   string
-	GroupID string
+	Brokers  []string
+	Topic    string
+	GroupID  string
+	Security SecurityConfig
+	// MinBytes and MaxBytes bound a fetch response, zero values keep the kafka-go defaults.
+	MinBytes int
+	MaxBytes int
+	// StartOffset is where a group without committed offsets starts reading.
+	StartOffset   StartOffset
+	MaxLag        int64
+	StatsInterval time.Duration
 }
 
 type Consumer struct {
-	r *kafka.Reader
+	r      *kafka.Reader
+	client *kafka.Client
+	cfg    ConsumerConfig
+	lag    atomic.Int64
+	done   chan struct{}
 }
 
 func NewConsumer(cfg ConsumerConfig) (*Consumer, error) {
+	if len(cfg.Brokers) == 0 {
+		return nil, ErrNoBrokers
+	}
 
-	conn, err := kafka.DialContext(context.Background(), "tcp", cfg.Addr)
+	startOffset, err := cfg.StartOffset.offset()
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to Kafka: %w", err)
+		return nil, err
+	}
+	dialer, err := cfg.Security.dialer()
+	if err != nil {
+		return nil, err
+	}
+	transport, err := cfg.Security.transport()
+	if err != nil {
+		return nil, err
+	}
+
+	client := &kafka.Client{Addr: kafka.TCP(cfg.Brokers...), Transport: transport}
+	if err := probe(client, cfg.Topic); err != nil {
+		return nil, err
 	}
-	defer conn.Close()
 
 	r := kafka.NewReader(
 		kafka.ReaderConfig{
-			Brokers: []string{cfg.Addr},
-			Topic:   cfg.Topic,
-			GroupID: cfg.GroupID,
+			Brokers:     cfg.Brokers,
+			Topic:       cfg.Topic,
+			GroupID:     cfg.GroupID,
+			Dialer:      dialer,
+			MinBytes:    cfg.MinBytes,
+			MaxBytes:    cfg.MaxBytes,
+			StartOffset: startOffset,
 		})
 
-	return &Consumer{r: r}, nil
+	c := &Consumer{
+		r:      r,
+		client: client,
+		cfg:    cfg,
+		done:   make(chan struct{}),
+	}
+
+	if cfg.StatsInterval > 0 {
+		go c.collectStats()
+	}
+
+	return c, nil
+}
+
+func (o StartOffset) offset() (int64, error) {
+	switch o {
+	case StartOffsetFirst, "":
+		return kafka.FirstOffset, nil
+	case StartOffsetLast:
+		return kafka.LastOffset, nil
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrUnknownStartOffset, o)
+	}
 }
 
-func (c *Consumer) Consume(ctx context.Context) ([]byte, error) {
+func (c *Consumer) Consume(ctx context.Context) (broker.Message, error) {
 	msg, err := c.r.ReadMessage(ctx)
 	if err != nil {
-		return nil, err
+		return broker.Message{}, err
+	}
+
+	m := broker.Message{
+		Key:   msg.Key,
+		Value: msg.Value,
+	}
+	if len(msg.Headers) > 0 {
+		m.Headers = make(map[string]string, len(msg.Headers))
+		for _, h := range msg.Headers {
+			m.Headers[h.Key] = string(h.Value)
+		}
 	}
-	return msg.Value, nil
+
+	return m, nil
+}
+
+// Lag returns the total consumer group lag observed on the last stats collection.
+func (c *Consumer) Lag() int64 {
+	return c.lag.Load()
+}
+
+// Ready reports whether the consumer keeps up with the topic.
+func (c *Consumer) Ready(_ context.Context) error {
+	if c.cfg.MaxLag <= 0 {
+		return nil
+	}
+	if lag := c.Lag(); lag > c.cfg.MaxLag {
+		return fmt.Errorf("%w: %d > %d", ErrConsumerLagTooHigh, lag, c.cfg.MaxLag)
+	}
+	return nil
 }
 
 func (c *Consumer) Close() error {
+	select {
+	case <-c.done:
+	default:
+		close(c.done)
+	}
 	return c.r.Close()
 }
+
+func (c *Consumer) collectStats() {
+	ticker := time.NewTicker(c.cfg.StatsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			stats := c.r.Stats()
+			metrics.AddConsumerStats(c.cfg.Topic, stats.Messages, stats.Errors, stats.Rebalances)
+
+			ctx, cancel := context.WithTimeout(context.Background(), c.cfg.StatsInterval)
+			if err := c.updateLag(ctx); err != nil {
+				log.Println("error collecting consumer lag: ", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// updateLag calculates the group lag per partition from committed and last offsets.
+// ReaderStats.Lag is not used since in group mode it only holds the value of the last fetched partition.
+func (c *Consumer) updateLag(ctx context.Context) error {
+	meta, err := c.client.Metadata(ctx, &kafka.MetadataRequest{Topics: []string{c.cfg.Topic}})
+	if err != nil {
+		return err
+	}
+	if len(meta.Topics) == 0 {
+		return nil
+	}
+	if meta.Topics[0].Error != nil {
+		return meta.Topics[0].Error
+	}
+
+	partitions := make([]int, 0, len(meta.Topics[0].Partitions))
+	lastOffsets := make([]kafka.OffsetRequest, 0, len(meta.Topics[0].Partitions))
+	for _, p := range meta.Topics[0].Partitions {
+		partitions = append(partitions, p.ID)
+		lastOffsets = append(lastOffsets, kafka.LastOffsetOf(p.ID))
+	}
+
+	committed, err := c.client.OffsetFetch(ctx, &kafka.OffsetFetchRequest{
+		GroupID: c.cfg.GroupID,
+		Topics:  map[string][]int{c.cfg.Topic: partitions},
+	})
+	if err != nil {
+		return err
+	}
+	if committed.Error != nil {
+		return committed.Error
+	}
+
+	offsets, err := c.client.ListOffsets(ctx, &kafka.ListOffsetsRequest{
+		Topics: map[string][]kafka.OffsetRequest{c.cfg.Topic: lastOffsets},
+	})
+	if err != nil {
+		return err
+	}
+
+	lags, err := partitionLags(committed.Topics[c.cfg.Topic], offsets.Topics[c.cfg.Topic])
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	for partition, lag := range lags {
+		metrics.SetConsumerLag(c.cfg.Topic, partition, lag)
+		total += lag
+	}
+
+	c.lag.Store(total)
+	return nil
+}
+
+// partitionLags returns the lag of every partition. Partitions without a committed offset are lagging
+// from their first offset, which is where a new group starts with StartOffsetFirst.
+func partitionLags(committed []kafka.OffsetFetchPartition, offsets []kafka.PartitionOffsets) (map[int]int64, error) {
+	committedByPartition := make(map[int]int64, len(committed))
+	for _, p := range committed {
+		committedByPartition[p.Partition] = p.CommittedOffset
+	}
+
+	lags := make(map[int]int64, len(offsets))
+	for _, p := range offsets {
+		if p.Error != nil {
+			return nil, p.Error
+		}
+		offset, ok := committedByPartition[p.Partition]
+		if !ok || offset < 0 {
+			offset = p.FirstOffset
+		}
+		lags[p.Partition] = max(p.LastOffset-offset, 0)
+	}
+
+	return lags, nil
+}
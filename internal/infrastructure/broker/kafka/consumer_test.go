@@ -0,0 +1,65 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPartitionLags(t *testing.T) {
+	tests := []struct {
+		name      string
+		committed []kafka.OffsetFetchPartition
+		offsets   []kafka.PartitionOffsets
+		lags      map[int]int64
+		err       error
+	}{
+		{
+			name:      "Committed",
+			committed: []kafka.OffsetFetchPartition{{Partition: 0, CommittedOffset: 10}, {Partition: 1, CommittedOffset: 50}},
+			offsets:   []kafka.PartitionOffsets{{Partition: 0, LastOffset: 15}, {Partition: 1, LastOffset: 50}},
+			lags:      map[int]int64{0: 5, 1: 0},
+		},
+		{
+			name:      "Nothing committed",
+			committed: []kafka.OffsetFetchPartition{{Partition: 0, CommittedOffset: -1}},
+			offsets:   []kafka.PartitionOffsets{{Partition: 0, FirstOffset: 100, LastOffset: 130}, {Partition: 1, FirstOffset: 7, LastOffset: 9}},
+			lags:      map[int]int64{0: 30, 1: 2},
+		},
+		{
+			name:      "Committed past the last offset",
+			committed: []kafka.OffsetFetchPartition{{Partition: 0, CommittedOffset: 20}},
+			offsets:   []kafka.PartitionOffsets{{Partition: 0, LastOffset: 15}},
+			lags:      map[int]int64{0: 0},
+		},
+		{
+			name:    "Partition error",
+			offsets: []kafka.PartitionOffsets{{Partition: 0, Error: kafka.NotLeaderForPartition}},
+			err:     kafka.NotLeaderForPartition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lags, err := partitionLags(tt.committed, tt.offsets)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.lags, lags)
+		})
+	}
+}
+
+func TestConsumer_Ready(t *testing.T) {
+	c := &Consumer{cfg: ConsumerConfig{MaxLag: 100}}
+
+	c.lag.Store(100)
+	assert.NoError(t, c.Ready(context.Background()))
+
+	c.lag.Store(101)
+	assert.ErrorIs(t, c.Ready(context.Background()), ErrConsumerLagTooHigh)
+
+	// the check is off without a threshold
+	c.cfg.MaxLag = 0
+	assert.NoError(t, c.Ready(context.Background()))
+}
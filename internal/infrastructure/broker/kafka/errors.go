@@ -0,0 +1,15 @@
+package kafka
+
+import "errors"
+
+var (
+	ErrConsumerLagTooHigh   = errors.New("consumer lag is too high")
+	ErrNoBrokers            = errors.New("kafka brokers are not set")
+	ErrUnknownSASLMechanism = errors.New("unknown SASL mechanism")
+	ErrInvalidCA            = errors.New("no certificates found in kafka CA file")
+	ErrUnknownAcks          = errors.New("unknown required acks")
+	ErrUnknownCompression   = errors.New("unknown compression codec")
+	ErrUnknownStartOffset   = errors.New("unknown start offset")
+	ErrTooFewPartitions     = errors.New("too few partitions")
+	ErrTopicNotFound        = errors.New("topic not found")
+)
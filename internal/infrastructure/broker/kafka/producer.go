@@ -2,54 +2,148 @@ package kafka
 
 import (
 	"context"
+	"fmt"
 	"github.com/segmentio/kafka-go"
+	"time"
+	"wallet/internal/infrastructure/broker"
+	"wallet/internal/utils/metrics"
+)
+
+type Acks string
+
+const (
+	AcksNone Acks = "none"
+	AcksOne  Acks = "one"
+	AcksAll  Acks = "all"
 )
 
 type ProducerConfig struct {
-	Addr  string
-	Topic string
+	Brokers  []string
+	Topic    string
+	Security SecurityConfig
+	Acks     Acks
+	// Compression is one of none, gzip, snappy, lz4 or zstd.
+	Compression  string
+	BatchSize    int
+	BatchTimeout time.Duration
+
+	StatsInterval time.Duration
 }
 
 type Producer struct {
-	pr *kafka.Writer
+	pr   *kafka.Writer
+	cfg  ProducerConfig
+	done chan struct{}
 }
 
 func NewProducer(cfg ProducerConfig) (*Producer, error) {
+	if len(cfg.Brokers) == 0 {
+		return nil, ErrNoBrokers
+	}
 
-	// hint for fix: panic: [3] Unknown Topic Or Partition: the request is for a topic or partition that does not exist on this broker
-	conn, err := kafka.DialLeader(
-		context.Background(),
-		"tcp",
-		cfg.Addr,
-		cfg.Topic,
-		0,
-	)
+	acks, err := cfg.Acks.requiredAcks()
+	if err != nil {
+		return nil, err
+	}
+	compression, err := parseCompression(cfg.Compression)
+	if err != nil {
+		return nil, err
+	}
+	transport, err := cfg.Security.transport()
 	if err != nil {
 		return nil, err
 	}
-	// close the connection because we won't be using it
-	_ = conn.Close()
+
+	// fails with Unknown Topic Or Partition when the topic does not exist, see TOPICS_MODE
+	if err := probe(&kafka.Client{Addr: kafka.TCP(cfg.Brokers...), Transport: transport}, cfg.Topic); err != nil {
+		return nil, err
+	}
 
 	p := &Producer{
 		pr: &kafka.Writer{
-			Addr:         kafka.TCP(cfg.Addr),
+			Addr:         kafka.TCP(cfg.Brokers...),
 			Topic:        cfg.Topic,
-			BatchBytes:   0,
-			BatchSize:    1, //
-			BatchTimeout: 0,
+			Balancer:     &kafka.Hash{},
+			Transport:    transport,
+			RequiredAcks: acks,
+			Compression:  compression,
+			BatchSize:    cfg.BatchSize,
+			BatchTimeout: cfg.BatchTimeout,
 		},
+		cfg:  cfg,
+		done: make(chan struct{}),
 	}
+
+	if cfg.StatsInterval > 0 {
+		go p.collectStats()
+	}
+
 	return p, nil
 }
 
-func (p *Producer) Publish(ctx context.Context, data []byte) error {
-	msg := kafka.Message{
-		Value: data,
+func (a Acks) requiredAcks() (kafka.RequiredAcks, error) {
+	switch a {
+	case AcksNone:
+		return kafka.RequireNone, nil
+	case AcksOne:
+		return kafka.RequireOne, nil
+	case AcksAll, "":
+		return kafka.RequireAll, nil
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrUnknownAcks, a)
+	}
+}
+
+func parseCompression(codec string) (kafka.Compression, error) {
+	switch codec {
+	case "none", "":
+		return 0, nil
+	case "gzip":
+		return kafka.Gzip, nil
+	case "snappy":
+		return kafka.Snappy, nil
+	case "lz4":
+		return kafka.Lz4, nil
+	case "zstd":
+		return kafka.Zstd, nil
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrUnknownCompression, codec)
+	}
+}
+
+func (p *Producer) Publish(ctx context.Context, msg broker.Message) error {
+	km := kafka.Message{
+		Key:   msg.Key,
+		Value: msg.Value,
+	}
+	for k, v := range msg.Headers {
+		km.Headers = append(km.Headers, kafka.Header{Key: k, Value: []byte(v)})
 	}
 
-	return p.pr.WriteMessages(ctx, msg)
+	return p.pr.WriteMessages(ctx, km)
 }
 
 func (p *Producer) Close() error {
+	select {
+	case <-p.done:
+	default:
+		close(p.done)
+	}
 	return p.pr.Close()
 }
+
+func (p *Producer) collectStats() {
+	ticker := time.NewTicker(p.cfg.StatsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+			stats := p.pr.Stats()
+			metrics.AddProducerStats(p.cfg.Topic, stats.Messages, stats.Errors)
+			metrics.SetProducerBatchSize(p.cfg.Topic, stats.BatchSize.Avg, stats.BatchSize.Max)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package kafka
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+type SASLMechanism string
+
+const (
+	SASLNone        SASLMechanism = ""
+	SASLPlain       SASLMechanism = "plain"
+	SASLScramSHA256 SASLMechanism = "scram-sha-256"
+	SASLScramSHA512 SASLMechanism = "scram-sha-512"
+)
+
+// SecurityConfig configures authentication and encryption of broker connections.
+type SecurityConfig struct {
+	SASLMechanism SASLMechanism
+	Username      string
+	Password      string
+
+	TLSEnabled bool
+	// TLSCAFile is a PEM bundle verifying the brokers instead of the system roots.
+	TLSCAFile             string
+	TLSServerName         string
+	TLSInsecureSkipVerify bool
+}
+
+const dialTimeout = 10 * time.Second
+
+func (s SecurityConfig) mechanism() (sasl.Mechanism, error) {
+	switch s.SASLMechanism {
+	case SASLNone:
+		return nil, nil
+	case SASLPlain:
+		return plain.Mechanism{Username: s.Username, Password: s.Password}, nil
+	case SASLScramSHA256:
+		return scram.Mechanism(scram.SHA256, s.Username, s.Password)
+	case SASLScramSHA512:
+		return scram.Mechanism(scram.SHA512, s.Username, s.Password)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownSASLMechanism, s.SASLMechanism)
+	}
+}
+
+func (s SecurityConfig) tlsConfig() (*tls.Config, error) {
+	if !s.TLSEnabled {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         s.TLSServerName,
+		InsecureSkipVerify: s.TLSInsecureSkipVerify,
+	}
+
+	if s.TLSCAFile != "" {
+		pem, err := os.ReadFile(s.TLSCAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, ErrInvalidCA
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return tlsConfig, nil
+}
+
+// dialer is used by the reader, which doesn't accept a transport.
+func (s SecurityConfig) dialer() (*kafka.Dialer, error) {
+	mechanism, err := s.mechanism()
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig, err := s.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return &kafka.Dialer{
+		Timeout:       dialTimeout,
+		DualStack:     true,
+		SASLMechanism: mechanism,
+		TLS:           tlsConfig,
+	}, nil
+}
+
+// transport is used by the writer and the admin client.
+func (s SecurityConfig) transport() (*kafka.Transport, error) {
+	mechanism, err := s.mechanism()
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig, err := s.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return &kafka.Transport{
+		DialTimeout: dialTimeout,
+		SASL:        mechanism,
+		TLS:         tlsConfig,
+	}, nil
+}
+
+// probe checks that the brokers are reachable with the configured credentials and the topic exists.
+func probe(client *kafka.Client, topic string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+	defer cancel()
+
+	meta, err := client.Metadata(ctx, &kafka.MetadataRequest{Topics: []string{topic}})
+	if err != nil {
+		return fmt.Errorf("failed to connect to Kafka: %w", err)
+	}
+	for _, t := range meta.Topics {
+		if t.Error != nil {
+			return fmt.Errorf("topic %s: %w", t.Name, t.Error)
+		}
+	}
+
+	return nil
+}
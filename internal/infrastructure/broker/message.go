@@ -0,0 +1,14 @@
+package broker
+
+import "context"
+
+// Message is a broker record. Messages with the same Key keep their order
+// because they are routed to the same partition.
+type Message struct {
+	Key     []byte
+	Value   []byte
+	Headers map[string]string
+	// Ack removes a consumed message from brokers with explicit acknowledgements, it must be called
+	// only once the message is processed. It is nil when the broker commits messages on its own.
+	Ack func(context.Context) error
+}
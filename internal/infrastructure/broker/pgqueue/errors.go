@@ -0,0 +1,10 @@
+package pgqueue
+
+import "errors"
+
+var (
+	ErrNoTopic                = errors.New("queue topic is not set")
+	ErrListenerNotReady       = errors.New("queue listener is not connected")
+	ErrInvalidPollInterval    = errors.New("queue poll interval must be positive")
+	ErrInvalidDeadLetterTopic = errors.New("queue dead letter topic must be set and differ from the topic")
+)
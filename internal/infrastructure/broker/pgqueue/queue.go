@@ -0,0 +1,336 @@
+package pgqueue
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"wallet/internal/infrastructure/broker"
+	"wallet/internal/utils/metrics"
+)
+
+const (
+	queueMessagesName = "queue_messages"
+	// notifyChannel is shared by all topics, the payload is the topic name.
+	notifyChannel = "queue_messages"
+)
+
+type Config struct {
+	Topic string
+	// VisibilityTimeout hides a claimed message from other consumers. A message that is not
+	// acknowledged in time, e.g. because the consumer crashed, is delivered again.
+	VisibilityTimeout time.Duration
+	// PollInterval is how often consumers look for messages when no notification arrives,
+	// e.g. for messages becoming visible again or notifications lost while reconnecting.
+	PollInterval time.Duration
+	// MaxAttempts moves a message that was delivered that many times without an acknowledgement
+	// to DeadLetterTopic. Nothing consumes that topic, the messages are kept for inspection.
+	MaxAttempts     int
+	DeadLetterTopic string
+}
+
+// Queue is a broker backed by a Postgres table. Consumers claim messages with FOR UPDATE SKIP LOCKED,
+// so replicas never receive the same message at once, and are woken up by LISTEN/NOTIFY.
+// Unlike Kafka, messages with the same key are not ordered.
+type Queue struct {
+	pool *pgxpool.Pool
+	cfg  Config
+
+	mu sync.Mutex
+	// wake is closed and replaced when a message is published to the topic
+	wake      chan struct{}
+	listening atomic.Bool
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func New(pool *pgxpool.Pool, cfg Config) (*Queue, error) {
+	if cfg.Topic == "" {
+		return nil, ErrNoTopic
+	}
+	if cfg.PollInterval <= 0 {
+		return nil, ErrInvalidPollInterval
+	}
+	if cfg.DeadLetterTopic == "" || cfg.DeadLetterTopic == cfg.Topic {
+		return nil, ErrInvalidDeadLetterTopic
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &Queue{
+		pool:   pool,
+		cfg:    cfg,
+		wake:   make(chan struct{}),
+		cancel: cancel,
+	}
+
+	q.wg.Add(2)
+	go q.listen(ctx)
+	go q.moveDeadLetters(ctx)
+
+	return q, nil
+}
+
+const (
+	publishMessageFn = "queue publish message"
+	notifyMessageFn  = "queue notify message"
+	claimMessageFn   = "queue claim message"
+	ackMessageFn     = "queue ack message"
+	deadLetterFn     = "queue move dead letters"
+)
+
+func (q *Queue) Publish(ctx context.Context, msg broker.Message) error {
+	headers := msg.Headers
+	if headers == nil {
+		headers = map[string]string{}
+	}
+
+	now := time.Now()
+	stmt, args, err := sq.Insert(queueMessagesName).
+		Columns(
+			"topic",
+			"key",
+			"value",
+			"headers",
+			"visible_at",
+			"created_at",
+		).
+		Values(
+			q.cfg.Topic,
+			msg.Key,
+			msg.Value,
+			headers,
+			now,
+			now,
+		).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err := metrics.Tx().Exec(publishMessageFn, ctx, q.pool, stmt, args...); err != nil {
+		return err
+	}
+
+	// the message is already stored, consumers find it on the next poll if the notification fails
+	if _, err := metrics.Tx().Exec(notifyMessageFn, ctx, q.pool, "SELECT pg_notify($1, $2)", notifyChannel, q.cfg.Topic); err != nil {
+		log.Println("error notifying queue consumers: ", err)
+	}
+
+	return nil
+}
+
+// Consume blocks until a message is claimed or ctx is done. The message must be acknowledged
+// with Ack once it is processed. A message that is not acknowledged within the visibility timeout,
+// e.g. because the consumer crashed, is delivered again.
+func (q *Queue) Consume(ctx context.Context) (broker.Message, error) {
+	ticker := time.NewTicker(q.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// taken before claiming, so a message published in between wakes the consumer up
+		wake := q.wakeup()
+
+		msg, ok, err := q.claim(ctx)
+		if err != nil {
+			return broker.Message{}, err
+		}
+		if ok {
+			return msg, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return broker.Message{}, ctx.Err()
+		case <-wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (q *Queue) claim(ctx context.Context) (broker.Message, bool, error) {
+	now := time.Now()
+
+	due, dueArgs, err := sq.Select("id").
+		From(queueMessagesName).
+		Where(sq.Eq{"topic": q.cfg.Topic}).
+		Where(sq.LtOrEq{"visible_at": now}).
+		Where(sq.Lt{"attempts": q.cfg.MaxAttempts}).
+		OrderBy("id").
+		Limit(1).
+		Suffix("FOR UPDATE SKIP LOCKED").
+		ToSql()
+	if err != nil {
+		return broker.Message{}, false, err
+	}
+
+	stmt, args, err := sq.Update(queueMessagesName).
+		Set("visible_at", now.Add(q.cfg.VisibilityTimeout)).
+		Set("attempts", sq.Expr("attempts + 1")).
+		Where("id = ("+due+")", dueArgs...).
+		Suffix("RETURNING id, key, value, headers").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return broker.Message{}, false, err
+	}
+
+	var (
+		id  int64
+		msg broker.Message
+	)
+	if err := metrics.Tx().QueryRow(claimMessageFn, ctx, q.pool, stmt, args...).
+		Scan(&id, &msg.Key, &msg.Value, &msg.Headers); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return broker.Message{}, false, nil
+		}
+		return broker.Message{}, false, err
+	}
+
+	msg.Ack = func(ctx context.Context) error {
+		return q.ack(ctx, id)
+	}
+
+	return msg, true, nil
+}
+
+func (q *Queue) ack(ctx context.Context, id int64) error {
+	stmt, args, err := sq.Delete(queueMessagesName).
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = metrics.Tx().Exec(ackMessageFn, ctx, q.pool, stmt, args...)
+	return err
+}
+
+// moveDeadLetters periodically moves messages that ran out of attempts to the dead letter topic.
+func (q *Queue) moveDeadLetters(ctx context.Context) {
+	defer q.wg.Done()
+
+	ticker := time.NewTicker(q.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := q.moveExhausted(ctx); err != nil && ctx.Err() == nil {
+				log.Println("error moving queue dead letters: ", err)
+			}
+		}
+	}
+}
+
+// moveExhausted moves only messages whose last delivery timed out, a consumer may still acknowledge the others.
+func (q *Queue) moveExhausted(ctx context.Context) error {
+	stmt, args, err := sq.Update(queueMessagesName).
+		Set("topic", q.cfg.DeadLetterTopic).
+		Where(sq.Eq{"topic": q.cfg.Topic}).
+		Where(sq.GtOrEq{"attempts": q.cfg.MaxAttempts}).
+		Where(sq.LtOrEq{"visible_at": time.Now()}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := metrics.Tx().Exec(deadLetterFn, ctx, q.pool, stmt, args...)
+	if err != nil {
+		return err
+	}
+
+	if moved := tag.RowsAffected(); moved > 0 {
+		log.Printf("moved %d queue messages of %s to %s after %d attempts\n", moved, q.cfg.Topic, q.cfg.DeadLetterTopic, q.cfg.MaxAttempts)
+		metrics.AddQueueDeadLettered(q.cfg.Topic, moved)
+	}
+	return nil
+}
+
+func (q *Queue) wakeup() <-chan struct{} {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.wake
+}
+
+func (q *Queue) broadcast() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	close(q.wake)
+	q.wake = make(chan struct{})
+}
+
+const listenRetryDelay = time.Second
+
+// listen holds a dedicated connection subscribed to notifications and reconnects when it is lost.
+func (q *Queue) listen(ctx context.Context) {
+	defer q.wg.Done()
+
+	for {
+		if err := q.waitForNotifications(ctx); err != nil && ctx.Err() == nil {
+			log.Println("queue listener disconnected: ", err)
+		}
+		q.listening.Store(false)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(listenRetryDelay):
+		}
+	}
+}
+
+func (q *Queue) waitForNotifications(ctx context.Context) error {
+	pooled, err := q.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// a connection in LISTEN state must not go back to the pool
+	conn := pooled.Hijack()
+	defer func() {
+		_ = conn.Close(context.Background())
+	}()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{notifyChannel}.Sanitize()); err != nil {
+		return err
+	}
+	q.listening.Store(true)
+
+	// consumers may have missed messages published while reconnecting
+	q.broadcast()
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		if n.Payload == q.cfg.Topic {
+			q.broadcast()
+		}
+	}
+}
+
+// Ready reports whether consumers are woken up by notifications.
+func (q *Queue) Ready(_ context.Context) error {
+	if !q.listening.Load() {
+		return ErrListenerNotReady
+	}
+	return nil
+}
+
+func (q *Queue) Close() error {
+	q.cancel()
+	q.wg.Wait()
+	return nil
+}
@@ -0,0 +1,206 @@
+package pgqueue_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+	"wallet/config"
+	"wallet/internal/infrastructure/broker"
+	"wallet/internal/infrastructure/broker/pgqueue"
+	"wallet/internal/infrastructure/database/postgres"
+
+	"github.com/google/uuid"
+	"github.com/ilyakaznacheev/cleanenv"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The tests need a migrated database configured with the DB_* variables:
+//
+//	TEST_POSTGRES=1 DB_PORT=5432 go test ./internal/infrastructure/broker/pgqueue/
+func testPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	if os.Getenv("TEST_POSTGRES") == "" {
+		t.Skip("TEST_POSTGRES is not set")
+	}
+
+	var cfg config.DBConfig
+	require.NoError(t, cleanenv.ReadEnv(&cfg))
+
+	store, err := postgres.NewStore(context.Background(), cfg.Convert())
+	require.NoError(t, err)
+	t.Cleanup(store.Close)
+
+	return store.Pool()
+}
+
+// testConfig returns a config with topics of its own, so tests don't see each other's messages.
+// Polling is effectively off, consumers are only woken up by notifications.
+func testConfig() pgqueue.Config {
+	topic := "test-" + uuid.NewString()
+	return pgqueue.Config{
+		Topic:             topic,
+		VisibilityTimeout: time.Minute,
+		PollInterval:      time.Hour,
+		MaxAttempts:       3,
+		DeadLetterTopic:   topic + "-dlq",
+	}
+}
+
+func newTestQueue(t *testing.T, pool *pgxpool.Pool, cfg pgqueue.Config) *pgqueue.Queue {
+	t.Helper()
+
+	q, err := pgqueue.New(pool, cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = q.Close()
+		_, _ = pool.Exec(context.Background(), "DELETE FROM queue_messages WHERE topic = ANY($1)",
+			[]string{cfg.Topic, cfg.DeadLetterTopic})
+	})
+
+	return q
+}
+
+func publish(t *testing.T, q *pgqueue.Queue, values ...string) {
+	t.Helper()
+
+	for _, v := range values {
+		require.NoError(t, q.Publish(context.Background(), broker.Message{
+			Key:     []byte("key"),
+			Value:   []byte(v),
+			Headers: map[string]string{"version": "1"},
+		}))
+	}
+}
+
+func consume(q *pgqueue.Queue, timeout time.Duration) (broker.Message, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return q.Consume(ctx)
+}
+
+func countMessages(t *testing.T, pool *pgxpool.Pool, topic string) int {
+	t.Helper()
+
+	var n int
+	require.NoError(t, pool.QueryRow(context.Background(), "SELECT count(*) FROM queue_messages WHERE topic = $1", topic).Scan(&n))
+	return n
+}
+
+func TestQueue_ClaimSkipsLocked(t *testing.T) {
+	ctx := context.Background()
+	pool := testPool(t)
+	cfg := testConfig()
+	q := newTestQueue(t, pool, cfg)
+
+	publish(t, q, "first", "second")
+
+	// another replica holds the first message
+	tx, err := pool.Begin(ctx)
+	require.NoError(t, err)
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+	var id int64
+	require.NoError(t, tx.QueryRow(ctx,
+		"SELECT id FROM queue_messages WHERE topic = $1 ORDER BY id LIMIT 1 FOR UPDATE", cfg.Topic).Scan(&id))
+
+	msg, err := consume(q, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(msg.Value))
+	assert.Equal(t, []byte("key"), msg.Key)
+	assert.Equal(t, map[string]string{"version": "1"}, msg.Headers)
+
+	// the locked message is skipped, not waited for
+	_, err = consume(q, 200*time.Millisecond)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	require.NoError(t, tx.Rollback(ctx))
+	msg, err = consume(q, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "first", string(msg.Value))
+}
+
+func TestQueue_NotifyWakesConsumer(t *testing.T) {
+	pool := testPool(t)
+	q := newTestQueue(t, pool, testConfig())
+
+	require.Eventually(t, func() bool {
+		return q.Ready(context.Background()) == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	type result struct {
+		msg broker.Message
+		err error
+	}
+	consumed := make(chan result, 1)
+	go func() {
+		msg, err := consume(q, 5*time.Second)
+		consumed <- result{msg, err}
+	}()
+
+	// let the consumer find the queue empty and wait, the next poll is an hour away
+	time.Sleep(200 * time.Millisecond)
+	start := time.Now()
+	publish(t, q, "wake up")
+
+	res := <-consumed
+	require.NoError(t, res.err)
+	assert.Equal(t, "wake up", string(res.msg.Value))
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestQueue_Ack(t *testing.T) {
+	pool := testPool(t)
+	cfg := testConfig()
+	cfg.VisibilityTimeout = 200 * time.Millisecond
+	cfg.PollInterval = 50 * time.Millisecond
+	q := newTestQueue(t, pool, cfg)
+
+	publish(t, q, "payment")
+
+	// the consumer fails before the outcome is committed and does not acknowledge the message
+	msg, err := consume(q, time.Second)
+	require.NoError(t, err)
+	_, err = consume(q, 100*time.Millisecond)
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "claimed message must be hidden")
+
+	// it is delivered again after the visibility timeout
+	msg, err = consume(q, 2*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "payment", string(msg.Value))
+
+	// acknowledged once the outcome is committed, it is gone for good
+	require.NoError(t, msg.Ack(context.Background()))
+	assert.Zero(t, countMessages(t, pool, cfg.Topic))
+	_, err = consume(q, 500*time.Millisecond)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestQueue_DeadLetter(t *testing.T) {
+	pool := testPool(t)
+	cfg := testConfig()
+	cfg.VisibilityTimeout = 100 * time.Millisecond
+	cfg.PollInterval = 50 * time.Millisecond
+	cfg.MaxAttempts = 2
+	q := newTestQueue(t, pool, cfg)
+
+	publish(t, q, "poison")
+
+	for range cfg.MaxAttempts {
+		msg, err := consume(q, 2*time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, "poison", string(msg.Value))
+	}
+
+	// out of attempts, the message is not delivered again and moves to the dead letter topic
+	_, err := consume(q, 500*time.Millisecond)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Eventually(t, func() bool {
+		return countMessages(t, pool, cfg.DeadLetterTopic) == 1
+	}, 2*time.Second, 20*time.Millisecond)
+	assert.Zero(t, countMessages(t, pool, cfg.Topic))
+}
@@ -0,0 +1,11 @@
+package redis
+
+import "errors"
+
+var (
+	ErrNotFound     = errors.New("key not found")
+	ErrUnknownMode  = errors.New("unknown redis mode")
+	ErrNoAddrs      = errors.New("redis addresses are not set")
+	ErrNoMasterName = errors.New("redis sentinel master name is not set")
+	ErrInvalidCA    = errors.New("no certificates found in redis CA file")
+)
@@ -6,6 +6,9 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"log"
+	"strconv"
+	"time"
+	"wallet/internal/utils/metrics"
 )
 
 type DBConfig struct {
@@ -14,36 +17,130 @@ type DBConfig struct {
 	User     string
 	Pass     string
 	Database string
+	SSLMode  string
+	Pool     PoolConfig
+	Retry    RetryConfig
+}
+
+// PoolConfig tunes the connection pool, zero values keep the pgxpool defaults.
+type PoolConfig struct {
+	MinConns        int32
+	MaxConns        int32
+	MaxConnLifetime time.Duration
+	MaxConnIdleTime time.Duration
+	ConnectTimeout  time.Duration
+	// StatementTimeout aborts queries running longer, zero disables it.
+	StatementTimeout time.Duration
 }
 
 type Store struct {
-	pool *pgxpool.Pool
+	pool   *pgxpool.Pool
+	retry  RetryConfig
+	budget *retryBudget
 }
 
 func NewStore(ctx context.Context, cfg DBConfig) (*Store, error) {
-	psqlURL := fmt.Sprintf("host=%s user=%s password=%s dbname=%s sslmode=disable",
-		cfg.Host, cfg.User, cfg.Pass, cfg.Database)
+	psqlURL := fmt.Sprintf("host=%s user=%s password=%s dbname=%s sslmode=%s",
+		cfg.Host, cfg.User, cfg.Pass, cfg.Database, cfg.SSLMode)
 
 	if cfg.Port != "" {
 		psqlURL += fmt.Sprintf(" port=%s", cfg.Port)
 	}
 
-	pool, err := pgxpool.New(ctx, psqlURL)
+	poolCfg, err := pgxpool.ParseConfig(psqlURL)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", ErrConnectToDB, err)
+	}
+	cfg.Pool.apply(poolCfg)
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
 	if err != nil {
 		return nil, fmt.Errorf("%v: %w", ErrConnectToDB, err)
 	}
 	if pool.Ping(ctx) != nil {
 		return nil, ErrPingToDB
 	}
-	return &Store{pool: pool}, nil
+	return &Store{
+		pool:   pool,
+		retry:  cfg.Retry,
+		budget: newRetryBudget(cfg.Retry.BudgetRatio),
+	}, nil
+}
+
+func (c PoolConfig) apply(cfg *pgxpool.Config) {
+	if c.MinConns > 0 {
+		cfg.MinConns = c.MinConns
+	}
+	if c.MaxConns > 0 {
+		cfg.MaxConns = c.MaxConns
+	}
+	if c.MaxConnLifetime > 0 {
+		cfg.MaxConnLifetime = c.MaxConnLifetime
+	}
+	if c.MaxConnIdleTime > 0 {
+		cfg.MaxConnIdleTime = c.MaxConnIdleTime
+	}
+	if c.ConnectTimeout > 0 {
+		cfg.ConnConfig.ConnectTimeout = c.ConnectTimeout
+	}
+	if c.StatementTimeout > 0 {
+		cfg.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(c.StatementTimeout.Milliseconds(), 10)
+	}
+}
+
+// Pool is used by components that need their own connections, e.g. to LISTEN for notifications.
+func (s *Store) Pool() *pgxpool.Pool {
+	return s.pool
 }
 
 func (s *Store) Close() {
 	s.pool.Close()
 }
 
+// WithTransact runs fn in a read committed transaction.
 func (s *Store) WithTransact(ctx context.Context, fn func(pgx.Tx) error) error {
-	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	return s.WithTxOptions(ctx, pgx.TxOptions{}, fn)
+}
+
+// WithSerializableTransact runs fn in a serializable transaction.
+func (s *Store) WithSerializableTransact(ctx context.Context, fn func(pgx.Tx) error) error {
+	return s.WithTxOptions(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable}, fn)
+}
+
+// WithTxOptions runs fn in a transaction with the given isolation level, access and deferrable modes.
+// Serialization failures and deadlocks are retried from the beginning, so fn must be safe to run again.
+func (s *Store) WithTxOptions(ctx context.Context, opts pgx.TxOptions, fn func(pgx.Tx) error) error {
+	s.budget.deposit()
+
+	for attempt := 1; ; attempt++ {
+		err := s.transact(ctx, opts, fn)
+
+		reason, retryable := retryReason(err)
+		if !retryable {
+			return err
+		}
+		if attempt >= s.retry.MaxAttempts {
+			metrics.IncTxRetriesExhausted(giveUpAttempts)
+			return err
+		}
+		if !s.budget.withdraw() {
+			metrics.IncTxRetriesExhausted(giveUpBudget)
+			return err
+		}
+		metrics.IncTxRetries(reason)
+
+		timer := time.NewTimer(s.retry.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+func (s *Store) transact(ctx context.Context, opts pgx.TxOptions, fn func(pgx.Tx) error) error {
+	tx, err := s.pool.BeginTx(ctx, opts)
 
 	if err != nil {
 		log.Println("err creating transaction", err)
@@ -66,30 +163,3 @@ func (s *Store) WithTransact(ctx context.Context, fn func(pgx.Tx) error) error {
 
 	return nil
 }
-
-//func (s *Store) WithSerializableTransact(ctx context.Context, fn func(pgx.Tx) error) error {
-//	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{
-//		IsoLevel: pgx.Serializable,
-//	})
-//
-//	if err != nil {
-//		fmt.Println("err creating transaction", err)
-//		return err
-//	}
-//	defer func() {
-//		_ = tx.Rollback(ctx)
-//	}()
-//
-//	err = fn(tx)
-//	if err != nil {
-//		fmt.Println("err executing transaction: ", err)
-//		return err
-//	}
-//
-//	if err := tx.Commit(ctx); err != nil {
-//		fmt.Println("err committing transaction", err)
-//		return err
-//	}
-//
-//	return nil
-//}
@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"errors"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+type RetryConfig struct {
+	// MaxAttempts limits the attempts of one transaction, 1 disables retries.
+	MaxAttempts int
+	// BaseDelay and MaxDelay bound the jittered exponential backoff between attempts.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// BudgetRatio is the share of transactions that may be retried, so retries do not
+	// multiply the load when the database is already contended.
+	BudgetRatio float64
+}
+
+const (
+	retrySerializationFailure = "serialization_failure"
+	retryDeadlock             = "deadlock"
+
+	giveUpAttempts = "attempts"
+	giveUpBudget   = "budget"
+)
+
+func retryReason(err error) (string, bool) {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return "", false
+	}
+
+	switch pgErr.Code {
+	case pgerrcode.SerializationFailure:
+		return retrySerializationFailure, true
+	case pgerrcode.DeadlockDetected:
+		return retryDeadlock, true
+	}
+	return "", false
+}
+
+// backoff returns a full jitter delay before the next attempt.
+func (c RetryConfig) backoff(attempt int) time.Duration {
+	if c.BaseDelay <= 0 {
+		return 0
+	}
+
+	delay := c.BaseDelay << (attempt - 1)
+	if delay <= 0 || (c.MaxDelay > 0 && delay > c.MaxDelay) {
+		delay = c.MaxDelay
+	}
+
+	return rand.N(delay + 1)
+}
+
+// retryBurst is the number of retries allowed before any transaction has deposited to the budget.
+const retryBurst = 10
+
+// retryBudget is a token bucket filled by every transaction and drained by every retry.
+type retryBudget struct {
+	mu     sync.Mutex
+	ratio  float64
+	tokens float64
+}
+
+func newRetryBudget(ratio float64) *retryBudget {
+	return &retryBudget{
+		ratio:  ratio,
+		tokens: retryBurst,
+	}
+}
+
+func (b *retryBudget) deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(b.tokens+b.ratio, retryBurst)
+}
+
+func (b *retryBudget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
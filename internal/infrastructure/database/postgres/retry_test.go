@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryReason(t *testing.T) {
+	reason, ok := retryReason(fmt.Errorf("commit: %w", &pgconn.PgError{Code: pgerrcode.SerializationFailure}))
+	assert.True(t, ok)
+	assert.Equal(t, retrySerializationFailure, reason)
+
+	reason, ok = retryReason(&pgconn.PgError{Code: pgerrcode.DeadlockDetected})
+	assert.True(t, ok)
+	assert.Equal(t, retryDeadlock, reason)
+
+	_, ok = retryReason(&pgconn.PgError{Code: pgerrcode.UniqueViolation})
+	assert.False(t, ok)
+
+	_, ok = retryReason(errors.New("connection reset"))
+	assert.False(t, ok)
+}
+
+func TestRetryConfig_Backoff(t *testing.T) {
+	cfg := RetryConfig{BaseDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond}
+
+	for attempt := 1; attempt < 70; attempt++ {
+		delay := cfg.backoff(attempt)
+		assert.GreaterOrEqual(t, delay, time.Duration(0))
+		assert.LessOrEqual(t, delay, cfg.MaxDelay)
+	}
+}
+
+func TestRetryBudget(t *testing.T) {
+	b := newRetryBudget(0.5)
+
+	for range retryBurst {
+		assert.True(t, b.withdraw())
+	}
+	assert.False(t, b.withdraw())
+
+	// two transactions earn one retry
+	b.deposit()
+	assert.False(t, b.withdraw())
+	b.deposit()
+	assert.True(t, b.withdraw())
+}
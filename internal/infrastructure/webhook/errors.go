@@ -0,0 +1,9 @@
+package webhook
+
+import "errors"
+
+var (
+	ErrUnexpectedStatus  = errors.New("unexpected webhook response status")
+	ErrAddressNotAllowed = errors.New("webhook address is not public")
+	ErrInvalidNetwork    = errors.New("invalid allowed network")
+)
@@ -0,0 +1,89 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+	"wallet/internal/entity"
+)
+
+const (
+	idHeader        = "X-Webhook-Id"
+	timestampHeader = "X-Webhook-Timestamp"
+	signatureHeader = "X-Webhook-Signature"
+	userAgent       = "wallet-webhooks/1.0"
+
+	maxResponseBody = 64 << 10
+)
+
+type Config struct {
+	Timeout time.Duration
+	// AllowedNetworks are CIDRs webhooks may be sent to besides public addresses,
+	// e.g. loopback for local development. Everything else that is not public is rejected.
+	AllowedNetworks []string
+}
+
+// Sender posts signed webhook payloads.
+type Sender struct {
+	client *http.Client
+}
+
+func NewSender(cfg Config) (*Sender, error) {
+	allowed, err := parseNetworks(cfg.AllowedNetworks)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// a proxy would be the only address checked by the dialer
+	transport.Proxy = nil
+	transport.DialContext = newDialer(allowed).DialContext
+
+	return &Sender{
+		client: &http.Client{
+			Timeout:   cfg.Timeout,
+			Transport: transport,
+			// redirects are treated as failures, endpoints must be configured with their final url
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}, nil
+}
+
+// Send posts the payload and returns the response status code.
+// Responses other than 2xx are reported as ErrUnexpectedStatus.
+func (s *Sender) Send(ctx context.Context, url, secret string, eventID uuid.UUID, payload []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(idHeader, eventID.String())
+	req.Header.Set(timestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(signatureHeader, entity.SignWebhook(secret, timestamp, payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBody))
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("%w: %d", ErrUnexpectedStatus, resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,80 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"testing"
+	"time"
+	"wallet/internal/entity"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSender_Send(t *testing.T) {
+	eventID := uuid.New()
+	payload := []byte(`{"type":"transaction.succeeded"}`)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(timestampHeader), 10, 64)
+
+		assert.Equal(t, eventID.String(), r.Header.Get(idHeader))
+		assert.Equal(t, entity.SignWebhook("whsec_test", timestamp, body), r.Header.Get(signatureHeader))
+
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	sender, err := NewSender(Config{Timeout: time.Second, AllowedNetworks: []string{"127.0.0.0/8", "::1/128"}})
+	assert.NoError(t, err)
+
+	code, err := sender.Send(context.Background(), srv.URL+"/ok", "whsec_test", eventID, payload)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, code)
+
+	code, err = sender.Send(context.Background(), srv.URL+"/fail", "whsec_test", eventID, payload)
+	assert.ErrorIs(t, err, ErrUnexpectedStatus)
+	assert.Equal(t, http.StatusInternalServerError, code)
+}
+
+func TestSender_RejectsInternalAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("internal address must not be reached")
+	}))
+	defer srv.Close()
+
+	sender, err := NewSender(Config{Timeout: time.Second})
+	assert.NoError(t, err)
+
+	code, err := sender.Send(context.Background(), srv.URL, "whsec_test", uuid.New(), []byte(`{}`))
+	assert.ErrorIs(t, err, ErrAddressNotAllowed)
+	assert.Zero(t, code)
+}
+
+func TestIsPublic(t *testing.T) {
+	for addr, public := range map[string]bool{
+		"8.8.8.8":         true,
+		"2606:4700::1111": true,
+		"127.0.0.1":       false,
+		"10.1.2.3":        false,
+		"172.16.0.1":      false,
+		"192.168.1.1":     false,
+		"169.254.169.254": false,
+		"100.64.0.1":      false,
+		"0.0.0.0":         false,
+		"::1":             false,
+		"fd00:ec2::254":   false,
+		"fe80::1":         false,
+	} {
+		assert.Equal(t, public, isPublic(netip.MustParseAddr(addr)), addr)
+	}
+}
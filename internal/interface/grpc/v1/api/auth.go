@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"strings"
+	"wallet/internal/entity"
+	"wallet/internal/interface/grpc/v1/pb"
+	"wallet/internal/service"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	apiKeyMetadata        = "x-api-key"
+	authorizationMetadata = "authorization"
+	bearerPrefix          = "Bearer "
+)
+
+type apiKeyAuthenticator interface {
+	Authenticate(ctx context.Context, raw string) (*entity.Principal, error)
+}
+
+type tokenVerifier interface {
+	Verify(ctx context.Context, raw string) (*entity.Principal, error)
+}
+
+// methodScopes lists the scope required by every rpc. Unknown methods, such as reflection, need no scope.
+var methodScopes = map[string]entity.Scope{
+	pb.WalletService_CreateWallet_FullMethodName:   entity.ScopeWalletWrite,
+	pb.WalletService_PostOperation_FullMethodName:  entity.ScopeWalletWrite,
+	pb.WalletService_GetBalance_FullMethodName:     entity.ScopeWalletRead,
+	pb.WalletService_GetTransaction_FullMethodName: entity.ScopeWalletRead,
+	pb.WalletService_WatchBalance_FullMethodName:   entity.ScopeWalletRead,
+}
+
+// Auth authenticates rpcs the same way middleware.Authenticate and middleware.Authorize do for http,
+// reading credentials from the x-api-key or authorization metadata.
+type Auth struct {
+	apiKeys apiKeyAuthenticator
+	tokens  tokenVerifier
+}
+
+// NewAuth creates the interceptors. tokens may be nil when JWT authentication is disabled.
+func NewAuth(apiKeys apiKeyAuthenticator, tokens tokenVerifier) *Auth {
+	return &Auth{
+		apiKeys: apiKeys,
+		tokens:  tokens,
+	}
+}
+
+func (a *Auth) Unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := a.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return handler(ctx, req)
+}
+
+func (a *Auth) Stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+	if err != nil {
+		return toStatus(err)
+	}
+	return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+}
+
+func (a *Auth) authenticate(ctx context.Context, method string) (context.Context, error) {
+	scope, ok := methodScopes[method]
+	if !ok {
+		return ctx, nil
+	}
+
+	var principal *entity.Principal
+	var err error
+
+	if raw := credentials(ctx); a.tokens != nil && strings.Count(raw, ".") == 2 {
+		principal, err = a.tokens.Verify(ctx, raw)
+	} else {
+		principal, err = a.apiKeys.Authenticate(ctx, raw)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !principal.HasScope(scope) {
+		return nil, service.ErrForbidden
+	}
+
+	return entity.ContextWithPrincipal(ctx, principal), nil
+}
+
+func credentials(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if key := md.Get(apiKeyMetadata); len(key) > 0 && key[0] != "" {
+		return key[0]
+	}
+	if auth := md.Get(authorizationMetadata); len(auth) > 0 && strings.HasPrefix(auth[0], bearerPrefix) {
+		return strings.TrimSpace(strings.TrimPrefix(auth[0], bearerPrefix))
+	}
+	return ""
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log"
+	"wallet/internal/entity"
+	"wallet/internal/infrastructure/auth/jwt"
+	"wallet/internal/presenter"
+	transactionRepository "wallet/internal/repository/transaction"
+	walletRepository "wallet/internal/repository/wallet"
+	"wallet/internal/service"
+	"wallet/internal/utils/ratelimit"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var invalidArgumentErrors = []error{
+	presenter.ErrInvalidUUID,
+	service.ErrInvalidUUID,
+	entity.ErrWalletUUIDIsEmpty,
+	entity.ErrInvalidOperationType,
+	entity.ErrInvalidOperationUUID,
+	entity.ErrInvalidStatus,
+	entity.ErrAmountIsOrBelowZero,
+}
+
+// toStatus converts domain errors to grpc status errors, the same way response.Builder.HandleError does for http.
+func toStatus(err error) error {
+	for _, target := range invalidArgumentErrors {
+		if errors.Is(err, target) {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	if errors.Is(err, entity.ErrNotEnoughFunds) || errors.Is(err, entity.ErrWalletFrozen) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if errors.Is(err, walletRepository.ErrWalletNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	if errors.Is(err, transactionRepository.ErrTransactionNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	if errors.Is(err, walletRepository.ErrNoRowsAffected) {
+		return status.Error(codes.Aborted, err.Error())
+	}
+
+	if errors.Is(err, service.ErrUnauthenticated) {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+	if errors.Is(err, jwt.ErrInvalidToken) {
+		return status.Error(codes.Unauthenticated, jwt.ErrInvalidToken.Error())
+	}
+	if errors.Is(err, service.ErrForbidden) {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	if errors.Is(err, ratelimit.ErrLimitExceeded) {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return status.Error(codes.Canceled, err.Error())
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+
+	log.Println("grpc internal error: ", err)
+	return status.Error(codes.Internal, "internal error")
+}
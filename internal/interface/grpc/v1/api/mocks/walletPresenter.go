@@ -0,0 +1,179 @@
+// Code generated by mockery v2.52.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	dto "wallet/internal/dto"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// WalletPresenter is an autogenerated mock type for the walletPresenter type
+type WalletPresenter struct {
+	mock.Mock
+}
+
+// GetBalance provides a mock function with given fields: _a0, _a1
+func (_m *WalletPresenter) GetBalance(_a0 context.Context, _a1 string) (*dto.GetBalanceResponse, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBalance")
+	}
+
+	var r0 *dto.GetBalanceResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*dto.GetBalanceResponse, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *dto.GetBalanceResponse); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.GetBalanceResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTransaction provides a mock function with given fields: _a0, _a1
+func (_m *WalletPresenter) GetTransaction(_a0 context.Context, _a1 string) (*dto.TransactionResponse, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTransaction")
+	}
+
+	var r0 *dto.TransactionResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*dto.TransactionResponse, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *dto.TransactionResponse); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.TransactionResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewWallet provides a mock function with given fields: _a0, _a1
+func (_m *WalletPresenter) NewWallet(_a0 context.Context, _a1 *dto.CreateWalletRequest) (*dto.WalletResponse, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for NewWallet")
+	}
+
+	var r0 *dto.WalletResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.CreateWalletRequest) (*dto.WalletResponse, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.CreateWalletRequest) *dto.WalletResponse); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.WalletResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *dto.CreateWalletRequest) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Subscribe provides a mock function with given fields: ctx, uid, lastEventID
+func (_m *WalletPresenter) Subscribe(ctx context.Context, uid string, lastEventID string) (<-chan dto.WalletEvent, error) {
+	ret := _m.Called(ctx, uid, lastEventID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Subscribe")
+	}
+
+	var r0 <-chan dto.WalletEvent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (<-chan dto.WalletEvent, error)); ok {
+		return rf(ctx, uid, lastEventID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) <-chan dto.WalletEvent); ok {
+		r0 = rf(ctx, uid, lastEventID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan dto.WalletEvent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, uid, lastEventID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Transaction provides a mock function with given fields: _a0, _a1
+func (_m *WalletPresenter) Transaction(_a0 context.Context, _a1 *dto.PostOperationRequest) (*dto.TransactionResponse, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Transaction")
+	}
+
+	var r0 *dto.TransactionResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.PostOperationRequest) (*dto.TransactionResponse, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.PostOperationRequest) *dto.TransactionResponse); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.TransactionResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *dto.PostOperationRequest) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewWalletPresenter creates a new instance of WalletPresenter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewWalletPresenter(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *WalletPresenter {
+	mock := &WalletPresenter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
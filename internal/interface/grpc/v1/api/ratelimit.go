@@ -0,0 +1,127 @@
+package api
+
+import (
+	"context"
+	"log"
+	"math"
+	"net"
+	"strconv"
+	"wallet/internal/entity"
+	"wallet/internal/utils/metrics"
+	"wallet/internal/utils/ratelimit"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const retryAfterMetadata = "retry-after"
+
+type limiter interface {
+	Allow(ctx context.Context, key string) (ratelimit.Result, error)
+}
+
+// KeyFunc returns the rate limit key of the rpc request. Requests with an empty key are not limited.
+type KeyFunc func(ctx context.Context, req any) string
+
+// ClientKey limits by principal, or by peer address when authentication is disabled.
+// The keys are the same as of middleware.ClientKey, so http and grpc share the limits.
+func ClientKey(ctx context.Context, _ any) string {
+	if principal, ok := entity.PrincipalFromContext(ctx); ok {
+		return "client:" + principal.Subject
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		host = p.Addr.String()
+	}
+	return "addr:" + host
+}
+
+// WalletKey limits by the wallet uuid of the request, like middleware.WalletKey.
+func WalletKey(ctx context.Context, req any) string {
+	r, ok := req.(interface{ GetWalletUuid() string })
+	if !ok || r.GetWalletUuid() == "" {
+		return ""
+	}
+
+	uid, err := uuid.Parse(r.GetWalletUuid())
+	if err != nil {
+		if k := ClientKey(ctx, nil); k != "" {
+			return "wallet:" + k
+		}
+		return ""
+	}
+	return "wallet:" + uid.String()
+}
+
+// RateLimit rejects rpcs over the limit with ResourceExhausted and the retry-after header,
+// the same way middleware.RateLimit does for http. Limiter failures are logged and let the rpc through.
+// The interceptors must run after Auth, otherwise clients are limited by address.
+type RateLimit struct {
+	limiter limiter
+	scope   string
+	key     KeyFunc
+}
+
+func NewRateLimit(l limiter, scope string, key KeyFunc) *RateLimit {
+	return &RateLimit{
+		limiter: l,
+		scope:   scope,
+		key:     key,
+	}
+}
+
+func (rl *RateLimit) Unary(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := rl.allow(ctx, req, func(md metadata.MD) error { return grpc.SetHeader(ctx, md) }); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// Stream checks the limit on every received message, the wallet of a stream is only known from its request.
+func (rl *RateLimit) Stream(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &rateLimitedStream{ServerStream: ss, rl: rl})
+}
+
+func (rl *RateLimit) allow(ctx context.Context, req any, setHeader func(metadata.MD) error) error {
+	k := rl.key(ctx, req)
+	if k == "" {
+		return nil
+	}
+
+	res, err := rl.limiter.Allow(ctx, k)
+	if err != nil {
+		log.Printf("rate limit %s failed: %v\n", rl.scope, err)
+		return nil
+	}
+
+	if !res.Allowed {
+		metrics.IncRateLimitRejected(rl.scope)
+
+		retryAfter := strconv.Itoa(max(1, int(math.Ceil(res.RetryAfter.Seconds()))))
+		if err := setHeader(metadata.Pairs(retryAfterMetadata, retryAfter)); err != nil {
+			log.Println("error setting retry-after header: ", err)
+		}
+		return toStatus(ratelimit.ErrLimitExceeded)
+	}
+
+	return nil
+}
+
+type rateLimitedStream struct {
+	grpc.ServerStream
+	rl *RateLimit
+}
+
+func (s *rateLimitedStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return s.rl.allow(s.Context(), m, s.SetHeader)
+}
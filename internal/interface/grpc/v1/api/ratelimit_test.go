@@ -0,0 +1,49 @@
+package api
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"wallet/internal/entity"
+	"wallet/internal/interface/grpc/v1/pb"
+	"wallet/internal/utils/ratelimit"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRateLimitUnary(t *testing.T) {
+	rl := NewRateLimit(ratelimit.NewLocal(ratelimit.Config{Rate: 0.001, Burst: 1}), "wallet", WalletKey)
+	handler := func(ctx context.Context, req any) (any, error) {
+		return "ok", nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: pb.WalletService_GetBalance_FullMethodName}
+	ctx := entity.ContextWithPrincipal(context.Background(), &entity.Principal{Subject: "client"})
+	wallet := uuid.New()
+
+	resp, err := rl.Unary(ctx, &pb.GetBalanceRequest{WalletUuid: wallet.String()}, info, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+
+	// the same wallet spelled in upper case shares the bucket
+	_, err = rl.Unary(ctx, &pb.GetBalanceRequest{WalletUuid: strings.ToUpper(wallet.String())}, info, handler)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// another wallet has its own bucket
+	_, err = rl.Unary(ctx, &pb.GetBalanceRequest{WalletUuid: uuid.NewString()}, info, handler)
+	assert.NoError(t, err)
+}
+
+func TestRateLimitKeys(t *testing.T) {
+	ctx := entity.ContextWithPrincipal(context.Background(), &entity.Principal{Subject: "client"})
+
+	assert.Equal(t, "client:client", ClientKey(ctx, nil))
+	wallet := uuid.New()
+	assert.Equal(t, "wallet:"+wallet.String(), WalletKey(ctx, &pb.WatchBalanceRequest{WalletUuid: strings.ToUpper(wallet.String())}))
+	assert.Equal(t, "wallet:client:client", WalletKey(ctx, &pb.WatchBalanceRequest{WalletUuid: "a"}))
+	assert.Equal(t, "", WalletKey(ctx, &pb.CreateWalletRequest{}))
+	assert.Equal(t, "", ClientKey(context.Background(), nil))
+}
@@ -0,0 +1,181 @@
+package api
+
+import (
+	"context"
+	"wallet/internal/dto"
+	"wallet/internal/entity"
+	"wallet/internal/interface/grpc/v1/pb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+//go:generate mockery --name walletPresenter --structname=WalletPresenter
+type walletPresenter interface {
+	Transaction(context.Context, *dto.PostOperationRequest) (*dto.TransactionResponse, error)
+	GetTransaction(context.Context, string) (*dto.TransactionResponse, error)
+	GetBalance(context.Context, string) (*dto.GetBalanceResponse, error)
+	NewWallet(context.Context, *dto.CreateWalletRequest) (*dto.WalletResponse, error)
+	Subscribe(ctx context.Context, uid string, lastEventID string) (<-chan dto.WalletEvent, error)
+}
+
+type Server struct {
+	pb.UnimplementedWalletServiceServer
+
+	wallet walletPresenter
+}
+
+func RegisterServer(
+	registrar grpc.ServiceRegistrar,
+	wallet walletPresenter,
+) *Server {
+	srv := &Server{
+		wallet: wallet,
+	}
+
+	pb.RegisterWalletServiceServer(registrar, srv)
+
+	return srv
+}
+
+func (s *Server) CreateWallet(ctx context.Context, req *pb.CreateWalletRequest) (*pb.CreateWalletResponse, error) {
+	wallet, err := s.wallet.NewWallet(ctx, &dto.CreateWalletRequest{Owner: req.GetOwner()})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.CreateWalletResponse{
+		Wallet: &pb.Wallet{
+			Uuid:   wallet.UUID,
+			Owner:  wallet.Owner,
+			Amount: wallet.Amount,
+		},
+	}, nil
+}
+
+func (s *Server) GetBalance(ctx context.Context, req *pb.GetBalanceRequest) (*pb.GetBalanceResponse, error) {
+	balance, err := s.wallet.GetBalance(ctx, req.GetWalletUuid())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.GetBalanceResponse{
+		WalletUuid: req.GetWalletUuid(),
+		Amount:     balance.Amount,
+	}, nil
+}
+
+func (s *Server) PostOperation(ctx context.Context, req *pb.PostOperationRequest) (*pb.PostOperationResponse, error) {
+	transaction, err := s.wallet.Transaction(ctx, &dto.PostOperationRequest{
+		WalletId:      req.GetWalletUuid(),
+		OperationType: fromOperationType(req.GetOperationType()),
+		Amount:        req.GetAmount(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.PostOperationResponse{IdempotencyKey: transaction.IdempotencyKey}, nil
+}
+
+func (s *Server) GetTransaction(ctx context.Context, req *pb.GetTransactionRequest) (*pb.GetTransactionResponse, error) {
+	transaction, err := s.wallet.GetTransaction(ctx, req.GetIdempotencyKey())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.GetTransactionResponse{
+		Transaction: &pb.Transaction{
+			IdempotencyKey: transaction.IdempotencyKey,
+			WalletUuid:     transaction.WalletId,
+			OperationType:  toOperationType(transaction.OperationType),
+			Amount:         transaction.Amount,
+			Status:         toTransactionStatus(transaction.Status),
+			CreatedAt:      timestamppb.New(transaction.CreatedAt),
+			UpdatedAt:      timestamppb.New(transaction.UpdatedAt),
+		},
+	}, nil
+}
+
+// WatchBalance sends the current balance and then every change until the client goes away.
+// When the client falls behind the stream ends with Unavailable, the client is expected to call it again.
+func (s *Server) WatchBalance(req *pb.WatchBalanceRequest, stream pb.WalletService_WatchBalanceServer) error {
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	// subscribe before reading the balance, so no change in between is lost
+	events, err := s.wallet.Subscribe(ctx, req.GetWalletUuid(), "")
+	if err != nil {
+		return toStatus(err)
+	}
+
+	balance, err := s.wallet.GetBalance(ctx, req.GetWalletUuid())
+	if err != nil {
+		return toStatus(err)
+	}
+
+	last := balance.Amount
+	if err := stream.Send(&pb.WatchBalanceResponse{
+		WalletUuid: req.GetWalletUuid(),
+		Amount:     last,
+	}); err != nil {
+		return err
+	}
+
+	for e := range events {
+		if e.Balance == nil || *e.Balance == last {
+			continue
+		}
+
+		last = *e.Balance
+		if err := stream.Send(&pb.WatchBalanceResponse{
+			WalletUuid: req.GetWalletUuid(),
+			Amount:     last,
+		}); err != nil {
+			return err
+		}
+	}
+
+	// the hub closes the channel of subscribers falling behind, unlike the client going away it is not a clean end
+	if err := stream.Context().Err(); err != nil {
+		return status.FromContextError(err).Err()
+	}
+	return status.Error(codes.Unavailable, "balance stream fell behind, watch again")
+}
+
+func fromOperationType(t pb.OperationType) string {
+	switch t {
+	case pb.OperationType_OPERATION_TYPE_DEPOSIT:
+		return string(entity.Deposit)
+	case pb.OperationType_OPERATION_TYPE_WITHDRAW:
+		return string(entity.Withdraw)
+	default:
+		return ""
+	}
+}
+
+func toOperationType(t string) pb.OperationType {
+	switch entity.OperationType(t) {
+	case entity.Deposit:
+		return pb.OperationType_OPERATION_TYPE_DEPOSIT
+	case entity.Withdraw:
+		return pb.OperationType_OPERATION_TYPE_WITHDRAW
+	default:
+		return pb.OperationType_OPERATION_TYPE_UNSPECIFIED
+	}
+}
+
+func toTransactionStatus(s string) pb.TransactionStatus {
+	switch entity.Status(s) {
+	case entity.New:
+		return pb.TransactionStatus_TRANSACTION_STATUS_NEW
+	case entity.Success:
+		return pb.TransactionStatus_TRANSACTION_STATUS_SUCCESS
+	case entity.Failure:
+		return pb.TransactionStatus_TRANSACTION_STATUS_FAILURE
+	default:
+		return pb.TransactionStatus_TRANSACTION_STATUS_UNSPECIFIED
+	}
+}
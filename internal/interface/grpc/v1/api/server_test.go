@@ -0,0 +1,147 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"wallet/internal/dto"
+	"wallet/internal/entity"
+	"wallet/internal/interface/grpc/v1/api/mocks"
+	"wallet/internal/interface/grpc/v1/pb"
+	"wallet/internal/presenter"
+	walletRepository "wallet/internal/repository/wallet"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPostOperation(t *testing.T) {
+	mockWallet := new(mocks.WalletPresenter)
+	srv := &Server{wallet: mockWallet}
+
+	tests := []struct {
+		name string
+		req  *pb.PostOperationRequest
+		resp *dto.TransactionResponse
+		err  error
+		code codes.Code
+	}{
+		{
+			"Valid Request",
+			&pb.PostOperationRequest{WalletUuid: "valid-uuid", OperationType: pb.OperationType_OPERATION_TYPE_DEPOSIT, Amount: 100},
+			&dto.TransactionResponse{IdempotencyKey: "key"},
+			nil,
+			codes.OK,
+		},
+		{
+			"Invalid Wallet UUID",
+			&pb.PostOperationRequest{WalletUuid: "invalid-uuid", OperationType: pb.OperationType_OPERATION_TYPE_DEPOSIT, Amount: 100},
+			nil,
+			presenter.ErrInvalidUUID,
+			codes.InvalidArgument,
+		},
+		{
+			"Not Enough Funds",
+			&pb.PostOperationRequest{WalletUuid: "valid-uuid", OperationType: pb.OperationType_OPERATION_TYPE_WITHDRAW, Amount: 100},
+			nil,
+			entity.ErrNotEnoughFunds,
+			codes.FailedPrecondition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockWallet.On("Transaction", mock.Anything, &dto.PostOperationRequest{
+				WalletId:      tt.req.WalletUuid,
+				OperationType: fromOperationType(tt.req.OperationType),
+				Amount:        tt.req.Amount,
+			}).Return(tt.resp, tt.err).Once()
+
+			resp, err := srv.PostOperation(context.Background(), tt.req)
+
+			assert.Equal(t, tt.code, status.Code(err))
+			if tt.err == nil {
+				assert.Equal(t, "key", resp.IdempotencyKey)
+			}
+			mockWallet.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetBalance(t *testing.T) {
+	mockWallet := new(mocks.WalletPresenter)
+	srv := &Server{wallet: mockWallet}
+
+	mockWallet.On("GetBalance", mock.Anything, "valid-uuid").Return(&dto.GetBalanceResponse{Amount: 100}, nil).Once()
+	mockWallet.On("GetBalance", mock.Anything, "unknown-uuid").Return(nil, walletRepository.ErrWalletNotFound).Once()
+
+	resp, err := srv.GetBalance(context.Background(), &pb.GetBalanceRequest{WalletUuid: "valid-uuid"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100), resp.Amount)
+
+	_, err = srv.GetBalance(context.Background(), &pb.GetBalanceRequest{WalletUuid: "unknown-uuid"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	mockWallet.AssertExpectations(t)
+}
+
+type watchBalanceStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent []int64
+}
+
+func (s *watchBalanceStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *watchBalanceStream) Send(resp *pb.WatchBalanceResponse) error {
+	s.sent = append(s.sent, resp.Amount)
+	return nil
+}
+
+func TestWatchBalance(t *testing.T) {
+	const uid = "wallet-uuid"
+
+	tests := []struct {
+		name   string
+		cancel bool
+		code   codes.Code
+	}{
+		// the hub drops slow subscribers by closing the channel
+		{"Subscriber Dropped", false, codes.Unavailable},
+		{"Client Gone", true, codes.Canceled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockWallet := new(mocks.WalletPresenter)
+			srv := &Server{wallet: mockWallet}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			balance := int64(150)
+			events := make(chan dto.WalletEvent, 3)
+			events <- dto.WalletEvent{WalletId: uid, Balance: &balance}
+			events <- dto.WalletEvent{WalletId: uid, Balance: &balance}
+			events <- dto.WalletEvent{WalletId: uid}
+			close(events)
+			if tt.cancel {
+				cancel()
+			}
+
+			mockWallet.On("Subscribe", mock.Anything, uid, "").Return((<-chan dto.WalletEvent)(events), nil).Once()
+			mockWallet.On("GetBalance", mock.Anything, uid).Return(&dto.GetBalanceResponse{Amount: 100}, nil).Once()
+
+			stream := &watchBalanceStream{ctx: ctx}
+			err := srv.WatchBalance(&pb.WatchBalanceRequest{WalletUuid: uid}, stream)
+
+			assert.Equal(t, tt.code, status.Code(err))
+			assert.Equal(t, []int64{100, 150}, stream.sent)
+			mockWallet.AssertExpectations(t)
+		})
+	}
+}
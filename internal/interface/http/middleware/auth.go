@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"wallet/internal/entity"
+	"wallet/internal/interface/response"
+	"wallet/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	apiKeyHeader        = "X-API-Key"
+	authorizationHeader = "Authorization"
+	bearerPrefix        = "Bearer "
+)
+
+//go:generate mockery --name apiKeyAuthenticator --structname=APIKeyAuthenticator
+type apiKeyAuthenticator interface {
+	Authenticate(ctx context.Context, raw string) (*entity.Principal, error)
+}
+
+//go:generate mockery --name tokenVerifier --structname=TokenVerifier
+type tokenVerifier interface {
+	Verify(ctx context.Context, raw string) (*entity.Principal, error)
+}
+
+// ScopePolicy returns the scope required to serve the request.
+type ScopePolicy func(r *http.Request) entity.Scope
+
+// ScopeByMethod requires wallet:read for safe methods and wallet:write for the rest.
+func ScopeByMethod(r *http.Request) entity.Scope {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return entity.ScopeWalletRead
+	default:
+		return entity.ScopeWalletWrite
+	}
+}
+
+// FixedScope requires the same scope for every request.
+func FixedScope(scope entity.Scope) ScopePolicy {
+	return func(*http.Request) entity.Scope {
+		return scope
+	}
+}
+
+// Authenticate resolves the caller from the X-API-Key or Authorization: Bearer header
+// and stores the principal in the request context. Bearer credentials shaped as JWT
+// are checked by tokens, which may be nil when JWT authentication is disabled.
+func Authenticate(apiKeys apiKeyAuthenticator, tokens tokenVerifier) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				var principal *entity.Principal
+				var err error
+
+				if raw := credentials(r); tokens != nil && isJWT(raw) {
+					principal, err = tokens.Verify(r.Context(), raw)
+				} else {
+					principal, err = apiKeys.Authenticate(r.Context(), raw)
+				}
+				if err != nil {
+					response.Resp().HandleError(err).Build().Write(w)
+					return
+				}
+
+				next.ServeHTTP(w, r.WithContext(entity.ContextWithPrincipal(r.Context(), principal)))
+			},
+		)
+	}
+}
+
+// Authorize rejects requests whose principal lacks the scope required by policy.
+func Authorize(policy ScopePolicy) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				principal, ok := entity.PrincipalFromContext(r.Context())
+				if !ok {
+					response.Resp().HandleError(service.ErrUnauthenticated).Build().Write(w)
+					return
+				}
+				if !principal.HasScope(policy(r)) {
+					response.Resp().HandleError(service.ErrForbidden).Build().Write(w)
+					return
+				}
+
+				next.ServeHTTP(w, r)
+			},
+		)
+	}
+}
+
+func isJWT(raw string) bool {
+	return strings.Count(raw, ".") == 2
+}
+
+func credentials(r *http.Request) string {
+	if key := r.Header.Get(apiKeyHeader); key != "" {
+		return key
+	}
+	if auth := r.Header.Get(authorizationHeader); strings.HasPrefix(auth, bearerPrefix) {
+		return strings.TrimSpace(strings.TrimPrefix(auth, bearerPrefix))
+	}
+	return ""
+}
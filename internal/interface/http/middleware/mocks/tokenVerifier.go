@@ -0,0 +1,59 @@
+// Code generated by mockery v2.52.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	entity "wallet/internal/entity"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// TokenVerifier is an autogenerated mock type for the tokenVerifier type
+type TokenVerifier struct {
+	mock.Mock
+}
+
+// Verify provides a mock function with given fields: ctx, raw
+func (_m *TokenVerifier) Verify(ctx context.Context, raw string) (*entity.Principal, error) {
+	ret := _m.Called(ctx, raw)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Verify")
+	}
+
+	var r0 *entity.Principal
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.Principal, error)); ok {
+		return rf(ctx, raw)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.Principal); ok {
+		r0 = rf(ctx, raw)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Principal)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, raw)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewTokenVerifier creates a new instance of TokenVerifier. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewTokenVerifier(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *TokenVerifier {
+	mock := &TokenVerifier{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
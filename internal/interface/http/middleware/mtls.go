@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"wallet/internal/entity"
+	"wallet/internal/interface/response"
+	"wallet/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+// ClientCertificate stores the identity of a verified client certificate in the request context.
+// Requests over plain HTTP or without a certificate pass unchanged.
+func ClientCertificate(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if identity := clientIdentity(r); identity != nil {
+				r = r.WithContext(entity.ContextWithClientIdentity(r.Context(), identity))
+			}
+			next.ServeHTTP(w, r)
+		},
+	)
+}
+
+// RequireClientCertificate rejects requests whose client certificate has none of the allowed names.
+// It runs after ClientCertificate.
+func RequireClientCertificate(allowed []string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				identity, ok := entity.ClientIdentityFromContext(r.Context())
+				if !ok {
+					response.Resp().HandleError(service.ErrUnauthenticated).Build().Write(w)
+					return
+				}
+				if !slices.ContainsFunc(identity.Names(), func(name string) bool { return slices.Contains(allowed, name) }) {
+					response.Resp().HandleError(service.ErrForbidden).Build().Write(w)
+					return
+				}
+
+				next.ServeHTTP(w, r)
+			},
+		)
+	}
+}
+
+// clientIdentity reads the leaf of the verified chain, unverified certificates are ignored.
+func clientIdentity(r *http.Request) *entity.ClientIdentity {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil
+	}
+
+	cert := r.TLS.VerifiedChains[0][0]
+	identity := &entity.ClientIdentity{
+		Subject:      cert.Subject.CommonName,
+		DNSNames:     cert.DNSNames,
+		SerialNumber: cert.SerialNumber.String(),
+	}
+	for _, uri := range cert.URIs {
+		identity.URIs = append(identity.URIs, uri.String())
+	}
+	return identity
+}
@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"wallet/internal/entity"
+	"wallet/internal/interface/response"
+	"wallet/internal/utils/metrics"
+	"wallet/internal/utils/ratelimit"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	jsoniter "github.com/json-iterator/go"
+)
+
+const maxPeekBodySize = 1 << 20
+
+type limiter interface {
+	Allow(ctx context.Context, key string) (ratelimit.Result, error)
+}
+
+// KeyFunc returns the rate limit key of the request. Requests with an empty key are not limited.
+type KeyFunc func(r *http.Request) string
+
+// ClientKey limits by principal, or by remote address when authentication is disabled.
+func ClientKey(r *http.Request) string {
+	if principal, ok := entity.PrincipalFromContext(r.Context()); ok {
+		return "client:" + principal.Subject
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "addr:" + host
+}
+
+// WalletKey limits by wallet uuid taken from the path or from the walletId field of the body.
+// Requests without a valid wallet uuid are limited by client, so spelling the uuid differently does not bypass the limit.
+func WalletKey(r *http.Request) string {
+	if uid := mux.Vars(r)["uuid"]; uid != "" {
+		return walletKey(r, uid)
+	}
+
+	if r.Method != http.MethodPost || r.Body == nil {
+		return ""
+	}
+
+	// only the head of the body is read, the handler gets it back followed by the rest
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxPeekBodySize))
+	r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), r.Body), Closer: r.Body}
+	if err != nil {
+		return ""
+	}
+
+	var req struct {
+		WalletId string `json:"walletId"`
+	}
+	if err := jsoniter.Unmarshal(body, &req); err != nil || req.WalletId == "" {
+		return ""
+	}
+	return walletKey(r, req.WalletId)
+}
+
+func walletKey(r *http.Request, raw string) string {
+	uid, err := uuid.Parse(raw)
+	if err != nil {
+		return "wallet:" + ClientKey(r)
+	}
+	return "wallet:" + uid.String()
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// RateLimit rejects requests over the limit with 429 and Retry-After.
+// Limiter failures are logged and let the request through.
+func RateLimit(l limiter, scope string, key KeyFunc) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				k := key(r)
+				if k == "" {
+					next.ServeHTTP(w, r)
+					return
+				}
+
+				res, err := l.Allow(r.Context(), k)
+				if err != nil {
+					log.Printf("rate limit %s failed: %v\n", scope, err)
+					next.ServeHTTP(w, r)
+					return
+				}
+
+				if !res.Allowed {
+					metrics.IncRateLimitRejected(scope)
+
+					response.
+						Resp().
+						WithHeader("Retry-After", strconv.Itoa(max(1, int(math.Ceil(res.RetryAfter.Seconds()))))).
+						HandleError(ratelimit.ErrLimitExceeded).
+						Build().
+						Write(w)
+					return
+				}
+
+				next.ServeHTTP(w, r)
+			},
+		)
+	}
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"wallet/internal/entity"
+	"wallet/internal/utils/ratelimit"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func walletRequest(wallet string, padding int) *http.Request {
+	body := `{"walletId":"` + wallet + `","comment":"` + strings.Repeat("x", padding) + `"}`
+	return httptest.NewRequest(http.MethodPost, "/wallet", strings.NewReader(body))
+}
+
+func TestRateLimit(t *testing.T) {
+	router := mux.NewRouter()
+	router.Use(RateLimit(ratelimit.NewLocal(ratelimit.Config{Rate: 0.001, Burst: 1}), "wallet", WalletKey))
+	router.HandleFunc("/wallet", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		_, _ = w.Write([]byte(strconv.Itoa(len(body))))
+	}).Methods(http.MethodPost)
+
+	wallet := uuid.New()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, walletRequest(wallet.String(), 0))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// the same wallet spelled in upper case shares the bucket
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, walletRequest(strings.ToUpper(wallet.String()), 0))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "1000", w.Header().Get("Retry-After"))
+
+	// the handler gets the whole body, including the part over the peek limit
+	req := walletRequest(uuid.NewString(), 2*maxPeekBodySize)
+	size := req.ContentLength
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, strconv.FormatInt(size, 10), w.Body.String())
+}
+
+func TestWalletKey(t *testing.T) {
+	wallet := uuid.New()
+	principal := &entity.Principal{Subject: "client"}
+
+	tcs := []struct {
+		name string
+		req  *http.Request
+		vars map[string]string
+		key  string
+	}{
+		{"Path", httptest.NewRequest(http.MethodGet, "/wallets", nil), map[string]string{"uuid": strings.ToUpper(wallet.String())}, "wallet:" + wallet.String()},
+		{"Body", walletRequest(wallet.String(), 0), nil, "wallet:" + wallet.String()},
+		{"Invalid uuid", walletRequest("not-a-uuid", 0), nil, "wallet:client:client"},
+		{"No wallet", httptest.NewRequest(http.MethodGet, "/wallets", nil), nil, ""},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			req := tc.req.WithContext(entity.ContextWithPrincipal(tc.req.Context(), principal))
+			if tc.vars != nil {
+				req = mux.SetURLVars(req, tc.vars)
+			}
+
+			assert.Equal(t, tc.key, WalletKey(req))
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net/http"
+	"wallet/internal/interface/response"
+
+	"github.com/google/uuid"
+)
+
+const maxRequestIDLength = 128
+
+// RequestID sets X-Request-ID on the response, keeping the one sent by the client or a proxy
+// when it is not too long. Error responses report it as the problem instance.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(response.RequestIDHeader)
+			if id == "" || len(id) > maxRequestIDLength {
+				id = uuid.NewString()
+			}
+			w.Header().Set(response.RequestIDHeader, id)
+
+			next.ServeHTTP(w, r)
+		},
+	)
+}
@@ -0,0 +1,260 @@
+package api
+
+import (
+	"context"
+	"github.com/gorilla/mux"
+	"net/http"
+	"wallet/internal/dto"
+	"wallet/internal/interface/response"
+)
+
+//go:generate mockery --name apiKeyPresenter --structname=APIKeyPresenter
+type apiKeyPresenter interface {
+	Issue(context.Context, *dto.IssueAPIKeyRequest) (*dto.IssueAPIKeyResponse, error)
+	List(context.Context) ([]dto.APIKeyResponse, error)
+	Revoke(context.Context, string) error
+}
+
+//go:generate mockery --name walletAdminPresenter --structname=WalletAdminPresenter
+type walletAdminPresenter interface {
+	SetFrozen(ctx context.Context, uid string, frozen bool) (*dto.WalletResponse, error)
+	InvalidateBalance(ctx context.Context, uid string) error
+}
+
+//go:generate mockery --name workerAdminPresenter --structname=WorkerAdminPresenter
+type workerAdminPresenter interface {
+	PauseConsumption(context.Context) *dto.WorkerStatusResponse
+	ResumeConsumption(context.Context) *dto.WorkerStatusResponse
+	WorkerStatus(context.Context) *dto.WorkerStatusResponse
+}
+
+type AdminRouter struct {
+	apiKeys apiKeyPresenter
+	wallets walletAdminPresenter
+	workers workerAdminPresenter
+	router  *mux.Router
+}
+
+const (
+	apiKeysPath        = "/api-keys"
+	apiKeyPath         = "/api-keys/{id}"
+	freezeWalletPath   = "/wallets/{uuid}/freeze"
+	unfreezeWalletPath = "/wallets/{uuid}/unfreeze"
+	walletCachePath    = "/wallets/{uuid}/cache"
+	workersPath        = "/workers"
+	pauseWorkersPath   = "/workers/pause"
+	resumeWorkersPath  = "/workers/resume"
+)
+
+func RegisterAdminRouter(
+	router *mux.Router,
+	apiKeys apiKeyPresenter,
+	wallets walletAdminPresenter,
+	workers workerAdminPresenter,
+) *AdminRouter {
+	rt := &AdminRouter{
+		router:  router,
+		apiKeys: apiKeys,
+		wallets: wallets,
+		workers: workers,
+	}
+
+	rt.router.HandleFunc(apiKeysPath, rt.issueAPIKey).Methods(http.MethodPost)
+	rt.router.HandleFunc(apiKeysPath, rt.listAPIKeys).Methods(http.MethodGet)
+	rt.router.HandleFunc(apiKeyPath, rt.revokeAPIKey).Methods(http.MethodDelete)
+	rt.router.HandleFunc(freezeWalletPath, rt.freezeWallet).Methods(http.MethodPost)
+	rt.router.HandleFunc(unfreezeWalletPath, rt.unfreezeWallet).Methods(http.MethodPost)
+	rt.router.HandleFunc(walletCachePath, rt.invalidateBalance).Methods(http.MethodDelete)
+	rt.router.HandleFunc(workersPath, rt.workerStatus).Methods(http.MethodGet)
+	rt.router.HandleFunc(pauseWorkersPath, rt.pauseWorkers).Methods(http.MethodPost)
+	rt.router.HandleFunc(resumeWorkersPath, rt.resumeWorkers).Methods(http.MethodPost)
+
+	return rt
+}
+
+// @Summary		IssueAPIKey
+// @Description	issue new api key with scopes wallet:read, wallet:write or wallet:admin. The key is returned only once
+// @Tags			admin
+// @Accept			json
+// @Produce		json
+// @Param			input	body	dto.IssueAPIKeyRequest	true	"request"
+// @Success		201	{object}	dto.IssueAPIKeyResponse
+// @Failure		400,401,403,422	{object}	dto.Problem
+// @Success		500	{object}	dto.Problem
+// @Success		default	{object}	dto.Problem
+// @Security		ApiKeyAuth
+// @Router			/admin/api-keys [post]
+func (rt *AdminRouter) issueAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req dto.IssueAPIKeyRequest
+
+	if err := getFromBody(r, &req); err != nil {
+		response.
+			Resp().
+			WithCode(http.StatusBadRequest).
+			WithError(ErrInvalidFormData).
+			Build().
+			Write(w)
+		return
+	}
+
+	key, err := rt.apiKeys.Issue(r.Context(), &req)
+	if err != nil {
+		response.Resp().HandleError(err).Build().Write(w)
+		return
+	}
+
+	response.Resp().WithCode(http.StatusCreated).WithPayload(key).Build().Write(w)
+}
+
+// @Summary		ListAPIKeys
+// @Description	list issued api keys without secrets
+// @Tags			admin
+// @Accept			json
+// @Produce		json
+// @Success		200	{array}	dto.APIKeyResponse
+// @Failure		401,403	{object}	dto.Problem
+// @Success		500	{object}	dto.Problem
+// @Success		default	{object}	dto.Problem
+// @Security		ApiKeyAuth
+// @Router			/admin/api-keys [get]
+func (rt *AdminRouter) listAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := rt.apiKeys.List(r.Context())
+	if err != nil {
+		response.Resp().HandleError(err).Build().Write(w)
+		return
+	}
+
+	response.Resp().WithCode(http.StatusOK).WithPayload(keys).Build().Write(w)
+}
+
+// @Summary		RevokeAPIKey
+// @Description	revoke api key by id
+// @Tags			admin
+// @Accept			json
+// @Produce		json
+// @Param			id	path	string	true	"api key id"
+// @Success		204	{object}	nil
+// @Failure		400,401,403,404	{object}	dto.Problem
+// @Success		500	{object}	dto.Problem
+// @Success		default	{object}	dto.Problem
+// @Security		ApiKeyAuth
+// @Router			/admin/api-keys/{id} [delete]
+func (rt *AdminRouter) revokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	const id = "id"
+
+	if err := rt.apiKeys.Revoke(r.Context(), mux.Vars(r)[id]); err != nil {
+		response.Resp().HandleError(err).Build().Write(w)
+		return
+	}
+
+	response.Resp().WithCode(http.StatusNoContent).Build().Write(w)
+}
+
+// @Summary		FreezeWallet
+// @Description	freeze wallet, new operations on it fail with 409 and queued ones are marked as failed
+// @Tags			admin
+// @Accept			json
+// @Produce		json
+// @Param			uuid	path	string	true	"wallet uuid"
+// @Success		200	{object}	dto.WalletResponse
+// @Failure		400,401,403,404	{object}	dto.Problem
+// @Success		500	{object}	dto.Problem
+// @Success		default	{object}	dto.Problem
+// @Security		ApiKeyAuth
+// @Router			/admin/wallets/{uuid}/freeze [post]
+func (rt *AdminRouter) freezeWallet(w http.ResponseWriter, r *http.Request) {
+	rt.setFrozen(w, r, true)
+}
+
+// @Summary		UnfreezeWallet
+// @Description	unfreeze wallet
+// @Tags			admin
+// @Accept			json
+// @Produce		json
+// @Param			uuid	path	string	true	"wallet uuid"
+// @Success		200	{object}	dto.WalletResponse
+// @Failure		400,401,403,404	{object}	dto.Problem
+// @Success		500	{object}	dto.Problem
+// @Success		default	{object}	dto.Problem
+// @Security		ApiKeyAuth
+// @Router			/admin/wallets/{uuid}/unfreeze [post]
+func (rt *AdminRouter) unfreezeWallet(w http.ResponseWriter, r *http.Request) {
+	rt.setFrozen(w, r, false)
+}
+
+func (rt *AdminRouter) setFrozen(w http.ResponseWriter, r *http.Request, frozen bool) {
+	const uuid = "uuid"
+
+	wallet, err := rt.wallets.SetFrozen(r.Context(), mux.Vars(r)[uuid], frozen)
+	if err != nil {
+		response.Resp().HandleError(err).Build().Write(w)
+		return
+	}
+
+	response.Resp().WithCode(http.StatusOK).WithPayload(wallet).Build().Write(w)
+}
+
+// @Summary		InvalidateBalance
+// @Description	drop cached balance of the wallet, the next read goes to the database
+// @Tags			admin
+// @Accept			json
+// @Produce		json
+// @Param			uuid	path	string	true	"wallet uuid"
+// @Success		204	{object}	nil
+// @Failure		400,401,403	{object}	dto.Problem
+// @Success		500	{object}	dto.Problem
+// @Success		default	{object}	dto.Problem
+// @Security		ApiKeyAuth
+// @Router			/admin/wallets/{uuid}/cache [delete]
+func (rt *AdminRouter) invalidateBalance(w http.ResponseWriter, r *http.Request) {
+	const uuid = "uuid"
+
+	if err := rt.wallets.InvalidateBalance(r.Context(), mux.Vars(r)[uuid]); err != nil {
+		response.Resp().HandleError(err).Build().Write(w)
+		return
+	}
+
+	response.Resp().WithCode(http.StatusNoContent).Build().Write(w)
+}
+
+// @Summary		WorkerStatus
+// @Description	get state of the workers consuming transactions
+// @Tags			admin
+// @Accept			json
+// @Produce		json
+// @Success		200	{object}	dto.WorkerStatusResponse
+// @Failure		401,403	{object}	dto.Problem
+// @Success		default	{object}	dto.Problem
+// @Security		ApiKeyAuth
+// @Router			/admin/workers [get]
+func (rt *AdminRouter) workerStatus(w http.ResponseWriter, r *http.Request) {
+	response.Resp().WithCode(http.StatusOK).WithPayload(rt.workers.WorkerStatus(r.Context())).Build().Write(w)
+}
+
+// @Summary		PauseWorkers
+// @Description	stop taking new transactions from the broker on this replica, e.g. during incidents. Workers finish the transactions they already took
+// @Tags			admin
+// @Accept			json
+// @Produce		json
+// @Success		200	{object}	dto.WorkerStatusResponse
+// @Failure		401,403	{object}	dto.Problem
+// @Success		default	{object}	dto.Problem
+// @Security		ApiKeyAuth
+// @Router			/admin/workers/pause [post]
+func (rt *AdminRouter) pauseWorkers(w http.ResponseWriter, r *http.Request) {
+	response.Resp().WithCode(http.StatusOK).WithPayload(rt.workers.PauseConsumption(r.Context())).Build().Write(w)
+}
+
+// @Summary		ResumeWorkers
+// @Description	resume taking transactions from the broker on this replica
+// @Tags			admin
+// @Accept			json
+// @Produce		json
+// @Success		200	{object}	dto.WorkerStatusResponse
+// @Failure		401,403	{object}	dto.Problem
+// @Success		default	{object}	dto.Problem
+// @Security		ApiKeyAuth
+// @Router			/admin/workers/resume [post]
+func (rt *AdminRouter) resumeWorkers(w http.ResponseWriter, r *http.Request) {
+	response.Resp().WithCode(http.StatusOK).WithPayload(rt.workers.ResumeConsumption(r.Context())).Build().Write(w)
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wallet/internal/entity"
+	apiKeyRepository "wallet/internal/repository/apikey"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"wallet/internal/dto"
+	"wallet/internal/interface/http/v1/api/mocks"
+)
+
+func newTestAdminRouter(apiKeys apiKeyPresenter) *mux.Router {
+	router := mux.NewRouter()
+	RegisterAdminRouter(router, apiKeys, new(mocks.WalletAdminPresenter), new(mocks.WorkerAdminPresenter))
+	return router
+}
+
+func TestIssueAPIKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		statusCode int
+	}{
+		{"Valid Request", `{"name":"ci","scopes":["wallet:read"]}`, nil, http.StatusCreated},
+		{"Invalid Body", `{"name":`, nil, http.StatusBadRequest},
+		{"Invalid Scope", `{"name":"ci","scopes":["wallet:root"]}`, entity.ErrInvalidScope, http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAPIKeys := new(mocks.APIKeyPresenter)
+			router := newTestAdminRouter(mockAPIKeys)
+
+			if tt.statusCode != http.StatusBadRequest {
+				var resp *dto.IssueAPIKeyResponse
+				if tt.err == nil {
+					resp = &dto.IssueAPIKeyResponse{APIKeyResponse: dto.APIKeyResponse{ID: "id", Name: "ci"}, Key: "wk_secret"}
+				}
+				mockAPIKeys.On("Issue", mock.Anything, mock.AnythingOfType("*dto.IssueAPIKeyRequest")).Return(resp, tt.err).Once()
+			}
+
+			req := httptest.NewRequest(http.MethodPost, apiKeysPath, bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.statusCode, w.Code)
+			if tt.statusCode == http.StatusCreated {
+				var got dto.IssueAPIKeyResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+				assert.Equal(t, "wk_secret", got.Key)
+			}
+			mockAPIKeys.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListAPIKeys(t *testing.T) {
+	mockAPIKeys := new(mocks.APIKeyPresenter)
+	router := newTestAdminRouter(mockAPIKeys)
+
+	mockAPIKeys.On("List", mock.Anything).Return([]dto.APIKeyResponse{{ID: "id", Name: "ci", Prefix: "wk_0011"}}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, apiKeysPath, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), `"key"`)
+	mockAPIKeys.AssertExpectations(t)
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		statusCode int
+	}{
+		{"Revoked", nil, http.StatusNoContent},
+		{"Not Found", apiKeyRepository.ErrAPIKeyNotFound, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAPIKeys := new(mocks.APIKeyPresenter)
+			router := newTestAdminRouter(mockAPIKeys)
+
+			mockAPIKeys.On("Revoke", mock.Anything, "key-id").Return(tt.err).Once()
+
+			req := httptest.NewRequest(http.MethodDelete, "/api-keys/key-id", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.statusCode, w.Code)
+			mockAPIKeys.AssertExpectations(t)
+		})
+	}
+}
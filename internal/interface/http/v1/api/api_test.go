@@ -58,16 +58,19 @@ func TestPostOperation(t *testing.T) {
 
 			if tt.name == "Valid Request" {
 				// Настройка мока только для валидного запроса
-				mockWallet.On("Transaction", mock.Anything, mock.Anything).Return(nil).Once()
+				mockWallet.On("Transaction", mock.Anything, mock.Anything).Return(&dto.TransactionResponse{IdempotencyKey: "key"}, nil).Once()
 			}
 			if tt.name == "Invalid Wallet UUID" {
 				// Настройка мока только для невалидного запроса
-				mockWallet.On("Transaction", mock.Anything, mock.Anything).Return(presenter.ErrInvalidUUID).Once()
+				mockWallet.On("Transaction", mock.Anything, mock.Anything).Return(nil, presenter.ErrInvalidUUID).Once()
 			}
 
 			router.ServeHTTP(w, req)
 
 			assert.Equal(t, tt.statusCode, w.Code)
+			if tt.err == nil {
+				assert.Equal(t, "key", w.Header().Get("Idempotency-Key"))
+			}
 			mockWallet.AssertExpectations(t) // Проверка, что все ожидания выполнены
 		})
 	}
@@ -122,7 +125,7 @@ func TestCreateWallet(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPost, "/wallet/create", nil)
 	w := httptest.NewRecorder()
 
-	mockWallet.On("NewWallet", mock.Anything).Return(&dto.WalletResponse{UUID: "new-uuid", Amount: 0}, nil).Once()
+	mockWallet.On("NewWallet", mock.Anything, mock.Anything).Return(&dto.WalletResponse{UUID: "new-uuid", Amount: 0}, nil).Once()
 
 	router.ServeHTTP(w, req)
 
@@ -1,8 +1,9 @@
 package api
 
 import (
-	"context"
+	"errors"
 	"github.com/gorilla/mux"
+	"io"
 	"net/http"
 	"wallet/internal/dto"
 	"wallet/internal/interface/response"
@@ -14,10 +15,12 @@ import (
 // @Accept			json
 // @Produce		json
 // @Param			input	body		dto.PostOperationRequest	true	"request"
-// @Success		200		{object}	nil
-// @Failure		400,404	{object}	dto.ErrorResponse
-// @Success		500		{object}	dto.ErrorResponse
-// @Success		default	{object}	dto.ErrorResponse
+// @Success		200		{object}	dto.TransactionResponse
+// @Header			200		{string}	Idempotency-Key	"key to look the operation up with"
+// @Failure		400,401,403,404,409,422	{object}	dto.Problem
+// @Success		500		{object}	dto.Problem
+// @Success		default	{object}	dto.Problem
+// @Security		ApiKeyAuth
 // @Router			/wallet [post]
 func (rt *Router) postOperation(w http.ResponseWriter, r *http.Request) {
 	var req dto.PostOperationRequest
@@ -32,7 +35,8 @@ func (rt *Router) postOperation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := rt.wallet.Transaction(context.TODO(), &req); err != nil {
+	transaction, err := rt.wallet.Transaction(r.Context(), &req)
+	if err != nil {
 		response.
 			Resp().
 			HandleError(err).
@@ -41,7 +45,13 @@ func (rt *Router) postOperation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response.Resp().WithCode(http.StatusOK).Build().Write(w)
+	response.
+		Resp().
+		WithCode(http.StatusOK).
+		WithHeader(idempotencyKeyHeader, transaction.IdempotencyKey).
+		WithPayload(transaction).
+		Build().
+		Write(w)
 }
 
 // @Summary		GetAmount
@@ -51,9 +61,10 @@ func (rt *Router) postOperation(w http.ResponseWriter, r *http.Request) {
 // @Produce		json
 // @Param			uuid	path		string	false	"wallet_uuid"
 // @Success		200		{object}	dto.GetBalanceResponse
-// @Failure		400,404	{object}	dto.ErrorResponse
-// @Success		500		{object}	dto.ErrorResponse
-// @Success		default	{object}	dto.ErrorResponse
+// @Failure		400,401,403,404	{object}	dto.Problem
+// @Success		500		{object}	dto.Problem
+// @Success		default	{object}	dto.Problem
+// @Security		ApiKeyAuth
 // @Router			/wallets/{uuid} [get]
 func (rt *Router) getWalletAmount(w http.ResponseWriter, r *http.Request) {
 	const uuid = "uuid"
@@ -69,7 +80,7 @@ func (rt *Router) getWalletAmount(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	balance, err := rt.wallet.GetBalance(context.TODO(), walletUUID)
+	balance, err := rt.wallet.GetBalance(r.Context(), walletUUID)
 	if err != nil {
 		response.Resp().HandleError(err).Build().Write(w)
 		return
@@ -79,17 +90,31 @@ func (rt *Router) getWalletAmount(w http.ResponseWriter, r *http.Request) {
 }
 
 // @Summary		CreateWallet
-// @Description	create new wallet, returning uuid and amount
+// @Description	create new wallet, returning uuid and amount. End users own the wallets they create, service principals may pass the owner
 // @Tags			wallets
 // @Accept			json
 // @Produce		json
+// @Param			input	body		dto.CreateWalletRequest	false	"request"
 // @Success		200		{object}	dto.WalletResponse
-// @Failure		400,404	{object}	dto.ErrorResponse
-// @Success		500		{object}	dto.ErrorResponse
-// @Success		default	{object}	dto.ErrorResponse
+// @Failure		400,401,403,404	{object}	dto.Problem
+// @Success		500		{object}	dto.Problem
+// @Success		default	{object}	dto.Problem
+// @Security		ApiKeyAuth
 // @Router			/wallet/create [post]
 func (rt *Router) createWallet(w http.ResponseWriter, r *http.Request) {
-	wallet, err := rt.wallet.NewWallet(context.TODO())
+	var req dto.CreateWalletRequest
+
+	if err := getFromBody(r, &req); err != nil && !errors.Is(err, io.EOF) {
+		response.
+			Resp().
+			WithCode(http.StatusBadRequest).
+			WithError(ErrInvalidFormData).
+			Build().
+			Write(w)
+		return
+	}
+
+	wallet, err := rt.wallet.NewWallet(r.Context(), &req)
 	if err != nil {
 		response.Resp().HandleError(err).Build().Write(w)
 		return
@@ -1,8 +1,12 @@
 package api
 
-import "errors"
+import (
+	"errors"
+	"net/http"
+	"wallet/internal/interface/response"
+)
 
 var (
-	ErrEmptyWalletUUID = errors.New("empty wallet uuid")
-	ErrInvalidFormData = errors.New("invalid form data")
+	ErrEmptyWalletUUID = response.Register(errors.New("empty wallet uuid"), http.StatusBadRequest, "WALLET_UUID_REQUIRED")
+	ErrInvalidFormData = response.Register(errors.New("invalid form data"), http.StatusBadRequest, "INVALID_REQUEST_BODY")
 )
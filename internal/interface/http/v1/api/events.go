@@ -0,0 +1,117 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"github.com/gorilla/mux"
+	jsoniter "github.com/json-iterator/go"
+	"io"
+	"net/http"
+	"time"
+	"wallet/internal/dto"
+	"wallet/internal/interface/response"
+)
+
+//go:generate mockery --name eventsPresenter --structname=EventsPresenter
+type eventsPresenter interface {
+	Subscribe(ctx context.Context, uid string, lastEventID string) (<-chan dto.WalletEvent, error)
+}
+
+type EventsRouter struct {
+	events    eventsPresenter
+	router    *mux.Router
+	heartbeat time.Duration
+}
+
+const (
+	walletEventsPath  = "/wallets/{uuid}/events"
+	lastEventIDHeader = "Last-Event-ID"
+)
+
+// RegisterEventsRouter mounts the server-sent events stream. A heartbeat comment is sent every heartbeat
+// to keep idle connections open through proxies.
+func RegisterEventsRouter(
+	router *mux.Router,
+	events eventsPresenter,
+	heartbeat time.Duration,
+) *EventsRouter {
+	rt := &EventsRouter{
+		router:    router,
+		events:    events,
+		heartbeat: heartbeat,
+	}
+
+	rt.router.HandleFunc(walletEventsPath, rt.walletEvents).Methods(http.MethodGet)
+
+	return rt
+}
+
+// @Summary		WalletEvents
+// @Description	server-sent events stream of balance.changed and transaction.status events of the wallet. Send Last-Event-ID to resume after reconnect
+// @Tags			wallets
+// @Produce		text/event-stream
+// @Param			uuid			path		string	true	"wallet_uuid"
+// @Param			Last-Event-ID	header		string	false	"id of the last received event"
+// @Success		200				{object}	dto.WalletEvent
+// @Failure		400,401,403,404	{object}	dto.Problem
+// @Success		500				{object}	dto.Problem
+// @Success		default			{object}	dto.Problem
+// @Security		ApiKeyAuth
+// @Router			/wallets/{uuid}/events [get]
+func (rt *EventsRouter) walletEvents(w http.ResponseWriter, r *http.Request) {
+	const uuid = "uuid"
+
+	events, err := rt.events.Subscribe(r.Context(), mux.Vars(r)[uuid], r.Header.Get(lastEventIDHeader))
+	if err != nil {
+		response.Resp().HandleError(err).Build().Write(w)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	// the stream outlives the write timeout of the server
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(rt.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := writeEvent(w, e); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeEvent(w io.Writer, e dto.WalletEvent) error {
+	data, err := jsoniter.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+	return err
+}
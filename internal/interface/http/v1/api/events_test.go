@@ -0,0 +1,57 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"wallet/internal/dto"
+	"wallet/internal/presenter"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"wallet/internal/interface/http/v1/api/mocks"
+)
+
+func TestWalletEvents(t *testing.T) {
+	router := mux.NewRouter()
+	mockEvents := new(mocks.EventsPresenter)
+	RegisterEventsRouter(router, mockEvents, time.Minute)
+
+	t.Run("Stream", func(t *testing.T) {
+		balance := int64(100)
+		events := make(chan dto.WalletEvent, 1)
+		events <- dto.WalletEvent{ID: "1-0", Type: "balance.changed", WalletId: "valid-uuid", Balance: &balance}
+		close(events)
+
+		mockEvents.On("Subscribe", mock.Anything, "valid-uuid", "0-1").Return((<-chan dto.WalletEvent)(events), nil).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/wallets/valid-uuid/events", nil)
+		req.Header.Set("Last-Event-ID", "0-1")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+		assert.Equal(t,
+			"id: 1-0\nevent: balance.changed\ndata: {\"type\":\"balance.changed\",\"walletId\":\"valid-uuid\",\"balance\":100}\n\n",
+			w.Body.String(),
+		)
+	})
+
+	t.Run("Invalid UUID", func(t *testing.T) {
+		mockEvents.On("Subscribe", mock.Anything, "invalid-uuid", "").Return(nil, presenter.ErrInvalidUUID).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/wallets/invalid-uuid/events", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	mockEvents.AssertExpectations(t)
+}
@@ -0,0 +1,107 @@
+// Code generated by mockery v2.52.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	dto "wallet/internal/dto"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// APIKeyPresenter is an autogenerated mock type for the apiKeyPresenter type
+type APIKeyPresenter struct {
+	mock.Mock
+}
+
+// Issue provides a mock function with given fields: _a0, _a1
+func (_m *APIKeyPresenter) Issue(_a0 context.Context, _a1 *dto.IssueAPIKeyRequest) (*dto.IssueAPIKeyResponse, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Issue")
+	}
+
+	var r0 *dto.IssueAPIKeyResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.IssueAPIKeyRequest) (*dto.IssueAPIKeyResponse, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.IssueAPIKeyRequest) *dto.IssueAPIKeyResponse); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.IssueAPIKeyResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *dto.IssueAPIKeyRequest) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// List provides a mock function with given fields: _a0
+func (_m *APIKeyPresenter) List(_a0 context.Context) ([]dto.APIKeyResponse, error) {
+	ret := _m.Called(_a0)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []dto.APIKeyResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]dto.APIKeyResponse, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []dto.APIKeyResponse); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]dto.APIKeyResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Revoke provides a mock function with given fields: _a0, _a1
+func (_m *APIKeyPresenter) Revoke(_a0 context.Context, _a1 string) error {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Revoke")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewAPIKeyPresenter creates a new instance of APIKeyPresenter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAPIKeyPresenter(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *APIKeyPresenter {
+	mock := &APIKeyPresenter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,59 @@
+// Code generated by mockery v2.52.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	dto "wallet/internal/dto"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// EventsPresenter is an autogenerated mock type for the eventsPresenter type
+type EventsPresenter struct {
+	mock.Mock
+}
+
+// Subscribe provides a mock function with given fields: ctx, uid, lastEventID
+func (_m *EventsPresenter) Subscribe(ctx context.Context, uid string, lastEventID string) (<-chan dto.WalletEvent, error) {
+	ret := _m.Called(ctx, uid, lastEventID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Subscribe")
+	}
+
+	var r0 <-chan dto.WalletEvent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (<-chan dto.WalletEvent, error)); ok {
+		return rf(ctx, uid, lastEventID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) <-chan dto.WalletEvent); ok {
+		r0 = rf(ctx, uid, lastEventID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan dto.WalletEvent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, uid, lastEventID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewEventsPresenter creates a new instance of EventsPresenter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewEventsPresenter(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *EventsPresenter {
+	mock := &EventsPresenter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
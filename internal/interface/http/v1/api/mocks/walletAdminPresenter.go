@@ -0,0 +1,77 @@
+// Code generated by mockery v2.52.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	dto "wallet/internal/dto"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// WalletAdminPresenter is an autogenerated mock type for the walletAdminPresenter type
+type WalletAdminPresenter struct {
+	mock.Mock
+}
+
+// InvalidateBalance provides a mock function with given fields: ctx, uid
+func (_m *WalletAdminPresenter) InvalidateBalance(ctx context.Context, uid string) error {
+	ret := _m.Called(ctx, uid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InvalidateBalance")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, uid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetFrozen provides a mock function with given fields: ctx, uid, frozen
+func (_m *WalletAdminPresenter) SetFrozen(ctx context.Context, uid string, frozen bool) (*dto.WalletResponse, error) {
+	ret := _m.Called(ctx, uid, frozen)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetFrozen")
+	}
+
+	var r0 *dto.WalletResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) (*dto.WalletResponse, error)); ok {
+		return rf(ctx, uid, frozen)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) *dto.WalletResponse); ok {
+		r0 = rf(ctx, uid, frozen)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.WalletResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, bool) error); ok {
+		r1 = rf(ctx, uid, frozen)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewWalletAdminPresenter creates a new instance of WalletAdminPresenter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewWalletAdminPresenter(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *WalletAdminPresenter {
+	mock := &WalletAdminPresenter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -44,9 +44,9 @@ func (_m *WalletPresenter) GetBalance(_a0 context.Context, _a1 string) (*dto.Get
 	return r0, r1
 }
 
-// NewWallet provides a mock function with given fields: ctx
-func (_m *WalletPresenter) NewWallet(ctx context.Context) (*dto.WalletResponse, error) {
-	ret := _m.Called(ctx)
+// NewWallet provides a mock function with given fields: _a0, _a1
+func (_m *WalletPresenter) NewWallet(_a0 context.Context, _a1 *dto.CreateWalletRequest) (*dto.WalletResponse, error) {
+	ret := _m.Called(_a0, _a1)
 
 	if len(ret) == 0 {
 		panic("no return value specified for NewWallet")
@@ -54,19 +54,19 @@ func (_m *WalletPresenter) NewWallet(ctx context.Context) (*dto.WalletResponse,
 
 	var r0 *dto.WalletResponse
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context) (*dto.WalletResponse, error)); ok {
-		return rf(ctx)
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.CreateWalletRequest) (*dto.WalletResponse, error)); ok {
+		return rf(_a0, _a1)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context) *dto.WalletResponse); ok {
-		r0 = rf(ctx)
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.CreateWalletRequest) *dto.WalletResponse); ok {
+		r0 = rf(_a0, _a1)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*dto.WalletResponse)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = rf(ctx)
+	if rf, ok := ret.Get(1).(func(context.Context, *dto.CreateWalletRequest) error); ok {
+		r1 = rf(_a0, _a1)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -75,21 +75,33 @@ func (_m *WalletPresenter) NewWallet(ctx context.Context) (*dto.WalletResponse,
 }
 
 // Transaction provides a mock function with given fields: _a0, _a1
-func (_m *WalletPresenter) Transaction(_a0 context.Context, _a1 *dto.PostOperationRequest) error {
+func (_m *WalletPresenter) Transaction(_a0 context.Context, _a1 *dto.PostOperationRequest) (*dto.TransactionResponse, error) {
 	ret := _m.Called(_a0, _a1)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Transaction")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *dto.PostOperationRequest) error); ok {
+	var r0 *dto.TransactionResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.PostOperationRequest) (*dto.TransactionResponse, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.PostOperationRequest) *dto.TransactionResponse); ok {
 		r0 = rf(_a0, _a1)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.TransactionResponse)
+		}
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, *dto.PostOperationRequest) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // NewWalletPresenter creates a new instance of WalletPresenter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
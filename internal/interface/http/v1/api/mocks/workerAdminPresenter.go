@@ -0,0 +1,89 @@
+// Code generated by mockery v2.52.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	dto "wallet/internal/dto"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// WorkerAdminPresenter is an autogenerated mock type for the workerAdminPresenter type
+type WorkerAdminPresenter struct {
+	mock.Mock
+}
+
+// PauseConsumption provides a mock function with given fields: _a0
+func (_m *WorkerAdminPresenter) PauseConsumption(_a0 context.Context) *dto.WorkerStatusResponse {
+	ret := _m.Called(_a0)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PauseConsumption")
+	}
+
+	var r0 *dto.WorkerStatusResponse
+	if rf, ok := ret.Get(0).(func(context.Context) *dto.WorkerStatusResponse); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.WorkerStatusResponse)
+		}
+	}
+
+	return r0
+}
+
+// ResumeConsumption provides a mock function with given fields: _a0
+func (_m *WorkerAdminPresenter) ResumeConsumption(_a0 context.Context) *dto.WorkerStatusResponse {
+	ret := _m.Called(_a0)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResumeConsumption")
+	}
+
+	var r0 *dto.WorkerStatusResponse
+	if rf, ok := ret.Get(0).(func(context.Context) *dto.WorkerStatusResponse); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.WorkerStatusResponse)
+		}
+	}
+
+	return r0
+}
+
+// WorkerStatus provides a mock function with given fields: _a0
+func (_m *WorkerAdminPresenter) WorkerStatus(_a0 context.Context) *dto.WorkerStatusResponse {
+	ret := _m.Called(_a0)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WorkerStatus")
+	}
+
+	var r0 *dto.WorkerStatusResponse
+	if rf, ok := ret.Get(0).(func(context.Context) *dto.WorkerStatusResponse); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.WorkerStatusResponse)
+		}
+	}
+
+	return r0
+}
+
+// NewWorkerAdminPresenter creates a new instance of WorkerAdminPresenter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewWorkerAdminPresenter(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *WorkerAdminPresenter {
+	mock := &WorkerAdminPresenter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
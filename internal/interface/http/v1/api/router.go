@@ -9,9 +9,9 @@ import (
 
 //go:generate mockery --name walletPresenter --structname=WalletPresenter
 type walletPresenter interface {
-	Transaction(context.Context, *dto.PostOperationRequest) error
+	Transaction(context.Context, *dto.PostOperationRequest) (*dto.TransactionResponse, error)
 	GetBalance(context.Context, string) (*dto.GetBalanceResponse, error)
-	NewWallet(ctx context.Context) (*dto.WalletResponse, error)
+	NewWallet(context.Context, *dto.CreateWalletRequest) (*dto.WalletResponse, error)
 }
 
 type Router struct {
@@ -19,6 +19,8 @@ type Router struct {
 	router *mux.Router
 }
 
+const idempotencyKeyHeader = "Idempotency-Key"
+
 const (
 	postOperationPath   = "/wallet"
 	createWalletPath    = "/wallet/create"
@@ -0,0 +1,204 @@
+package api
+
+import (
+	"context"
+	"github.com/gorilla/mux"
+	"net/http"
+	"wallet/internal/dto"
+	"wallet/internal/interface/response"
+)
+
+//go:generate mockery --name webhookPresenter --structname=WebhookPresenter
+type webhookPresenter interface {
+	Create(context.Context, *dto.CreateWebhookRequest) (*dto.CreateWebhookResponse, error)
+	List(context.Context) ([]dto.WebhookResponse, error)
+	Delete(context.Context, string) error
+	Enable(context.Context, string) error
+	Deliveries(context.Context, string) ([]dto.WebhookDeliveryResponse, error)
+	Redeliver(ctx context.Context, id string, deliveryID string) error
+}
+
+type WebhookRouter struct {
+	webhooks webhookPresenter
+	router   *mux.Router
+}
+
+const (
+	webhooksPath          = "/webhooks"
+	webhookPath           = "/webhooks/{id}"
+	enableWebhookPath     = "/webhooks/{id}/enable"
+	webhookDeliveriesPath = "/webhooks/{id}/deliveries"
+	redeliverWebhookPath  = "/webhooks/{id}/deliveries/{deliveryId}/redeliver"
+)
+
+func RegisterWebhookRouter(
+	router *mux.Router,
+	webhooks webhookPresenter,
+) *WebhookRouter {
+	rt := &WebhookRouter{
+		router:   router,
+		webhooks: webhooks,
+	}
+
+	rt.router.HandleFunc(webhooksPath, rt.createWebhook).Methods(http.MethodPost)
+	rt.router.HandleFunc(webhooksPath, rt.listWebhooks).Methods(http.MethodGet)
+	rt.router.HandleFunc(webhookPath, rt.deleteWebhook).Methods(http.MethodDelete)
+	rt.router.HandleFunc(enableWebhookPath, rt.enableWebhook).Methods(http.MethodPost)
+	rt.router.HandleFunc(webhookDeliveriesPath, rt.listWebhookDeliveries).Methods(http.MethodGet)
+	rt.router.HandleFunc(redeliverWebhookPath, rt.redeliverWebhook).Methods(http.MethodPost)
+
+	return rt
+}
+
+// @Summary		CreateWebhook
+// @Description	subscribe url to transaction.succeeded and transaction.failed events of operations posted by the caller, or of every operation of walletId. The signing secret is returned only once
+// @Tags			webhooks
+// @Accept			json
+// @Produce		json
+// @Param			input	body		dto.CreateWebhookRequest	true	"request"
+// @Success		201		{object}	dto.CreateWebhookResponse
+// @Failure		400,401,403,404,422	{object}	dto.Problem
+// @Success		500		{object}	dto.Problem
+// @Success		default	{object}	dto.Problem
+// @Security		ApiKeyAuth
+// @Router			/webhooks [post]
+func (rt *WebhookRouter) createWebhook(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateWebhookRequest
+
+	if err := getFromBody(r, &req); err != nil {
+		response.
+			Resp().
+			WithCode(http.StatusBadRequest).
+			WithError(ErrInvalidFormData).
+			Build().
+			Write(w)
+		return
+	}
+
+	webhook, err := rt.webhooks.Create(r.Context(), &req)
+	if err != nil {
+		response.Resp().HandleError(err).Build().Write(w)
+		return
+	}
+
+	response.Resp().WithCode(http.StatusCreated).WithPayload(webhook).Build().Write(w)
+}
+
+// @Summary		ListWebhooks
+// @Description	list webhooks of the caller without secrets
+// @Tags			webhooks
+// @Accept			json
+// @Produce		json
+// @Success		200		{array}		dto.WebhookResponse
+// @Failure		401,403	{object}	dto.Problem
+// @Success		500		{object}	dto.Problem
+// @Success		default	{object}	dto.Problem
+// @Security		ApiKeyAuth
+// @Router			/webhooks [get]
+func (rt *WebhookRouter) listWebhooks(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := rt.webhooks.List(r.Context())
+	if err != nil {
+		response.Resp().HandleError(err).Build().Write(w)
+		return
+	}
+
+	response.Resp().WithCode(http.StatusOK).WithPayload(webhooks).Build().Write(w)
+}
+
+// @Summary		DeleteWebhook
+// @Description	delete webhook together with its deliveries
+// @Tags			webhooks
+// @Accept			json
+// @Produce		json
+// @Param			id	path	string	true	"webhook id"
+// @Success		204	{object}	nil
+// @Failure		400,401,403,404	{object}	dto.Problem
+// @Success		500	{object}	dto.Problem
+// @Success		default	{object}	dto.Problem
+// @Security		ApiKeyAuth
+// @Router			/webhooks/{id} [delete]
+func (rt *WebhookRouter) deleteWebhook(w http.ResponseWriter, r *http.Request) {
+	const id = "id"
+
+	if err := rt.webhooks.Delete(r.Context(), mux.Vars(r)[id]); err != nil {
+		response.Resp().HandleError(err).Build().Write(w)
+		return
+	}
+
+	response.Resp().WithCode(http.StatusNoContent).Build().Write(w)
+}
+
+// @Summary		EnableWebhook
+// @Description	enable webhook disabled after failing for too long
+// @Tags			webhooks
+// @Accept			json
+// @Produce		json
+// @Param			id	path	string	true	"webhook id"
+// @Success		204	{object}	nil
+// @Failure		400,401,403,404	{object}	dto.Problem
+// @Success		500	{object}	dto.Problem
+// @Success		default	{object}	dto.Problem
+// @Security		ApiKeyAuth
+// @Router			/webhooks/{id}/enable [post]
+func (rt *WebhookRouter) enableWebhook(w http.ResponseWriter, r *http.Request) {
+	const id = "id"
+
+	if err := rt.webhooks.Enable(r.Context(), mux.Vars(r)[id]); err != nil {
+		response.Resp().HandleError(err).Build().Write(w)
+		return
+	}
+
+	response.Resp().WithCode(http.StatusNoContent).Build().Write(w)
+}
+
+// @Summary		ListWebhookDeliveries
+// @Description	delivery log of the webhook, newest first
+// @Tags			webhooks
+// @Accept			json
+// @Produce		json
+// @Param			id	path	string	true	"webhook id"
+// @Success		200	{array}	dto.WebhookDeliveryResponse
+// @Failure		400,401,403,404	{object}	dto.Problem
+// @Success		500	{object}	dto.Problem
+// @Success		default	{object}	dto.Problem
+// @Security		ApiKeyAuth
+// @Router			/webhooks/{id}/deliveries [get]
+func (rt *WebhookRouter) listWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	const id = "id"
+
+	deliveries, err := rt.webhooks.Deliveries(r.Context(), mux.Vars(r)[id])
+	if err != nil {
+		response.Resp().HandleError(err).Build().Write(w)
+		return
+	}
+
+	response.Resp().WithCode(http.StatusOK).WithPayload(deliveries).Build().Write(w)
+}
+
+// @Summary		RedeliverWebhook
+// @Description	queue delivery again with a fresh retry budget
+// @Tags			webhooks
+// @Accept			json
+// @Produce		json
+// @Param			id			path	string	true	"webhook id"
+// @Param			deliveryId	path	int		true	"delivery id"
+// @Success		202	{object}	nil
+// @Failure		400,401,403,404	{object}	dto.Problem
+// @Success		500	{object}	dto.Problem
+// @Success		default	{object}	dto.Problem
+// @Security		ApiKeyAuth
+// @Router			/webhooks/{id}/deliveries/{deliveryId}/redeliver [post]
+func (rt *WebhookRouter) redeliverWebhook(w http.ResponseWriter, r *http.Request) {
+	const (
+		id         = "id"
+		deliveryID = "deliveryId"
+	)
+
+	vars := mux.Vars(r)
+	if err := rt.webhooks.Redeliver(r.Context(), vars[id], vars[deliveryID]); err != nil {
+		response.Resp().HandleError(err).Build().Write(w)
+		return
+	}
+
+	response.Resp().WithCode(http.StatusAccepted).Build().Write(w)
+}
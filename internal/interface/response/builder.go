@@ -1,17 +1,16 @@
 package response
 
 import (
-	"errors"
+	"log"
 	"net/http"
+	"strings"
 	"wallet/internal/dto"
-	"wallet/internal/entity"
 	"wallet/internal/presenter"
-	walletRepository "wallet/internal/repository/wallet"
-	"wallet/internal/service"
 )
 
 type Builder struct {
 	response Response
+	err      error
 }
 
 func Resp() *Builder {
@@ -49,47 +48,88 @@ func (b *Builder) WithMapHeaders(headers map[string]string) *Builder {
 	return b
 }
 
+// WithError reports err as a problem with the status set by WithCode.
 func (b *Builder) WithError(err error) *Builder {
-	b.response.Payload = dto.ErrorResponse{Message: err.Error()}
+	b.err = err
 	return b
 }
 
+// HandleError reports err as a problem with the status of its registered type.
+// Invalid fields are reported with 422, unknown errors with 500 without details.
 func (b *Builder) HandleError(err error) *Builder {
-	if errors.Is(err, service.ErrInvalidUUID) {
-		return b.WithCode(http.StatusBadRequest).WithError(err)
-	}
-	if errors.Is(err, presenter.ErrInvalidUUID) {
-		return b.WithCode(http.StatusBadRequest).WithError(err)
+	b.err = err
+
+	if len(invalidParams(err)) > 0 {
+		return b.WithCode(http.StatusUnprocessableEntity)
 	}
-	if errors.Is(err, walletRepository.ErrWalletNotFound) {
-		return b.WithCode(http.StatusNotFound).WithError(err)
+	if p, ok := lookup(err); ok {
+		return b.WithCode(p.status)
 	}
 
-	if errors.Is(err, entity.ErrWalletUUIDIsEmpty) {
-		return b.WithCode(http.StatusBadRequest).WithError(err)
+	log.Println("http internal error: ", err)
+	return b.WithCode(http.StatusInternalServerError)
+}
+
+func (b *Builder) Build() *Response {
+	if b.response.Code == 0 {
+		b.response.Code = http.StatusOK
 	}
-	if errors.Is(err, entity.ErrNotEnoughFunds) {
-		return b.WithCode(http.StatusBadRequest).WithError(err)
+	if b.err != nil {
+		b.response.Payload = newProblem(b.response.Code, b.err)
 	}
-	if errors.Is(err, entity.ErrInvalidOperationType) {
-		return b.WithCode(http.StatusBadRequest).WithError(err)
+	return &b.response
+}
+
+func newProblem(status int, err error) *dto.Problem {
+	problem := &dto.Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
 	}
-	if errors.Is(err, entity.ErrInvalidOperationUUID) {
-		return b.WithCode(http.StatusBadRequest).WithError(err)
+
+	if params := invalidParams(err); len(params) > 0 {
+		problem.Code = CodeValidationFailed
+		problem.Detail = "request has invalid fields"
+		problem.InvalidParams = params
+		return problem
 	}
-	if errors.Is(err, entity.ErrInvalidStatus) {
-		return b.WithCode(http.StatusBadRequest).WithError(err)
+
+	if p, ok := lookup(err); ok {
+		problem.Code = p.code
+		problem.Detail = p.detail(err)
+		return problem
 	}
-	if errors.Is(err, entity.ErrAmountIsOrBelowZero) {
-		return b.WithCode(http.StatusBadRequest).WithError(err)
+
+	if status >= http.StatusInternalServerError {
+		problem.Code = CodeInternal
+		problem.Detail = "internal error"
+		return problem
 	}
 
-	return b.WithCode(http.StatusInternalServerError)
+	// an unregistered error with an explicit status
+	problem.Code = strings.ToUpper(strings.ReplaceAll(problem.Title, " ", "_"))
+	problem.Detail = err.Error()
+	return problem
 }
 
-func (b *Builder) Build() *Response {
-	if b.response.Code == 0 {
-		b.response.Code = http.StatusOK
+// invalidParams collects the field errors, err may join or wrap several of them.
+func invalidParams(err error) []dto.InvalidParam {
+	switch e := err.(type) {
+	case *presenter.FieldError:
+		code := CodeValidationFailed
+		if p, ok := lookup(e.Err); ok {
+			code = p.code
+		}
+		return []dto.InvalidParam{{Name: e.Field, Code: code, Reason: e.Err.Error()}}
+	case interface{ Unwrap() []error }:
+		var params []dto.InvalidParam
+		for _, err := range e.Unwrap() {
+			params = append(params, invalidParams(err)...)
+		}
+		return params
+	case interface{ Unwrap() error }:
+		return invalidParams(e.Unwrap())
+	default:
+		return nil
 	}
-	return &b.response
 }
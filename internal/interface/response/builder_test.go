@@ -0,0 +1,74 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wallet/internal/dto"
+	"wallet/internal/entity"
+	"wallet/internal/presenter"
+	walletRepository "wallet/internal/repository/wallet"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeError(t *testing.T, err error) (*httptest.ResponseRecorder, dto.Problem) {
+	w := httptest.NewRecorder()
+	w.Header().Set(RequestIDHeader, "request-1")
+
+	Resp().HandleError(err).Build().Write(w)
+
+	var problem dto.Problem
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&problem))
+	return w, problem
+}
+
+func TestBuilder_HandleError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"not found", walletRepository.ErrWalletNotFound, http.StatusNotFound, "WALLET_NOT_FOUND"},
+		{"wrapped", errors.Join(errors.New("debit"), entity.ErrNotEnoughFunds), http.StatusUnprocessableEntity, "INSUFFICIENT_FUNDS"},
+		{"conflict", entity.ErrWalletFrozen, http.StatusConflict, "WALLET_FROZEN"},
+		{"invalid uuid", presenter.ErrInvalidUUID, http.StatusBadRequest, "INVALID_UUID"},
+		{"unknown", errors.New("pq: connection refused"), http.StatusInternalServerError, CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, problem := writeError(t, tt.err)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.status, problem.Status)
+			assert.Equal(t, tt.code, problem.Code)
+			assert.Equal(t, "request-1", problem.Instance)
+		})
+	}
+
+	// internal errors don't leak to clients
+	_, problem := writeError(t, errors.New("pq: connection refused"))
+	assert.Equal(t, "internal error", problem.Detail)
+}
+
+func TestBuilder_HandleError_InvalidParams(t *testing.T) {
+	err := errors.Join(
+		&presenter.FieldError{Field: "walletId", Err: presenter.ErrInvalidUUID},
+		&presenter.FieldError{Field: "amount", Err: entity.ErrAmountIsOrBelowZero},
+	)
+
+	w, problem := writeError(t, err)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, CodeValidationFailed, problem.Code)
+	assert.Equal(t, []dto.InvalidParam{
+		{Name: "walletId", Code: "INVALID_UUID", Reason: "invalid uuid"},
+		{Name: "amount", Code: "INVALID_AMOUNT", Reason: "amount is or below zero"},
+	}, problem.InvalidParams)
+}
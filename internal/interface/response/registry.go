@@ -0,0 +1,86 @@
+package response
+
+import (
+	"errors"
+	"net/http"
+	"wallet/internal/entity"
+	"wallet/internal/infrastructure/auth/jwt"
+	"wallet/internal/presenter"
+	apiKeyRepository "wallet/internal/repository/apikey"
+	transactionRepository "wallet/internal/repository/transaction"
+	walletRepository "wallet/internal/repository/wallet"
+	webhookRepository "wallet/internal/repository/webhook"
+	"wallet/internal/service"
+	"wallet/internal/utils/ratelimit"
+)
+
+const (
+	CodeInternal         = "INTERNAL"
+	CodeValidationFailed = "VALIDATION_FAILED"
+)
+
+// problemType is how an error is reported to clients.
+type problemType struct {
+	err    error
+	status int
+	code   string
+	// opaque errors don't expose what they wrap, e.g. why a token was rejected.
+	opaque bool
+}
+
+// registry maps errors to problem types, the first entry the error matches wins.
+// Codes are part of the api: add new ones, don't rename.
+var registry = []problemType{
+	{err: presenter.ErrInvalidUUID, status: http.StatusBadRequest, code: "INVALID_UUID"},
+	{err: service.ErrInvalidUUID, status: http.StatusBadRequest, code: "INVALID_UUID"},
+	{err: presenter.ErrInvalidDeliveryID, status: http.StatusBadRequest, code: "INVALID_DELIVERY_ID"},
+	{err: entity.ErrWalletUUIDIsEmpty, status: http.StatusBadRequest, code: "WALLET_UUID_REQUIRED"},
+	{err: entity.ErrInvalidOperationUUID, status: http.StatusBadRequest, code: "INVALID_OPERATION_UUID"},
+
+	{err: walletRepository.ErrWalletNotFound, status: http.StatusNotFound, code: "WALLET_NOT_FOUND"},
+	{err: transactionRepository.ErrTransactionNotFound, status: http.StatusNotFound, code: "TRANSACTION_NOT_FOUND"},
+	{err: apiKeyRepository.ErrAPIKeyNotFound, status: http.StatusNotFound, code: "API_KEY_NOT_FOUND"},
+	{err: webhookRepository.ErrWebhookNotFound, status: http.StatusNotFound, code: "WEBHOOK_NOT_FOUND"},
+	{err: webhookRepository.ErrDeliveryNotFound, status: http.StatusNotFound, code: "DELIVERY_NOT_FOUND"},
+
+	{err: service.ErrUnauthenticated, status: http.StatusUnauthorized, code: "UNAUTHENTICATED"},
+	{err: jwt.ErrInvalidToken, status: http.StatusUnauthorized, code: "INVALID_TOKEN", opaque: true},
+	{err: service.ErrForbidden, status: http.StatusForbidden, code: "FORBIDDEN"},
+
+	{err: ratelimit.ErrLimitExceeded, status: http.StatusTooManyRequests, code: "RATE_LIMITED"},
+
+	{err: entity.ErrWalletFrozen, status: http.StatusConflict, code: "WALLET_FROZEN"},
+	{err: transactionRepository.ErrDuplicateTransaction, status: http.StatusConflict, code: "DUPLICATE_TRANSACTION"},
+	{err: walletRepository.ErrNoRowsAffected, status: http.StatusConflict, code: "CONCURRENT_UPDATE"},
+
+	{err: entity.ErrNotEnoughFunds, status: http.StatusUnprocessableEntity, code: "INSUFFICIENT_FUNDS"},
+	{err: entity.ErrInvalidOperationType, status: http.StatusUnprocessableEntity, code: "INVALID_OPERATION_TYPE"},
+	{err: entity.ErrAmountIsOrBelowZero, status: http.StatusUnprocessableEntity, code: "INVALID_AMOUNT"},
+	{err: entity.ErrInvalidStatus, status: http.StatusUnprocessableEntity, code: "INVALID_STATUS"},
+	{err: entity.ErrInvalidScope, status: http.StatusUnprocessableEntity, code: "INVALID_SCOPE"},
+	{err: entity.ErrAPIKeyNameIsEmpty, status: http.StatusUnprocessableEntity, code: "API_KEY_NAME_REQUIRED"},
+	{err: entity.ErrInvalidWebhookURL, status: http.StatusUnprocessableEntity, code: "INVALID_WEBHOOK_URL"},
+}
+
+// Register adds a problem type for errors of packages the registry can't import, e.g. the handlers.
+// It returns err, so errors are registered where they are declared.
+func Register(err error, status int, code string) error {
+	registry = append(registry, problemType{err: err, status: status, code: code})
+	return err
+}
+
+func lookup(err error) (problemType, bool) {
+	for _, p := range registry {
+		if errors.Is(err, p.err) {
+			return p, true
+		}
+	}
+	return problemType{}, false
+}
+
+func (p problemType) detail(err error) string {
+	if p.opaque {
+		return p.err.Error()
+	}
+	return err.Error()
+}
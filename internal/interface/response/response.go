@@ -6,8 +6,12 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"wallet/internal/dto"
 )
 
+// RequestIDHeader is set on the response by the request id middleware, problems use it as the instance.
+const RequestIDHeader = "X-Request-ID"
+
 type Response struct {
 	Code    int
 	Payload any
@@ -27,6 +31,10 @@ func (b *Response) Write(w http.ResponseWriter) {
 	var payload []byte
 	var err error
 
+	if problem, ok := b.Payload.(*dto.Problem); ok && problem.Instance == "" {
+		problem.Instance = w.Header().Get(RequestIDHeader)
+	}
+
 	if b.Payload != nil {
 		payload, err = b.preparePayload()
 		if err != nil {
@@ -53,7 +61,11 @@ func (b *Response) preparePayload() ([]byte, error) {
 		return []byte{}, err
 	}
 
-	b.Headers.Set("Content-Type", "application/json")
+	if _, ok := b.Payload.(*dto.Problem); ok {
+		b.Headers.Set("Content-Type", "application/problem+json")
+	} else {
+		b.Headers.Set("Content-Type", "application/json")
+	}
 	b.Headers.Set("Content-Length", strconv.Itoa(len(jsoned)))
 
 	return jsoned, nil
@@ -0,0 +1,77 @@
+package presenter
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"wallet/internal/dto"
+	"wallet/internal/entity"
+)
+
+type apiKeyService interface {
+	Issue(ctx context.Context, name string, scopes []string) (*entity.APIKey, string, error)
+	List(ctx context.Context) ([]*entity.APIKey, error)
+	Revoke(ctx context.Context, id uuid.UUID) error
+}
+
+type APIKeyPresenter struct {
+	apiKeyService apiKeyService
+}
+
+func NewAPIKeyPresenter(apiKeyService apiKeyService) *APIKeyPresenter {
+	return &APIKeyPresenter{
+		apiKeyService: apiKeyService,
+	}
+}
+
+func (p *APIKeyPresenter) Issue(ctx context.Context, req *dto.IssueAPIKeyRequest) (*dto.IssueAPIKeyResponse, error) {
+	key, raw, err := p.apiKeyService.Issue(ctx, req.Name, req.Scopes)
+	switch {
+	case errors.Is(err, entity.ErrAPIKeyNameIsEmpty):
+		return nil, invalidField("name", err)
+	case errors.Is(err, entity.ErrInvalidScope):
+		return nil, invalidField("scopes", err)
+	case err != nil:
+		return nil, err
+	}
+
+	return &dto.IssueAPIKeyResponse{APIKeyResponse: toAPIKeyResponse(key), Key: raw}, nil
+}
+
+func (p *APIKeyPresenter) List(ctx context.Context) ([]dto.APIKeyResponse, error) {
+	keys, err := p.apiKeyService.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dto.APIKeyResponse, 0, len(keys))
+	for _, k := range keys {
+		res = append(res, toAPIKeyResponse(k))
+	}
+	return res, nil
+}
+
+func (p *APIKeyPresenter) Revoke(ctx context.Context, id string) error {
+	keyID, err := uuid.Parse(id)
+	if err != nil || keyID == uuid.Nil {
+		return ErrInvalidUUID
+	}
+
+	return p.apiKeyService.Revoke(ctx, keyID)
+}
+
+func toAPIKeyResponse(k *entity.APIKey) dto.APIKeyResponse {
+	scopes := make([]string, 0, len(k.Scopes))
+	for _, s := range k.Scopes {
+		scopes = append(scopes, string(s))
+	}
+
+	return dto.APIKeyResponse{
+		ID:        k.ID.String(),
+		Name:      k.Name,
+		Prefix:    k.Prefix,
+		Scopes:    scopes,
+		CreatedAt: k.CreatedAt,
+		RevokedAt: k.RevokedAt,
+	}
+}
@@ -2,4 +2,26 @@ package presenter
 
 import "errors"
 
-var ErrInvalidUUID = errors.New("invalid uuid")
+var (
+	ErrInvalidUUID       = errors.New("invalid uuid")
+	ErrInvalidDeliveryID = errors.New("invalid delivery id")
+)
+
+// FieldError reports an invalid field of the request body. Several of them are joined with errors.Join.
+type FieldError struct {
+	// Field is the json name of the field.
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+func invalidField(field string, err error) error {
+	return &FieldError{Field: field, Err: err}
+}
@@ -2,44 +2,88 @@ package presenter
 
 import (
 	"context"
+	"errors"
 	"github.com/google/uuid"
+	"strings"
 	"wallet/internal/dto"
 	"wallet/internal/entity"
+	"wallet/internal/service"
 )
 
 type walletService interface {
 	NewTransaction(ctx context.Context, operation *entity.Transaction) error
 
-	NewWallet(ctx context.Context) (*entity.Wallet, error)
+	NewWallet(ctx context.Context, owner string) (*entity.Wallet, error)
 	GetBalance(context.Context, uuid.UUID) (int64, error)
+	GetOwner(context.Context, uuid.UUID) (string, error)
+	GetTransaction(context.Context, uuid.UUID) (*entity.Transaction, error)
+	SetFrozen(ctx context.Context, uid uuid.UUID, frozen bool) (*entity.Wallet, error)
+	InvalidateBalance(context.Context, uuid.UUID) error
+	PauseConsumption() service.WorkerStatus
+	ResumeConsumption() service.WorkerStatus
+	WorkerStatus() service.WorkerStatus
+}
+
+type eventHub interface {
+	Subscribe(ctx context.Context, walletUUID uuid.UUID, lastEventID string) (<-chan *entity.WalletEvent, error)
 }
 
 type Presenter struct {
 	walletService walletService
+	events        eventHub
 }
 
-func NewPresenter(walletService walletService) *Presenter {
+func NewPresenter(walletService walletService, events eventHub) *Presenter {
 	return &Presenter{
 		walletService: walletService,
+		events:        events,
 	}
 }
 
-func (p *Presenter) Transaction(ctx context.Context, req *dto.PostOperationRequest) error {
-	walletUUID, err := uuid.Parse(req.WalletId)
-	if err != nil {
-		return ErrInvalidUUID
+func (p *Presenter) Transaction(ctx context.Context, req *dto.PostOperationRequest) (*dto.TransactionResponse, error) {
+	if err := validateOperation(req); err != nil {
+		return nil, err
 	}
 
+	walletUUID := uuid.MustParse(req.WalletId)
 	operation, err := entity.NewOperation(walletUUID, req.OperationType, req.Amount)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if err := p.authorize(ctx, walletUUID); err != nil {
+		return nil, err
+	}
+
+	if principal, ok := entity.PrincipalFromContext(ctx); ok {
+		operation.Initiator = principal.Subject
+	} else if client, ok := entity.ClientIdentityFromContext(ctx); ok {
+		operation.Initiator = client.Subject
 	}
 
 	if err := p.walletService.NewTransaction(ctx, operation); err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return toTransactionResponse(operation), nil
+}
+
+func (p *Presenter) GetTransaction(ctx context.Context, key string) (*dto.TransactionResponse, error) {
+	idempotencyKey, err := uuid.Parse(key)
+	if err != nil || idempotencyKey == uuid.Nil {
+		return nil, ErrInvalidUUID
+	}
+
+	t, err := p.walletService.GetTransaction(ctx, idempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.authorize(ctx, t.WalletUUID); err != nil {
+		return nil, err
+	}
+
+	return toTransactionResponse(t), nil
 }
 
 func (p *Presenter) GetBalance(ctx context.Context, uid string) (*dto.GetBalanceResponse, error) {
@@ -48,6 +92,10 @@ func (p *Presenter) GetBalance(ctx context.Context, uid string) (*dto.GetBalance
 		return nil, ErrInvalidUUID
 	}
 
+	if err := p.authorize(ctx, walletUUID); err != nil {
+		return nil, err
+	}
+
 	balance, err := p.walletService.GetBalance(ctx, walletUUID)
 	if err != nil {
 		return nil, err
@@ -56,10 +104,186 @@ func (p *Presenter) GetBalance(ctx context.Context, uid string) (*dto.GetBalance
 	return &dto.GetBalanceResponse{Amount: balance}, nil
 }
 
-func (p *Presenter) NewWallet(ctx context.Context) (*dto.WalletResponse, error) {
-	wallet, err := p.walletService.NewWallet(ctx)
+func (p *Presenter) NewWallet(ctx context.Context, req *dto.CreateWalletRequest) (*dto.WalletResponse, error) {
+	owner := req.Owner
+
+	// end users always own the wallets they create, services may create wallets on behalf of a user
+	if principal, ok := entity.PrincipalFromContext(ctx); ok && !principal.Service {
+		if owner != "" && owner != principal.Subject {
+			return nil, service.ErrForbidden
+		}
+		owner = principal.Subject
+	}
+
+	wallet, err := p.walletService.NewWallet(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	return toWalletResponse(wallet), nil
+}
+
+// SetFrozen freezes or unfreezes the wallet. It is served on the admin api only.
+func (p *Presenter) SetFrozen(ctx context.Context, uid string, frozen bool) (*dto.WalletResponse, error) {
+	walletUUID, err := uuid.Parse(uid)
+	if err != nil || walletUUID == uuid.Nil {
+		return nil, ErrInvalidUUID
+	}
+
+	wallet, err := p.walletService.SetFrozen(ctx, walletUUID, frozen)
+	if err != nil {
+		return nil, err
+	}
+	return toWalletResponse(wallet), nil
+}
+
+// Subscribe streams balance and transaction status changes of the wallet, see service.EventHub.Subscribe.
+func (p *Presenter) Subscribe(ctx context.Context, uid string, lastEventID string) (<-chan dto.WalletEvent, error) {
+	walletUUID, err := uuid.Parse(uid)
+	if err != nil || walletUUID == uuid.Nil {
+		return nil, ErrInvalidUUID
+	}
+
+	if err := p.authorize(ctx, walletUUID); err != nil {
+		return nil, err
+	}
+
+	events, err := p.events.Subscribe(ctx, walletUUID, lastEventID)
 	if err != nil {
 		return nil, err
 	}
-	return &dto.WalletResponse{UUID: wallet.UUID.String(), Amount: wallet.Amount}, nil
+
+	out := make(chan dto.WalletEvent)
+	go func() {
+		defer close(out)
+		for e := range events {
+			select {
+			case out <- toWalletEvent(e):
+			case <-ctx.Done():
+				// drain, so the hub can release the subscription
+				for range events {
+				}
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// authorize checks that the caller may access the wallet.
+func (p *Presenter) authorize(ctx context.Context, walletUUID uuid.UUID) error {
+	return authorizeWallet(ctx, p.walletService, walletUUID)
+}
+
+type walletOwners interface {
+	GetOwner(context.Context, uuid.UUID) (string, error)
+}
+
+// authorizeWallet checks that the caller may access the wallet.
+// Requests without principal are served only when authentication is disabled.
+func authorizeWallet(ctx context.Context, owners walletOwners, walletUUID uuid.UUID) error {
+	principal, ok := entity.PrincipalFromContext(ctx)
+	if !ok || principal.Service {
+		return nil
+	}
+
+	owner, err := owners.GetOwner(ctx, walletUUID)
+	if err != nil {
+		return err
+	}
+
+	if !principal.CanAccess(owner) {
+		return service.ErrForbidden
+	}
+
+	return nil
+}
+
+// InvalidateBalance drops the cached balance of the wallet. It is served on the admin api only.
+func (p *Presenter) InvalidateBalance(ctx context.Context, uid string) error {
+	walletUUID, err := uuid.Parse(uid)
+	if err != nil || walletUUID == uuid.Nil {
+		return ErrInvalidUUID
+	}
+
+	return p.walletService.InvalidateBalance(ctx, walletUUID)
+}
+
+func toWalletResponse(w *entity.Wallet) *dto.WalletResponse {
+	return &dto.WalletResponse{
+		UUID:   w.UUID.String(),
+		Owner:  w.Owner,
+		Amount: w.Amount,
+		Frozen: w.Frozen,
+	}
+}
+
+func toTransactionResponse(t *entity.Transaction) *dto.TransactionResponse {
+	return &dto.TransactionResponse{
+		IdempotencyKey: t.IdempotencyKey.String(),
+		WalletId:       t.WalletUUID.String(),
+		OperationType:  string(t.Operation),
+		Amount:         t.Amount,
+		Status:         string(t.Status),
+		CreatedAt:      t.CreatedAt,
+		UpdatedAt:      t.UpdatedAt,
+	}
+}
+
+func toWalletEvent(e *entity.WalletEvent) dto.WalletEvent {
+	event := dto.WalletEvent{
+		ID:       e.ID,
+		Type:     string(e.Type),
+		WalletId: e.WalletUUID.String(),
+	}
+
+	switch e.Type {
+	case entity.BalanceChanged:
+		balance := e.Balance
+		event.Balance = &balance
+	case entity.TransactionStatusChanged:
+		event.IdempotencyKey = e.IdempotencyKey.String()
+		event.Status = string(e.Status)
+	}
+
+	return event
+}
+
+// PauseConsumption stops workers from taking new transactions. It is served on the admin api only.
+func (p *Presenter) PauseConsumption(_ context.Context) *dto.WorkerStatusResponse {
+	return toWorkerStatusResponse(p.walletService.PauseConsumption())
+}
+
+// ResumeConsumption lets paused workers take transactions again. It is served on the admin api only.
+func (p *Presenter) ResumeConsumption(_ context.Context) *dto.WorkerStatusResponse {
+	return toWorkerStatusResponse(p.walletService.ResumeConsumption())
+}
+
+func (p *Presenter) WorkerStatus(_ context.Context) *dto.WorkerStatusResponse {
+	return toWorkerStatusResponse(p.walletService.WorkerStatus())
+}
+
+func toWorkerStatusResponse(status service.WorkerStatus) *dto.WorkerStatusResponse {
+	return &dto.WorkerStatusResponse{
+		Paused: status.Paused,
+		Active: status.Active,
+		Busy:   status.Busy,
+	}
+}
+
+// validateOperation reports every invalid field of the request, not only the first one.
+func validateOperation(req *dto.PostOperationRequest) error {
+	var errs []error
+	if walletUUID, err := uuid.Parse(req.WalletId); err != nil {
+		errs = append(errs, invalidField("walletId", ErrInvalidUUID))
+	} else if walletUUID == uuid.Nil {
+		errs = append(errs, invalidField("walletId", entity.ErrWalletUUIDIsEmpty))
+	}
+	if !entity.OperationType(strings.ToLower(req.OperationType)).IsValid() {
+		errs = append(errs, invalidField("operationType", entity.ErrInvalidOperationType))
+	}
+	if req.Amount <= 0 {
+		errs = append(errs, invalidField("amount", entity.ErrAmountIsOrBelowZero))
+	}
+	return errors.Join(errs...)
 }
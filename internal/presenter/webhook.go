@@ -0,0 +1,185 @@
+package presenter
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"strconv"
+	"wallet/internal/dto"
+	"wallet/internal/entity"
+	"wallet/internal/service"
+)
+
+type webhookService interface {
+	Create(ctx context.Context, subject string, walletUUID *uuid.UUID, url string) (*entity.Webhook, error)
+	Get(ctx context.Context, id uuid.UUID) (*entity.Webhook, error)
+	List(ctx context.Context, subject string) ([]*entity.Webhook, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	Enable(ctx context.Context, id uuid.UUID) error
+	Deliveries(ctx context.Context, id uuid.UUID) ([]*entity.WebhookDelivery, error)
+	Redeliver(ctx context.Context, id uuid.UUID, deliveryID int64) error
+}
+
+type WebhookPresenter struct {
+	webhookService webhookService
+	walletOwners   walletOwners
+}
+
+func NewWebhookPresenter(webhookService webhookService, walletOwners walletOwners) *WebhookPresenter {
+	return &WebhookPresenter{
+		webhookService: webhookService,
+		walletOwners:   walletOwners,
+	}
+}
+
+// Create subscribes the caller to outcomes of its transactions, or of every transaction of the wallet when set.
+func (p *WebhookPresenter) Create(ctx context.Context, req *dto.CreateWebhookRequest) (*dto.CreateWebhookResponse, error) {
+	var walletUUID *uuid.UUID
+	if req.WalletId != "" {
+		parsed, err := uuid.Parse(req.WalletId)
+		if err != nil || parsed == uuid.Nil {
+			return nil, invalidField("walletId", ErrInvalidUUID)
+		}
+		if err := authorizeWallet(ctx, p.walletOwners, parsed); err != nil {
+			return nil, err
+		}
+		walletUUID = &parsed
+	}
+
+	var subject string
+	if principal, ok := entity.PrincipalFromContext(ctx); ok {
+		subject = principal.Subject
+	}
+
+	webhook, err := p.webhookService.Create(ctx, subject, walletUUID, req.URL)
+	if errors.Is(err, entity.ErrInvalidWebhookURL) {
+		return nil, invalidField("url", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.CreateWebhookResponse{WebhookResponse: toWebhookResponse(webhook), Secret: webhook.Secret}, nil
+}
+
+// List returns webhooks of the caller. Admins and unauthenticated deployments see every webhook.
+func (p *WebhookPresenter) List(ctx context.Context) ([]dto.WebhookResponse, error) {
+	var subject string
+	if principal, ok := entity.PrincipalFromContext(ctx); ok && !principal.HasScope(entity.ScopeWalletAdmin) {
+		subject = principal.Subject
+	}
+
+	webhooks, err := p.webhookService.List(ctx, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dto.WebhookResponse, 0, len(webhooks))
+	for _, w := range webhooks {
+		res = append(res, toWebhookResponse(w))
+	}
+	return res, nil
+}
+
+func (p *WebhookPresenter) Delete(ctx context.Context, id string) error {
+	webhookID, err := p.authorize(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return p.webhookService.Delete(ctx, webhookID)
+}
+
+func (p *WebhookPresenter) Enable(ctx context.Context, id string) error {
+	webhookID, err := p.authorize(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return p.webhookService.Enable(ctx, webhookID)
+}
+
+func (p *WebhookPresenter) Deliveries(ctx context.Context, id string) ([]dto.WebhookDeliveryResponse, error) {
+	webhookID, err := p.authorize(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries, err := p.webhookService.Deliveries(ctx, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dto.WebhookDeliveryResponse, 0, len(deliveries))
+	for _, d := range deliveries {
+		res = append(res, toWebhookDeliveryResponse(d))
+	}
+	return res, nil
+}
+
+func (p *WebhookPresenter) Redeliver(ctx context.Context, id string, deliveryID string) error {
+	webhookID, err := p.authorize(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	parsed, err := strconv.ParseInt(deliveryID, 10, 64)
+	if err != nil {
+		return ErrInvalidDeliveryID
+	}
+
+	return p.webhookService.Redeliver(ctx, webhookID, parsed)
+}
+
+// authorize checks that the webhook belongs to the caller.
+func (p *WebhookPresenter) authorize(ctx context.Context, id string) (uuid.UUID, error) {
+	webhookID, err := uuid.Parse(id)
+	if err != nil || webhookID == uuid.Nil {
+		return uuid.Nil, ErrInvalidUUID
+	}
+
+	principal, ok := entity.PrincipalFromContext(ctx)
+	if !ok || principal.HasScope(entity.ScopeWalletAdmin) {
+		return webhookID, nil
+	}
+
+	webhook, err := p.webhookService.Get(ctx, webhookID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if webhook.Subject != principal.Subject {
+		return uuid.Nil, service.ErrForbidden
+	}
+
+	return webhookID, nil
+}
+
+func toWebhookResponse(w *entity.Webhook) dto.WebhookResponse {
+	res := dto.WebhookResponse{
+		ID:           w.ID.String(),
+		URL:          w.URL,
+		Enabled:      w.Enabled,
+		FailingSince: w.FailingSince,
+		DisabledAt:   w.DisabledAt,
+		CreatedAt:    w.CreatedAt,
+	}
+	if w.WalletUUID != nil {
+		res.WalletId = w.WalletUUID.String()
+	}
+	return res
+}
+
+func toWebhookDeliveryResponse(d *entity.WebhookDelivery) dto.WebhookDeliveryResponse {
+	return dto.WebhookDeliveryResponse{
+		ID:             d.ID,
+		EventID:        d.EventID.String(),
+		EventType:      string(d.EventType),
+		Status:         string(d.Status),
+		Attempts:       d.Attempts,
+		NextAttemptAt:  d.NextAttemptAt,
+		LastStatusCode: d.LastStatusCode,
+		LastError:      d.LastError,
+		CreatedAt:      d.CreatedAt,
+		DeliveredAt:    d.DeliveredAt,
+	}
+}
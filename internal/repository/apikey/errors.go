@@ -0,0 +1,7 @@
+package apikey
+
+import "errors"
+
+var (
+	ErrAPIKeyNotFound = errors.New("api key not found")
+)
@@ -0,0 +1,170 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"time"
+	"wallet/internal/entity"
+	"wallet/internal/utils/metrics"
+)
+
+type Repository struct {
+}
+
+func New() *Repository {
+	return &Repository{}
+}
+
+const (
+	insertAPIKeyFn    = "insert api key"
+	getAPIKeyByHashFn = "get api key by hash"
+	listAPIKeysFn     = "list api keys"
+	revokeAPIKeyFn    = "revoke api key"
+)
+
+var apiKeyColumns = []string{
+	"id",
+	"name",
+	"prefix",
+	"key_hash",
+	"scopes",
+	"created_at",
+	"revoked_at",
+}
+
+func (r Repository) Insert(ctx context.Context, tx pgx.Tx, k *entity.APIKey) error {
+	stmt, args, err := sq.
+		Insert("api_keys").
+		Columns(
+			"name",
+			"prefix",
+			"key_hash",
+			"scopes",
+			"created_at",
+		).
+		Values(
+			k.Name,
+			k.Prefix,
+			k.Hash,
+			scopesToStrings(k.Scopes),
+			k.CreatedAt,
+		).
+		Suffix("RETURNING \"id\"").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	if err := metrics.Tx().QueryRow(insertAPIKeyFn, ctx, tx, stmt, args...).Scan(&k.ID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r Repository) GetByHash(ctx context.Context, tx pgx.Tx, hash string) (*entity.APIKey, error) {
+	stmt, args, err := sq.Select(apiKeyColumns...).
+		From("api_keys").
+		Where(sq.Eq{"key_hash": hash}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	k, err := scanAPIKey(metrics.Tx().QueryRow(getAPIKeyByHashFn, ctx, tx, stmt, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+
+	return k, nil
+}
+
+func (r Repository) List(ctx context.Context, tx pgx.Tx) ([]*entity.APIKey, error) {
+	stmt, args, err := sq.Select(apiKeyColumns...).
+		From("api_keys").
+		OrderBy("created_at").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := metrics.Tx().Query(listAPIKeysFn, ctx, tx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]*entity.APIKey, 0)
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+
+	return keys, rows.Err()
+}
+
+func (r Repository) Revoke(ctx context.Context, tx pgx.Tx, id uuid.UUID) error {
+	stmt, args, err := sq.Update("api_keys").
+		Set("revoked_at", time.Now()).
+		Where(sq.Eq{"id": id}).
+		Where(sq.Eq{"revoked_at": nil}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	res, err := metrics.Tx().Exec(revokeAPIKeyFn, ctx, tx, stmt, args...)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+func scanAPIKey(row pgx.Row) (*entity.APIKey, error) {
+	k := new(entity.APIKey)
+	var scopes []string
+
+	if err := row.Scan(
+		&k.ID,
+		&k.Name,
+		&k.Prefix,
+		&k.Hash,
+		&scopes,
+		&k.CreatedAt,
+		&k.RevokedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	k.Scopes = make([]entity.Scope, 0, len(scopes))
+	for _, s := range scopes {
+		k.Scopes = append(k.Scopes, entity.Scope(s))
+	}
+
+	return k, nil
+}
+
+func scopesToStrings(scopes []entity.Scope) []string {
+	res := make([]string, 0, len(scopes))
+	for _, s := range scopes {
+		res = append(res, string(s))
+	}
+	return res
+}
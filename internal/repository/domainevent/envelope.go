@@ -0,0 +1,79 @@
+package domainevent
+
+import (
+	"time"
+	"wallet/internal/entity"
+)
+
+const (
+	specVersion = "1.0"
+	// schemaVersion is appended to the event type. It is bumped on incompatible changes of the data,
+	// consumers keep reading the old type until they migrate.
+	schemaVersion = "v1"
+
+	contentTypeHeader   = "content-type"
+	envelopeContentType = "application/cloudevents+json; charset=UTF-8"
+	dataContentType     = "application/json"
+)
+
+// cloudEvent is the CloudEvents 1.0 envelope in structured mode.
+type cloudEvent struct {
+	SpecVersion     string    `json:"specversion"`
+	ID              string    `json:"id"`
+	Source          string    `json:"source"`
+	Type            string    `json:"type"`
+	Subject         string    `json:"subject"`
+	Time            time.Time `json:"time"`
+	DataContentType string    `json:"datacontenttype"`
+	Data            eventData `json:"data"`
+}
+
+type eventData struct {
+	WalletId  string         `json:"walletId"`
+	Owner     string         `json:"owner,omitempty"`
+	Balance   int64          `json:"balance"`
+	Version   int64          `json:"version"`
+	Frozen    bool           `json:"frozen"`
+	Operation *operationData `json:"operation,omitempty"`
+	Reason    string         `json:"reason,omitempty"`
+}
+
+type operationData struct {
+	IdempotencyKey string `json:"idempotencyKey"`
+	OperationType  string `json:"operationType"`
+	Amount         int64  `json:"amount"`
+	Status         string `json:"status"`
+	Initiator      string `json:"initiator,omitempty"`
+}
+
+func newCloudEvent(source string, e *entity.DomainEvent) cloudEvent {
+	ce := cloudEvent{
+		SpecVersion:     specVersion,
+		ID:              e.ID.String(),
+		Source:          source,
+		Type:            string(e.Type) + "." + schemaVersion,
+		Subject:         e.WalletUUID.String(),
+		Time:            e.OccurredAt.UTC(),
+		DataContentType: dataContentType,
+		Data: eventData{
+			WalletId: e.WalletUUID.String(),
+			Owner:    e.Owner,
+			Balance:  e.Balance,
+			Version:  e.Version,
+			Frozen:   e.Frozen,
+			Reason:   e.Reason,
+		},
+	}
+
+	if t := e.Operation; t != nil {
+		ce.Data.Operation = &operationData{
+			IdempotencyKey: t.IdempotencyKey.String(),
+			OperationType:  string(t.Operation),
+			Amount:         t.Amount,
+			Status:         string(t.Status),
+			Initiator:      t.Initiator,
+		}
+	}
+
+	return ce
+}
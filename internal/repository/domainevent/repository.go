@@ -0,0 +1,44 @@
+package domainevent
+
+import (
+	"context"
+	jsoniter "github.com/json-iterator/go"
+	"wallet/internal/entity"
+	"wallet/internal/infrastructure/broker"
+)
+
+type publisher interface {
+	Publish(context.Context, broker.Message) error
+}
+
+type Config struct {
+	// Source is the CloudEvents source attribute identifying this service.
+	Source string
+}
+
+// Repository publishes domain events as CloudEvents keyed by wallet uuid,
+// so events of one wallet keep their order in a partition.
+type Repository struct {
+	publisher publisher
+	cfg       Config
+}
+
+func New(publisher publisher, cfg Config) *Repository {
+	return &Repository{
+		publisher: publisher,
+		cfg:       cfg,
+	}
+}
+
+func (r Repository) Publish(ctx context.Context, e *entity.DomainEvent) error {
+	data, err := jsoniter.Marshal(newCloudEvent(r.cfg.Source, e))
+	if err != nil {
+		return err
+	}
+
+	return r.publisher.Publish(ctx, broker.Message{
+		Key:     []byte(e.WalletUUID.String()),
+		Value:   data,
+		Headers: map[string]string{contentTypeHeader: envelopeContentType},
+	})
+}
@@ -0,0 +1,72 @@
+package domainevent
+
+import (
+	"context"
+	"testing"
+	"time"
+	"wallet/internal/entity"
+	"wallet/internal/infrastructure/broker"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type publisherFunc func(context.Context, broker.Message) error
+
+func (f publisherFunc) Publish(ctx context.Context, msg broker.Message) error {
+	return f(ctx, msg)
+}
+
+func TestRepository_Publish(t *testing.T) {
+	wallet := &entity.Wallet{
+		UUID:    uuid.MustParse("0b6a8f4e-3c1d-4f6e-9a51-7d2c8e9f0a11"),
+		Owner:   "user-1",
+		Amount:  150,
+		Version: 3,
+	}
+	tr := &entity.Transaction{
+		WalletUUID:     wallet.UUID,
+		IdempotencyKey: uuid.MustParse("5f0c2d7e-8b94-4a3f-b1e6-2c9d0a7f4e35"),
+		Operation:      entity.Withdraw,
+		Amount:         50,
+		Status:         entity.Success,
+	}
+
+	e := entity.NewOperationSucceededEvent(wallet, tr)
+	e.ID = uuid.MustParse("9d3e1a4b-6c2f-4e8d-a7b0-1f5c3e9d2b64")
+	e.OccurredAt = time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	var got broker.Message
+	repo := New(publisherFunc(func(_ context.Context, msg broker.Message) error {
+		got = msg
+		return nil
+	}), Config{Source: "/wallet"})
+
+	require.NoError(t, repo.Publish(context.Background(), e))
+
+	assert.Equal(t, wallet.UUID.String(), string(got.Key))
+	assert.Equal(t, envelopeContentType, got.Headers[contentTypeHeader])
+	assert.JSONEq(t, `{
+		"specversion": "1.0",
+		"id": "9d3e1a4b-6c2f-4e8d-a7b0-1f5c3e9d2b64",
+		"source": "/wallet",
+		"type": "wallet.operation.succeeded.v1",
+		"subject": "0b6a8f4e-3c1d-4f6e-9a51-7d2c8e9f0a11",
+		"time": "2025-01-15T10:00:00Z",
+		"datacontenttype": "application/json",
+		"data": {
+			"walletId": "0b6a8f4e-3c1d-4f6e-9a51-7d2c8e9f0a11",
+			"owner": "user-1",
+			"balance": 150,
+			"version": 3,
+			"frozen": false,
+			"operation": {
+				"idempotencyKey": "5f0c2d7e-8b94-4a3f-b1e6-2c9d0a7f4e35",
+				"operationType": "withdraw",
+				"amount": 50,
+				"status": "success"
+			}
+		}
+	}`, string(got.Value))
+}
@@ -0,0 +1,7 @@
+package event
+
+import "errors"
+
+var (
+	ErrMalformedEvent = errors.New("malformed event")
+)
@@ -0,0 +1,136 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	jsoniter "github.com/json-iterator/go"
+	"log"
+	"strings"
+	"time"
+	"wallet/internal/entity"
+	"wallet/internal/infrastructure/cache/redis"
+)
+
+type cache interface {
+	Eval(ctx context.Context, script string, keys []string, args ...any) (any, error)
+	StreamRange(ctx context.Context, key, start string) ([]redis.StreamMessage, error)
+	Subscribe(ctx context.Context, channel string) <-chan string
+}
+
+type Config struct {
+	// HistorySize is the approximate number of events kept per wallet for resuming subscriptions.
+	HistorySize int64
+	// HistoryTTL is how long the history of an idle wallet is kept.
+	HistoryTTL time.Duration
+}
+
+// Repository stores wallet events in a redis stream per wallet and fans them out to every replica
+// through a single pub/sub channel.
+type Repository struct {
+	cache cache
+	cfg   Config
+}
+
+func New(cache cache, cfg Config) *Repository {
+	return &Repository{
+		cache: cache,
+		cfg:   cfg,
+	}
+}
+
+const (
+	channel      = "wallet-events"
+	streamPrefix = "events:"
+	separator    = "\n"
+)
+
+// publishScript appends the event to the wallet stream and publishes it with the assigned id,
+// so live subscribers and resumed ones see the same ids.
+const publishScript = `
+local id = redis.call('XADD', KEYS[1], 'MAXLEN', '~', ARGV[2], '*', 'data', ARGV[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+redis.call('PUBLISH', ARGV[4], id .. '\n' .. ARGV[1])
+return id
+`
+
+func (r Repository) Publish(ctx context.Context, e *entity.WalletEvent) error {
+	data, err := jsoniter.MarshalToString(e)
+	if err != nil {
+		return err
+	}
+
+	id, err := r.cache.Eval(ctx, publishScript,
+		[]string{streamPrefix + e.WalletUUID.String()},
+		data,
+		r.cfg.HistorySize,
+		r.cfg.HistoryTTL.Milliseconds(),
+		channel,
+	)
+	if err != nil {
+		return err
+	}
+
+	e.ID = fmt.Sprint(id)
+	return nil
+}
+
+// History returns the events of the wallet published after the event with afterID.
+func (r Repository) History(ctx context.Context, walletUUID uuid.UUID, afterID string) ([]*entity.WalletEvent, error) {
+	messages, err := r.cache.StreamRange(ctx, streamPrefix+walletUUID.String(), "("+afterID)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*entity.WalletEvent, 0, len(messages))
+	for _, msg := range messages {
+		e, err := decode(msg.ID, msg.Data)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	return events, nil
+}
+
+// Listen delivers events published by any replica until ctx is done.
+func (r Repository) Listen(ctx context.Context) <-chan *entity.WalletEvent {
+	messages := r.cache.Subscribe(ctx, channel)
+	out := make(chan *entity.WalletEvent)
+
+	go func() {
+		defer close(out)
+
+		for msg := range messages {
+			id, data, ok := strings.Cut(msg, separator)
+			if !ok {
+				log.Println("error listen events: ", ErrMalformedEvent)
+				continue
+			}
+
+			e, err := decode(id, data)
+			if err != nil {
+				log.Println("error listen events: ", err)
+				continue
+			}
+
+			select {
+			case out <- e:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+func decode(id, data string) (*entity.WalletEvent, error) {
+	e := new(entity.WalletEvent)
+	if err := jsoniter.UnmarshalFromString(data, e); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformedEvent, err)
+	}
+	e.ID = id
+	return e, nil
+}
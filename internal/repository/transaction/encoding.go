@@ -0,0 +1,232 @@
+package transaction
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	jsoniter "github.com/json-iterator/go"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
+	"wallet/internal/entity"
+	"wallet/internal/infrastructure/broker"
+	"wallet/internal/repository/transaction/pb"
+)
+
+// Encoding is the wire format of published transactions. Consumers decode every encoding,
+// so the producer encoding can be switched with a rolling deploy.
+type Encoding string
+
+var (
+	// EncodingLegacy is the unversioned json written before the envelope was introduced.
+	// It is only needed while replicas without envelope support are running.
+	EncodingLegacy   Encoding = "legacy"
+	EncodingJSON     Encoding = "json"
+	EncodingProtobuf Encoding = "protobuf"
+)
+
+const (
+	contentTypeHeader   = "content-type"
+	jsonContentType     = "application/json"
+	protobufContentType = "application/x-protobuf"
+
+	// envelopeVersion is bumped on incompatible changes of the payload.
+	envelopeVersion = 1
+)
+
+// jsonEnvelope pins the json wire format, it must not follow the tags of entity.Transaction.
+type jsonEnvelope struct {
+	Version     uint32           `json:"version"`
+	Transaction *jsonTransaction `json:"transaction"`
+}
+
+type jsonTransaction struct {
+	WalletUUID     uuid.UUID `json:"walletId"`
+	IdempotencyKey uuid.UUID `json:"idempotencyKey"`
+	Operation      string    `json:"operationType"`
+	Amount         int64     `json:"amount"`
+	Status         string    `json:"status"`
+	Initiator      string    `json:"initiator,omitempty"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// legacyTransaction is the format of entity.Transaction.Marshall before the envelope.
+type legacyTransaction struct {
+	WalletUUID     uuid.UUID `json:"wallet-uuid"`
+	IdempotencyKey uuid.UUID `json:"idempotency-key"`
+	Operation      string    `json:"operation"`
+	Amount         int64     `json:"amount"`
+	Status         string    `json:"status"`
+	Initiator      string    `json:"initiator,omitempty"`
+	CreatedAt      time.Time `json:"created-at"`
+	UpdatedAt      time.Time `json:"updated-at"`
+}
+
+func (e Encoding) valid() bool {
+	switch e {
+	case EncodingLegacy, EncodingJSON, EncodingProtobuf:
+		return true
+	}
+	return false
+}
+
+func encode(encoding Encoding, tr *entity.Transaction) (broker.Message, error) {
+	msg := broker.Message{
+		Key: []byte(tr.WalletUUID.String()),
+	}
+
+	var err error
+	switch encoding {
+	case EncodingLegacy:
+		msg.Value, err = jsoniter.Marshal(legacyTransaction(*toJSONTransaction(tr)))
+	case EncodingJSON:
+		msg.Headers = map[string]string{contentTypeHeader: jsonContentType}
+		msg.Value, err = jsoniter.Marshal(jsonEnvelope{
+			Version:     envelopeVersion,
+			Transaction: toJSONTransaction(tr),
+		})
+	case EncodingProtobuf:
+		msg.Headers = map[string]string{contentTypeHeader: protobufContentType}
+		msg.Value, err = proto.MarshalOptions{Deterministic: true}.Marshal(&pb.TransactionEnvelope{
+			Version:     envelopeVersion,
+			Transaction: toProtoTransaction(tr),
+		})
+	default:
+		return broker.Message{}, fmt.Errorf("%w: %q", ErrUnknownEncoding, encoding)
+	}
+	if err != nil {
+		return broker.Message{}, err
+	}
+
+	return msg, nil
+}
+
+// decode reads any supported encoding. Messages without content type are legacy ones.
+func decode(msg broker.Message) (*entity.Transaction, error) {
+	switch contentType := msg.Headers[contentTypeHeader]; contentType {
+	case "":
+		var t legacyTransaction
+		if err := jsoniter.Unmarshal(msg.Value, &t); err != nil {
+			return nil, err
+		}
+		j := jsonTransaction(t)
+		return fromJSONTransaction(&j), nil
+	case jsonContentType:
+		var e jsonEnvelope
+		if err := jsoniter.Unmarshal(msg.Value, &e); err != nil {
+			return nil, err
+		}
+		if e.Version != envelopeVersion || e.Transaction == nil {
+			return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, e.Version)
+		}
+		return fromJSONTransaction(e.Transaction), nil
+	case protobufContentType:
+		var e pb.TransactionEnvelope
+		if err := proto.Unmarshal(msg.Value, &e); err != nil {
+			return nil, err
+		}
+		if e.GetVersion() != envelopeVersion || e.GetTransaction() == nil {
+			return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, e.GetVersion())
+		}
+		return fromProtoTransaction(e.GetTransaction())
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedContentType, contentType)
+	}
+}
+
+func toJSONTransaction(tr *entity.Transaction) *jsonTransaction {
+	return &jsonTransaction{
+		WalletUUID:     tr.WalletUUID,
+		IdempotencyKey: tr.IdempotencyKey,
+		Operation:      string(tr.Operation),
+		Amount:         tr.Amount,
+		Status:         string(tr.Status),
+		Initiator:      tr.Initiator,
+		CreatedAt:      tr.CreatedAt,
+		UpdatedAt:      tr.UpdatedAt,
+	}
+}
+
+func fromJSONTransaction(t *jsonTransaction) *entity.Transaction {
+	return &entity.Transaction{
+		WalletUUID:     t.WalletUUID,
+		IdempotencyKey: t.IdempotencyKey,
+		Operation:      entity.OperationType(t.Operation),
+		Amount:         t.Amount,
+		Status:         entity.Status(t.Status),
+		Initiator:      t.Initiator,
+		CreatedAt:      t.CreatedAt,
+		UpdatedAt:      t.UpdatedAt,
+	}
+}
+
+func toProtoTransaction(tr *entity.Transaction) *pb.Transaction {
+	t := &pb.Transaction{
+		WalletUuid:     tr.WalletUUID.String(),
+		IdempotencyKey: tr.IdempotencyKey.String(),
+		Amount:         tr.Amount,
+		Initiator:      tr.Initiator,
+		CreatedAt:      timestamppb.New(tr.CreatedAt),
+		UpdatedAt:      timestamppb.New(tr.UpdatedAt),
+	}
+
+	switch tr.Operation {
+	case entity.Deposit:
+		t.Operation = pb.OperationType_OPERATION_TYPE_DEPOSIT
+	case entity.Withdraw:
+		t.Operation = pb.OperationType_OPERATION_TYPE_WITHDRAW
+	}
+
+	switch tr.Status {
+	case entity.New:
+		t.Status = pb.Status_STATUS_NEW
+	case entity.Success:
+		t.Status = pb.Status_STATUS_SUCCESS
+	case entity.Failure:
+		t.Status = pb.Status_STATUS_FAILURE
+	}
+
+	return t
+}
+
+func fromProtoTransaction(t *pb.Transaction) (*entity.Transaction, error) {
+	walletUUID, err := uuid.Parse(t.GetWalletUuid())
+	if err != nil {
+		return nil, err
+	}
+	idempotencyKey, err := uuid.Parse(t.GetIdempotencyKey())
+	if err != nil {
+		return nil, err
+	}
+
+	tr := &entity.Transaction{
+		WalletUUID:     walletUUID,
+		IdempotencyKey: idempotencyKey,
+		Amount:         t.GetAmount(),
+		Initiator:      t.GetInitiator(),
+		CreatedAt:      t.GetCreatedAt().AsTime(),
+		UpdatedAt:      t.GetUpdatedAt().AsTime(),
+	}
+
+	switch t.GetOperation() {
+	case pb.OperationType_OPERATION_TYPE_DEPOSIT:
+		tr.Operation = entity.Deposit
+	case pb.OperationType_OPERATION_TYPE_WITHDRAW:
+		tr.Operation = entity.Withdraw
+	default:
+		return nil, entity.ErrInvalidOperationType
+	}
+
+	switch t.GetStatus() {
+	case pb.Status_STATUS_NEW:
+		tr.Status = entity.New
+	case pb.Status_STATUS_SUCCESS:
+		tr.Status = entity.Success
+	case pb.Status_STATUS_FAILURE:
+		tr.Status = entity.Failure
+	default:
+		return nil, entity.ErrInvalidStatus
+	}
+
+	return tr, nil
+}
@@ -0,0 +1,84 @@
+package transaction
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"wallet/internal/entity"
+	"wallet/internal/infrastructure/broker"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The golden files pin the wire format: messages written by one release must be readable by the next one.
+// Regenerate them with -update only together with a new envelope version.
+var update = flag.Bool("update", false, "update golden files")
+
+func goldenTransaction() *entity.Transaction {
+	return &entity.Transaction{
+		WalletUUID:     uuid.MustParse("0b6a8f4e-3c1d-4f6e-9a51-7d2c8e9f0a11"),
+		IdempotencyKey: uuid.MustParse("5f0c2d7e-8b94-4a3f-b1e6-2c9d0a7f4e35"),
+		Operation:      entity.Withdraw,
+		Amount:         50,
+		Status:         entity.New,
+		Initiator:      "user-1",
+		CreatedAt:      time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
+		UpdatedAt:      time.Date(2025, 1, 15, 10, 0, 1, 0, time.UTC),
+	}
+}
+
+func TestEncoding_Golden(t *testing.T) {
+	tcs := []struct {
+		encoding    Encoding
+		golden      string
+		contentType string
+	}{
+		{EncodingLegacy, "transaction_legacy.json", ""},
+		{EncodingJSON, "transaction_v1.json", jsonContentType},
+		{EncodingProtobuf, "transaction_v1.pb", protobufContentType},
+	}
+
+	for _, tc := range tcs {
+		t.Run(string(tc.encoding), func(t *testing.T) {
+			path := filepath.Join("testdata", tc.golden)
+
+			msg, err := encode(tc.encoding, goldenTransaction())
+			require.NoError(t, err)
+			assert.Equal(t, tc.contentType, msg.Headers[contentTypeHeader])
+			assert.Equal(t, "0b6a8f4e-3c1d-4f6e-9a51-7d2c8e9f0a11", string(msg.Key))
+
+			if *update {
+				require.NoError(t, os.WriteFile(path, msg.Value, 0o644))
+			}
+
+			golden, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, golden, msg.Value)
+
+			tr, err := decode(broker.Message{Value: golden, Headers: msg.Headers})
+			require.NoError(t, err)
+			assert.Equal(t, goldenTransaction(), tr)
+		})
+	}
+}
+
+func TestDecode_Errors(t *testing.T) {
+	_, err := decode(broker.Message{
+		Value:   []byte(`{"version":2,"transaction":{}}`),
+		Headers: map[string]string{contentTypeHeader: jsonContentType},
+	})
+	assert.ErrorIs(t, err, ErrUnsupportedVersion)
+
+	_, err = decode(broker.Message{
+		Value:   []byte(`<transaction/>`),
+		Headers: map[string]string{contentTypeHeader: "application/xml"},
+	})
+	assert.ErrorIs(t, err, ErrUnsupportedContentType)
+
+	_, err = encode("xml", goldenTransaction())
+	assert.ErrorIs(t, err, ErrUnknownEncoding)
+}
@@ -3,5 +3,9 @@ package transaction
 import "errors"
 
 var (
-	ErrDuplicateTransaction = errors.New("duplicate transaction")
+	ErrDuplicateTransaction   = errors.New("duplicate transaction")
+	ErrTransactionNotFound    = errors.New("transaction not found")
+	ErrUnknownEncoding        = errors.New("unknown transaction encoding")
+	ErrUnsupportedContentType = errors.New("unsupported transaction content type")
+	ErrUnsupportedVersion     = errors.New("unsupported transaction envelope version")
 )
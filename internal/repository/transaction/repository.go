@@ -3,37 +3,58 @@ package transaction
 import (
 	"context"
 	"errors"
+	"fmt"
 	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"maps"
 	"wallet/internal/entity"
+	"wallet/internal/infrastructure/broker"
 	"wallet/internal/utils/metrics"
 )
 
 type consumer interface {
-	Consume(context.Context) ([]byte, error)
+	Consume(context.Context) (broker.Message, error)
 }
 
 type publisher interface {
-	Publish(context.Context, []byte) error
+	Publish(context.Context, broker.Message) error
+}
+
+type Config struct {
+	// Encoding is the wire format of published transactions.
+	Encoding Encoding
 }
 
 type Repository struct {
 	consumer  consumer
 	publisher publisher
+	// deadLetters receives messages that can't be decoded. It is nil for brokers delivering them again.
+	deadLetters publisher
+	cfg         Config
 }
 
-func New(consumer consumer, publisher publisher) *Repository {
-	return &Repository{
-		consumer:  consumer,
-		publisher: publisher,
+func New(consumer consumer, publisher publisher, deadLetters publisher, cfg Config) (*Repository, error) {
+	if !cfg.Encoding.valid() {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownEncoding, cfg.Encoding)
 	}
+
+	return &Repository{
+		consumer:    consumer,
+		publisher:   publisher,
+		deadLetters: deadLetters,
+		cfg:         cfg,
+	}, nil
 }
 
 const (
-	insertTransactionFn  = "insert transaction"
-	isExistTransactionFn = "is exist transaction"
+	insertTransactionFn      = "insert transaction"
+	insertTransactionBatchFn = "insert transaction batch"
+	isExistTransactionFn     = "is exist transaction"
+	existingTransactionsFn   = "existing transactions"
+	getTransactionByKeyFn    = "get transaction by idempotency key"
 )
 
 func (r Repository) Insert(ctx context.Context, tx pgx.Tx, tr *entity.Transaction) error {
@@ -45,6 +66,7 @@ func (r Repository) Insert(ctx context.Context, tx pgx.Tx, tr *entity.Transactio
 			"operation",
 			"amount",
 			"status",
+			"initiator",
 			"created_at",
 			"updated_at",
 		).
@@ -54,6 +76,7 @@ func (r Repository) Insert(ctx context.Context, tx pgx.Tx, tr *entity.Transactio
 			tr.Operation,
 			tr.Amount,
 			tr.Status,
+			tr.Initiator,
 			tr.CreatedAt,
 			tr.UpdatedAt,
 		).
@@ -79,6 +102,77 @@ func (r Repository) Insert(ctx context.Context, tx pgx.Tx, tr *entity.Transactio
 	return nil
 }
 
+// InsertBatch inserts transactions of one batch with a single statement.
+func (r Repository) InsertBatch(ctx context.Context, tx pgx.Tx, trs []*entity.Transaction) error {
+	if len(trs) == 0 {
+		return nil
+	}
+
+	builder := sq.
+		Insert("transactions").
+		Columns(
+			"wallet_uuid",
+			"idempotency_key",
+			"operation",
+			"amount",
+			"status",
+			"initiator",
+			"created_at",
+			"updated_at",
+		)
+	byKey := make(map[uuid.UUID]*entity.Transaction, len(trs))
+	for _, tr := range trs {
+		builder = builder.Values(
+			tr.WalletUUID,
+			tr.IdempotencyKey,
+			tr.Operation,
+			tr.Amount,
+			tr.Status,
+			tr.Initiator,
+			tr.CreatedAt,
+			tr.UpdatedAt,
+		)
+		byKey[tr.IdempotencyKey] = tr
+	}
+
+	stmt, args, err := builder.
+		Suffix("RETURNING \"id\", \"idempotency_key\"").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	rows, err := metrics.Tx().Query(insertTransactionBatchFn, ctx, tx, stmt, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id  int64
+			key uuid.UUID
+		)
+		if err := rows.Scan(&id, &key); err != nil {
+			return err
+		}
+		if tr, ok := byKey[key]; ok {
+			tr.ID = id
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return ErrDuplicateTransaction
+		}
+		return err
+	}
+
+	return nil
+}
+
 func (r Repository) Exists(ctx context.Context, tx pgx.Tx, tr *entity.Transaction) (bool, error) {
 	stmt, args, err := sq.
 		Select("COUNT(*)").
@@ -99,25 +193,141 @@ func (r Repository) Exists(ctx context.Context, tx pgx.Tx, tr *entity.Transactio
 	return count > 0, nil
 }
 
-func (r Repository) Publish(ctx context.Context, tr *entity.Transaction) error {
-	data, err := tr.Marshall()
+// ExistingKeys returns which of the idempotency keys are already recorded for the wallet.
+func (r Repository) ExistingKeys(ctx context.Context, tx pgx.Tx, walletUUID uuid.UUID, keys []uuid.UUID) (map[uuid.UUID]struct{}, error) {
+	stmt, args, err := sq.
+		Select("idempotency_key").
+		From("transactions").
+		Where(sq.Eq{
+			"wallet_uuid":     walletUUID,
+			"idempotency_key": keys,
+		}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return r.publisher.Publish(ctx, data)
+	rows, err := metrics.Tx().Query(existingTransactionsFn, ctx, tx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	existing := make(map[uuid.UUID]struct{}, len(keys))
+	for rows.Next() {
+		var key uuid.UUID
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		existing[key] = struct{}{}
+	}
+
+	return existing, rows.Err()
 }
 
-func (r Repository) Consume(ctx context.Context) (*entity.Transaction, error) {
-	data, err := r.consumer.Consume(ctx)
+func (r Repository) GetByIdempotencyKey(ctx context.Context, tx pgx.Tx, key uuid.UUID) (*entity.Transaction, error) {
+	stmt, args, err := sq.Select(
+		"id",
+		"wallet_uuid",
+		"idempotency_key",
+		"operation",
+		"amount",
+		"status",
+		"initiator",
+		"created_at",
+		"updated_at",
+	).
+		From("transactions").
+		Where(sq.Eq{"idempotency_key": key}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
 	if err != nil {
 		return nil, err
 	}
 
 	tr := new(entity.Transaction)
-	if err := tr.Unmarshall(data); err != nil {
+
+	if err := metrics.Tx().QueryRow(getTransactionByKeyFn, ctx, tx, stmt, args...).
+		Scan(
+			&tr.ID,
+			&tr.WalletUUID,
+			&tr.IdempotencyKey,
+			&tr.Operation,
+			&tr.Amount,
+			&tr.Status,
+			&tr.Initiator,
+			&tr.CreatedAt,
+			&tr.UpdatedAt,
+		); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTransactionNotFound
+		}
 		return nil, err
 	}
 
 	return tr, nil
 }
+
+func (r Repository) Publish(ctx context.Context, tr *entity.Transaction) error {
+	msg, err := encode(r.cfg.Encoding, tr)
+	if err != nil {
+		return err
+	}
+
+	return r.publisher.Publish(ctx, msg)
+}
+
+// Ack acknowledges a consumed transaction.
+type Ack func(context.Context) error
+
+func noAck(context.Context) error { return nil }
+
+// Consume returns the next transaction with its Ack. The Ack must be called only after the transaction
+// is processed: the postgres queue delivers unacknowledged transactions again, Kafka commits them when read.
+func (r Repository) Consume(ctx context.Context) (*entity.Transaction, Ack, error) {
+	msg, err := r.consumer.Consume(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tr, err := decode(msg)
+	if err != nil {
+		return nil, nil, r.deadLetter(ctx, msg, err)
+	}
+
+	if msg.Ack == nil {
+		return tr, noAck, nil
+	}
+	return tr, msg.Ack, nil
+}
+
+const deadLetterReasonHeader = "dead-letter-reason"
+
+// deadLetter publishes a message that can't be decoded to the dead letter topic and acknowledges it.
+// Without a dead letter publisher the message is not acknowledged: the postgres queue delivers it again
+// and moves it to its own dead letter topic after the last attempt. Kafka commits messages when they are
+// read, so a message that fails to reach the dead letter topic is lost and only logged by the caller.
+func (r Repository) deadLetter(ctx context.Context, msg broker.Message, reason error) error {
+	if r.deadLetters == nil {
+		return reason
+	}
+
+	headers := maps.Clone(msg.Headers)
+	if headers == nil {
+		headers = make(map[string]string, 1)
+	}
+	headers[deadLetterReasonHeader] = reason.Error()
+
+	if err := r.deadLetters.Publish(ctx, broker.Message{Key: msg.Key, Value: msg.Value, Headers: headers}); err != nil {
+		return fmt.Errorf("%w, dead letter not published: %w", reason, err)
+	}
+
+	if msg.Ack != nil {
+		if err := msg.Ack(ctx); err != nil {
+			return fmt.Errorf("%w, dead letter not acknowledged: %w", reason, err)
+		}
+	}
+
+	return fmt.Errorf("%w, moved to the dead letter topic", reason)
+}
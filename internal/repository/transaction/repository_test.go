@@ -0,0 +1,95 @@
+package transaction
+
+import (
+	"context"
+	"testing"
+	"wallet/internal/infrastructure/broker"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePublisher struct {
+	published []broker.Message
+}
+
+func (p *fakePublisher) Publish(_ context.Context, msg broker.Message) error {
+	p.published = append(p.published, msg)
+	return nil
+}
+
+type fakeConsumer struct {
+	msg broker.Message
+}
+
+func (c fakeConsumer) Consume(context.Context) (broker.Message, error) {
+	return c.msg, nil
+}
+
+func TestRepository_Consume_Ack(t *testing.T) {
+	msg, err := encode(EncodingJSON, goldenTransaction())
+	require.NoError(t, err)
+
+	acked := 0
+	msg.Ack = func(context.Context) error {
+		acked++
+		return nil
+	}
+
+	r, err := New(fakeConsumer{msg: msg}, nil, nil, Config{Encoding: EncodingJSON})
+	require.NoError(t, err)
+
+	tr, ack, err := r.Consume(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, goldenTransaction().IdempotencyKey, tr.IdempotencyKey)
+
+	// the message is acknowledged by the caller once the transaction is processed
+	assert.Equal(t, 0, acked)
+	require.NoError(t, ack(context.Background()))
+	assert.Equal(t, 1, acked)
+
+	// a message that can't be decoded is left for the broker to redeliver
+	msg.Value = []byte(`{"version":2,"transaction":{}}`)
+	r.consumer = fakeConsumer{msg: msg}
+
+	_, _, err = r.Consume(context.Background())
+	assert.ErrorIs(t, err, ErrUnsupportedVersion)
+	assert.Equal(t, 1, acked)
+
+	// brokers committing on their own get an ack that does nothing
+	msg, err = encode(EncodingJSON, goldenTransaction())
+	require.NoError(t, err)
+	r.consumer = fakeConsumer{msg: msg}
+
+	_, ack, err = r.Consume(context.Background())
+	require.NoError(t, err)
+	assert.NoError(t, ack(context.Background()))
+}
+
+func TestRepository_Consume_DeadLetter(t *testing.T) {
+	acked := 0
+	msg := broker.Message{
+		Key:     []byte("key"),
+		Value:   []byte(`{"version":2,"transaction":{}}`),
+		Headers: map[string]string{"content-type": "application/json"},
+		Ack: func(context.Context) error {
+			acked++
+			return nil
+		},
+	}
+	deadLetters := &fakePublisher{}
+
+	r, err := New(fakeConsumer{msg: msg}, nil, deadLetters, Config{Encoding: EncodingJSON})
+	require.NoError(t, err)
+
+	_, _, err = r.Consume(context.Background())
+	assert.ErrorIs(t, err, ErrUnsupportedVersion)
+
+	require.Len(t, deadLetters.published, 1)
+	assert.Equal(t, msg.Value, deadLetters.published[0].Value)
+	assert.Equal(t, "application/json", deadLetters.published[0].Headers["content-type"])
+	assert.Contains(t, deadLetters.published[0].Headers[deadLetterReasonHeader], ErrUnsupportedVersion.Error())
+	assert.Equal(t, 1, acked)
+	// the original message headers are not changed
+	assert.NotContains(t, msg.Headers, deadLetterReasonHeader)
+}
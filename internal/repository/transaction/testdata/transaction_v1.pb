@@ -0,0 +1,2 @@
+j
+$0b6a8f4e-3c1d-4f6e-9a51-7d2c8e9f0a11$5f0c2d7e-8b94-4a3f-b1e6-2c9d0a7f4e35 2(2user-1:B
\ No newline at end of file
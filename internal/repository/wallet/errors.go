@@ -3,6 +3,8 @@ package wallet
 import "errors"
 
 var (
-	ErrWalletNotFound = errors.New("wallet not found")
-	ErrNoRowsAffected = errors.New("no rows affected")
+	ErrWalletNotFound   = errors.New("wallet not found")
+	ErrNoRowsAffected   = errors.New("no rows affected")
+	ErrMalformedBalance = errors.New("malformed cached balance")
+	ErrCacheMiss        = errors.New("cache miss")
 )
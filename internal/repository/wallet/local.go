@@ -0,0 +1,171 @@
+package wallet
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"log"
+	"sync"
+	"time"
+	"wallet/internal/utils/lru"
+	"wallet/internal/utils/metrics"
+)
+
+// BalanceCache caches balances and owners of wallets. It is implemented by Repository and LocalCache.
+type BalanceCache interface {
+	SetBalance(ctx context.Context, uid uuid.UUID, balance, version int64) error
+	GetBalance(context.Context, uuid.UUID) (int64, error)
+	InvalidateBalance(context.Context, uuid.UUID) error
+	SetOwner(context.Context, uuid.UUID, string) error
+	GetOwner(context.Context, uuid.UUID) (string, error)
+}
+
+type bus interface {
+	Publish(ctx context.Context, channel, message string) error
+	Subscribe(ctx context.Context, channel string) <-chan string
+}
+
+type LocalConfig struct {
+	// Size is the number of wallets kept in memory.
+	Size int
+	// TTL bounds staleness when an invalidation is lost, e.g. while reconnecting to redis.
+	TTL time.Duration
+}
+
+// LocalCache is an in-process LRU in front of the redis cache. Balance writes are announced over
+// redis pub/sub, so every replica drops its copy when any consumer updates the wallet.
+// Balances are filled only from redis, which keeps the newest version.
+type LocalCache struct {
+	remote   BalanceCache
+	bus      bus
+	balances *lru.Cache[uuid.UUID, int64]
+	owners   *lru.Cache[uuid.UUID, string]
+	// reads tracks wallets with balances being read from redis. A balance read is kept only if
+	// the wallet was not invalidated during the read, otherwise it may be older than the invalidation.
+	mu    sync.Mutex
+	reads map[uuid.UUID]*pendingRead
+}
+
+// pendingRead counts invalidations of a wallet while its balance is read from redis.
+// It is dropped with the last reader, so the map holds only wallets being read.
+type pendingRead struct {
+	readers       int
+	invalidations uint64
+}
+
+const invalidationChannel = "balance-invalidations"
+
+func NewLocalCache(ctx context.Context, remote BalanceCache, bus bus, cfg LocalConfig) *LocalCache {
+	c := &LocalCache{
+		remote:   remote,
+		bus:      bus,
+		balances: lru.New[uuid.UUID, int64](cfg.Size, cfg.TTL),
+		reads:    make(map[uuid.UUID]*pendingRead),
+		// owners never change, they only leave memory with the least recently used wallets
+		owners: lru.New[uuid.UUID, string](cfg.Size, 0),
+	}
+
+	// subscribe before serving reads, so no invalidation is missed
+	go c.listen(c.bus.Subscribe(ctx, invalidationChannel))
+
+	return c
+}
+
+func (c *LocalCache) SetBalance(ctx context.Context, uid uuid.UUID, balance, version int64) error {
+	// the local copy is dropped even if redis is unavailable, the next read falls back to the database
+	c.invalidate(uid)
+
+	if err := c.remote.SetBalance(ctx, uid, balance, version); err != nil {
+		return err
+	}
+
+	return c.bus.Publish(ctx, invalidationChannel, uid.String())
+}
+
+func (c *LocalCache) GetBalance(ctx context.Context, uid uuid.UUID) (int64, error) {
+	if balance, ok := c.balances.Get(uid); ok {
+		metrics.IncCacheRequests(metrics.CacheLayerLocal, metrics.CacheResultHit)
+		return balance, nil
+	}
+	metrics.IncCacheRequests(metrics.CacheLayerLocal, metrics.CacheResultMiss)
+
+	c.mu.Lock()
+	read, ok := c.reads[uid]
+	if !ok {
+		read = &pendingRead{}
+		c.reads[uid] = read
+	}
+	read.readers++
+	seen := read.invalidations
+	c.mu.Unlock()
+
+	balance, err := c.remote.GetBalance(ctx, uid)
+
+	c.mu.Lock()
+	if err == nil && read.invalidations == seen {
+		c.balances.Set(uid, balance)
+	}
+	read.readers--
+	if read.readers == 0 {
+		delete(c.reads, uid)
+	}
+	c.mu.Unlock()
+
+	if err != nil {
+		return 0, err
+	}
+
+	return balance, nil
+}
+
+func (c *LocalCache) InvalidateBalance(ctx context.Context, uid uuid.UUID) error {
+	if err := c.remote.InvalidateBalance(ctx, uid); err != nil {
+		return err
+	}
+
+	c.invalidate(uid)
+
+	return c.bus.Publish(ctx, invalidationChannel, uid.String())
+}
+
+func (c *LocalCache) SetOwner(ctx context.Context, uid uuid.UUID, owner string) error {
+	if err := c.remote.SetOwner(ctx, uid, owner); err != nil {
+		return err
+	}
+	c.owners.Set(uid, owner)
+	return nil
+}
+
+func (c *LocalCache) GetOwner(ctx context.Context, uid uuid.UUID) (string, error) {
+	if owner, ok := c.owners.Get(uid); ok {
+		return owner, nil
+	}
+
+	owner, err := c.remote.GetOwner(ctx, uid)
+	if err != nil {
+		return "", err
+	}
+
+	c.owners.Set(uid, owner)
+
+	return owner, nil
+}
+
+func (c *LocalCache) listen(messages <-chan string) {
+	for msg := range messages {
+		uid, err := uuid.Parse(msg)
+		if err != nil {
+			log.Printf("malformed balance invalidation %q: %v\n", msg, err)
+			continue
+		}
+		c.invalidate(uid)
+	}
+}
+
+func (c *LocalCache) invalidate(uid uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if read, ok := c.reads[uid]; ok {
+		read.invalidations++
+	}
+	c.balances.Delete(uid)
+}
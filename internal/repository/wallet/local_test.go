@@ -0,0 +1,153 @@
+package wallet
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRemote struct {
+	mu       sync.Mutex
+	balances map[uuid.UUID]int64
+	reads    int
+}
+
+func (r *fakeRemote) SetBalance(_ context.Context, uid uuid.UUID, balance, _ int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.balances[uid] = balance
+	return nil
+}
+
+func (r *fakeRemote) GetBalance(_ context.Context, uid uuid.UUID) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reads++
+	return r.balances[uid], nil
+}
+
+func (r *fakeRemote) InvalidateBalance(_ context.Context, uid uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.balances, uid)
+	return nil
+}
+
+func (r *fakeRemote) SetOwner(context.Context, uuid.UUID, string) error   { return nil }
+func (r *fakeRemote) GetOwner(context.Context, uuid.UUID) (string, error) { return "", nil }
+
+func (r *fakeRemote) readCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.reads
+}
+
+// fakeBus delivers published messages to every subscriber, like redis pub/sub between replicas.
+type fakeBus struct {
+	mu   sync.Mutex
+	subs []chan string
+}
+
+func (b *fakeBus) Publish(_ context.Context, _, message string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, sub := range b.subs {
+		sub <- message
+	}
+	return nil
+}
+
+func (b *fakeBus) Subscribe(_ context.Context, _ string) <-chan string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	sub := make(chan string, 16)
+	b.subs = append(b.subs, sub)
+	return sub
+}
+
+func TestLocalCache_Invalidation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	uid := uuid.New()
+	remote := &fakeRemote{balances: map[uuid.UUID]int64{uid: 100}}
+	bus := &fakeBus{}
+	cfg := LocalConfig{Size: 10, TTL: time.Minute}
+
+	replicaA := NewLocalCache(ctx, remote, bus, cfg)
+	replicaB := NewLocalCache(ctx, remote, bus, cfg)
+
+	// the second read of a replica is served from memory
+	for range 2 {
+		balance, err := replicaB.GetBalance(ctx, uid)
+		require.NoError(t, err)
+		assert.Equal(t, int64(100), balance)
+	}
+	assert.Equal(t, 1, remote.readCount())
+
+	// the consumer on replica A updates the balance, replica B drops its copy
+	require.NoError(t, replicaA.SetBalance(ctx, uid, 150, 2))
+
+	assert.Eventually(t, func() bool {
+		balance, err := replicaB.GetBalance(ctx, uid)
+		return err == nil && balance == 150
+	}, time.Second, 5*time.Millisecond)
+}
+
+// blockingRemote holds GetBalance until release is closed, so invalidations can arrive during the read.
+type blockingRemote struct {
+	*fakeRemote
+	started chan struct{}
+	release chan struct{}
+}
+
+func (r *blockingRemote) GetBalance(ctx context.Context, uid uuid.UUID) (int64, error) {
+	r.started <- struct{}{}
+	<-r.release
+	return r.fakeRemote.GetBalance(ctx, uid)
+}
+
+func TestLocalCache_InvalidationDuringRead(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	hot, other := uuid.New(), uuid.New()
+	remote := &blockingRemote{
+		fakeRemote: &fakeRemote{balances: map[uuid.UUID]int64{hot: 100, other: 5}},
+		started:    make(chan struct{}, 1),
+	}
+	cache := NewLocalCache(ctx, remote, &fakeBus{}, LocalConfig{Size: 10, TTL: time.Minute})
+
+	// readDuring reads the balance of hot and invalidates uid while the read waits for redis
+	readDuring := func(uid uuid.UUID) {
+		remote.release = make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, err := cache.GetBalance(ctx, hot)
+			assert.NoError(t, err)
+		}()
+		<-remote.started
+		cache.invalidate(uid)
+		close(remote.release)
+		<-done
+	}
+
+	// an invalidation of another wallet keeps the result
+	readDuring(other)
+	_, ok := cache.balances.Get(hot)
+	assert.True(t, ok)
+
+	// an invalidation of the same wallet drops it
+	cache.invalidate(hot)
+	readDuring(hot)
+	_, ok = cache.balances.Get(hot)
+	assert.False(t, ok)
+
+	assert.Empty(t, cache.reads)
+}
@@ -7,23 +7,34 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"strconv"
+	"strings"
 	"time"
 	"wallet/internal/entity"
+	"wallet/internal/infrastructure/cache/redis"
 	"wallet/internal/utils/metrics"
 )
 
 type cache interface {
 	SetWithTTL(ctx context.Context, key, value string, ttl time.Duration) error
 	Get(ctx context.Context, key string) (string, error)
+	Del(ctx context.Context, keys ...string) error
+	Eval(ctx context.Context, script string, keys []string, args ...any) (any, error)
+}
+
+type Config struct {
+	// BalanceTTL is how long a cached balance is served without reading the database.
+	BalanceTTL time.Duration
 }
 
 type Repository struct {
 	cache cache
+	cfg   Config
 }
 
-func New(cache cache) *Repository {
+func New(cache cache, cfg Config) *Repository {
 	return &Repository{
 		cache: cache,
+		cfg:   cfg,
 	}
 }
 
@@ -31,20 +42,25 @@ const (
 	insertWalletFn    = "insert wallet"
 	updateWalletFn    = "update wallet"
 	getWalletByUUIDFn = "get wallet by uuid"
+	lockWalletFn      = "get wallet by uuid for update"
 )
 
 func (r Repository) Insert(ctx context.Context, tx pgx.Tx, w *entity.Wallet) error {
 	stmt, args, err := sq.
 		Insert("wallets").
 		Columns(
+			"owner",
 			"amount",
 			"version",
+			"frozen",
 			"created_at",
 			"updated_at",
 		).
 		Values(
+			w.Owner,
 			w.Amount,
 			w.Version,
+			w.Frozen,
 			w.CreatedAt,
 			w.UpdatedAt,
 		).
@@ -66,6 +82,7 @@ func (r Repository) Update(ctx context.Context, tx pgx.Tx, w *entity.Wallet) err
 	stmt, args, err := sq.Update("wallets").
 		Set("amount", w.Amount).
 		Set("version", w.Version+1).
+		Set("frozen", w.Frozen).
 		Set("updated_at", w.UpdatedAt).
 		Where(sq.Eq{
 			"uuid":    w.UUID,
@@ -84,34 +101,55 @@ func (r Repository) Update(ctx context.Context, tx pgx.Tx, w *entity.Wallet) err
 	if res.RowsAffected() == 0 {
 		return ErrNoRowsAffected
 	}
+	w.Version++
 
 	return nil
 }
 
 func (r Repository) GetByUUID(ctx context.Context, tx pgx.Tx, uid uuid.UUID) (*entity.Wallet, error) {
-	stmt, args, err := sq.Select(
+	return r.getByUUID(ctx, tx, uid, false)
+}
+
+// GetByUUIDForUpdate locks the wallet row until the end of the transaction,
+// so concurrent updates of the wallet wait instead of failing the version check.
+func (r Repository) GetByUUIDForUpdate(ctx context.Context, tx pgx.Tx, uid uuid.UUID) (*entity.Wallet, error) {
+	return r.getByUUID(ctx, tx, uid, true)
+}
+
+func (r Repository) getByUUID(ctx context.Context, tx pgx.Tx, uid uuid.UUID, forUpdate bool) (*entity.Wallet, error) {
+	builder := sq.Select(
 		"uuid",
+		"owner",
 		"amount",
 		"version",
+		"frozen",
 		"created_at",
 		"updated_at",
 	).
 		From("wallets").
 		Where(sq.Eq{"uuid": uid}).
-		PlaceholderFormat(sq.Dollar).
-		ToSql()
+		PlaceholderFormat(sq.Dollar)
+
+	fn := getWalletByUUIDFn
+	if forUpdate {
+		builder = builder.Suffix("FOR UPDATE")
+		fn = lockWalletFn
+	}
 
+	stmt, args, err := builder.ToSql()
 	if err != nil {
 		return nil, err
 	}
 
 	w := new(entity.Wallet)
 
-	if err := metrics.Tx().QueryRow(getWalletByUUIDFn, ctx, tx, stmt, args...).
+	if err := metrics.Tx().QueryRow(fn, ctx, tx, stmt, args...).
 		Scan(
 			&w.UUID,
+			&w.Owner,
 			&w.Amount,
 			&w.Version,
+			&w.Frozen,
 			&w.CreatedAt,
 			&w.UpdatedAt,
 		); err != nil {
@@ -124,13 +162,63 @@ func (r Repository) GetByUUID(ctx context.Context, tx pgx.Tx, uid uuid.UUID) (*e
 	return w, nil
 }
 
-func (r Repository) SetBalance(ctx context.Context, uid uuid.UUID, balance int64) error {
-	return r.cache.SetWithTTL(ctx, uid.String(), strconv.FormatInt(balance, 10), time.Second*5)
+const (
+	ownerKeyPrefix = "owner:"
+	ownerTTL       = 24 * time.Hour
+)
+
+func (r Repository) SetOwner(ctx context.Context, uid uuid.UUID, owner string) error {
+	return r.cache.SetWithTTL(ctx, ownerKeyPrefix+uid.String(), owner, ownerTTL)
+}
+
+func (r Repository) GetOwner(ctx context.Context, uid uuid.UUID) (string, error) {
+	return r.cache.Get(ctx, ownerKeyPrefix+uid.String())
 }
+
+const balanceKeyPrefix = "balance:"
+
+// setBalanceScript stores "version:balance" unless the cached entry has the same or a newer version,
+// so a refill that read the wallet before the consumer committed can't overwrite the newer balance.
+const setBalanceScript = `
+local current = redis.call('GET', KEYS[1])
+if current then
+	local version = tonumber(string.match(current, '^(%d+):'))
+	if version and version >= tonumber(ARGV[1]) then
+		return 0
+	end
+end
+redis.call('SET', KEYS[1], ARGV[1] .. ':' .. ARGV[2], 'PX', ARGV[3])
+return 1
+`
+
+// SetBalance caches the balance of the given wallet version.
+func (r Repository) SetBalance(ctx context.Context, uid uuid.UUID, balance, version int64) error {
+	_, err := r.cache.Eval(ctx, setBalanceScript, []string{balanceKeyPrefix + uid.String()},
+		version, balance, r.cfg.BalanceTTL.Milliseconds())
+	return err
+}
+
+// GetBalance returns ErrCacheMiss when the balance isn't cached. Other errors mean redis is unavailable.
 func (r Repository) GetBalance(ctx context.Context, uid uuid.UUID) (int64, error) {
-	res, err := r.cache.Get(ctx, uid.String())
-	if err != nil {
+	res, err := r.cache.Get(ctx, balanceKeyPrefix+uid.String())
+	switch {
+	case errors.Is(err, redis.ErrNotFound):
+		metrics.IncCacheRequests(metrics.CacheLayerRedis, metrics.CacheResultMiss)
+		return 0, ErrCacheMiss
+	case err != nil:
+		metrics.IncCacheRequests(metrics.CacheLayerRedis, metrics.CacheResultError)
 		return 0, err
 	}
-	return strconv.ParseInt(res, 10, 64)
+	metrics.IncCacheRequests(metrics.CacheLayerRedis, metrics.CacheResultHit)
+
+	_, balance, ok := strings.Cut(res, ":")
+	if !ok {
+		return 0, ErrMalformedBalance
+	}
+	return strconv.ParseInt(balance, 10, 64)
+}
+
+// InvalidateBalance drops the cached balance, the next read goes to the database.
+func (r Repository) InvalidateBalance(ctx context.Context, uid uuid.UUID) error {
+	return r.cache.Del(ctx, balanceKeyPrefix+uid.String())
 }
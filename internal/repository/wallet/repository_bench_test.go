@@ -0,0 +1,101 @@
+package wallet_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+	"wallet/config"
+	"wallet/internal/entity"
+	"wallet/internal/infrastructure/database/postgres"
+	"wallet/internal/repository/wallet"
+
+	"github.com/google/uuid"
+	"github.com/ilyakaznacheev/cleanenv"
+	"github.com/jackc/pgx/v5"
+)
+
+// BenchmarkHotWallet compares optimistic and pessimistic locking when every worker updates one wallet.
+// Workers do not share a mutex, like consumers on different replicas. An optimistic conflict is retried
+// right away, in the service it costs a requeue through Kafka on top.
+//
+// It needs a migrated database configured with the DB_* variables:
+//
+//	BENCH_POSTGRES=1 DB_PORT=5432 go test -run ^$ -bench HotWallet ./internal/repository/wallet/
+func BenchmarkHotWallet(b *testing.B) {
+	if os.Getenv("BENCH_POSTGRES") == "" {
+		b.Skip("BENCH_POSTGRES is not set")
+	}
+
+	var cfg config.DBConfig
+	if err := cleanenv.ReadEnv(&cfg); err != nil {
+		b.Fatal(err)
+	}
+
+	ctx := context.Background()
+	store, err := postgres.NewStore(ctx, cfg.Convert())
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer store.Close()
+
+	// the store logs every failed transaction
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	repo := wallet.New(nil, wallet.Config{})
+
+	strategies := []struct {
+		name string
+		load func(context.Context, pgx.Tx, uuid.UUID) (*entity.Wallet, error)
+	}{
+		{"optimistic", repo.GetByUUID},
+		{"pessimistic", repo.GetByUUIDForUpdate},
+	}
+
+	for _, strategy := range strategies {
+		b.Run(strategy.name, func(b *testing.B) {
+			w := entity.NewWallet()
+			if err := store.WithTransact(ctx, func(tx pgx.Tx) error {
+				return repo.Insert(ctx, tx, w)
+			}); err != nil {
+				b.Fatal(err)
+			}
+
+			var conflicts atomic.Int64
+
+			b.SetParallelism(4)
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					for {
+						err := store.WithTransact(ctx, func(tx pgx.Tx) error {
+							current, err := strategy.load(ctx, tx, w.UUID)
+							if err != nil {
+								return err
+							}
+							current.Amount++
+							current.UpdatedAt = time.Now()
+							return repo.Update(ctx, tx, current)
+						})
+						if errors.Is(err, wallet.ErrNoRowsAffected) {
+							conflicts.Add(1)
+							continue
+						}
+						if err != nil {
+							b.Error(err)
+							return
+						}
+						break
+					}
+				}
+			})
+
+			b.ReportMetric(float64(conflicts.Load())/float64(b.N), "conflicts/op")
+		})
+	}
+}
@@ -0,0 +1,504 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	jsoniter "github.com/json-iterator/go"
+	"strings"
+	"time"
+	"wallet/internal/entity"
+	"wallet/internal/utils/metrics"
+)
+
+type Repository struct {
+}
+
+func New() *Repository {
+	return &Repository{}
+}
+
+const (
+	insertWebhookFn      = "insert webhook"
+	getWebhookFn         = "get webhook"
+	listWebhooksFn       = "list webhooks"
+	deleteWebhookFn      = "delete webhook"
+	enableWebhookFn      = "enable webhook"
+	enqueueDeliveriesFn  = "enqueue webhook deliveries"
+	claimDeliveriesFn    = "claim webhook deliveries"
+	completeDeliveryFn   = "complete webhook delivery"
+	insertAttemptFn      = "insert webhook delivery attempt"
+	markWebhookHealthyFn = "mark webhook healthy"
+	markWebhookFailingFn = "mark webhook failing"
+	listDeliveriesFn     = "list webhook deliveries"
+	redeliverDeliveryFn  = "redeliver webhook delivery"
+)
+
+const webhookDeliveriesName = "webhook_deliveries"
+
+var webhookColumns = []string{
+	"id",
+	"subject",
+	"wallet_uuid",
+	"url",
+	"secret",
+	"enabled",
+	"failing_since",
+	"disabled_at",
+	"created_at",
+}
+
+var deliveryColumns = []string{
+	"id",
+	"webhook_id",
+	"event_id",
+	"event_type",
+	"payload",
+	"status",
+	"attempts",
+	"next_attempt_at",
+	"last_status_code",
+	"last_error",
+	"created_at",
+	"delivered_at",
+}
+
+func (r Repository) Insert(ctx context.Context, tx pgx.Tx, w *entity.Webhook) error {
+	stmt, args, err := sq.
+		Insert("webhooks").
+		Columns(
+			"subject",
+			"wallet_uuid",
+			"url",
+			"secret",
+			"enabled",
+			"created_at",
+		).
+		Values(
+			w.Subject,
+			w.WalletUUID,
+			w.URL,
+			w.Secret,
+			w.Enabled,
+			w.CreatedAt,
+		).
+		Suffix("RETURNING \"id\"").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	if err := metrics.Tx().QueryRow(insertWebhookFn, ctx, tx, stmt, args...).Scan(&w.ID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r Repository) GetByID(ctx context.Context, tx pgx.Tx, id uuid.UUID) (*entity.Webhook, error) {
+	stmt, args, err := sq.Select(webhookColumns...).
+		From("webhooks").
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := scanWebhook(metrics.Tx().QueryRow(getWebhookFn, ctx, tx, stmt, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// List returns webhooks of the subject, or every webhook when subject is empty.
+func (r Repository) List(ctx context.Context, tx pgx.Tx, subject string) ([]*entity.Webhook, error) {
+	query := sq.Select(webhookColumns...).
+		From("webhooks").
+		OrderBy("created_at")
+	if subject != "" {
+		query = query.Where(sq.Eq{"subject": subject})
+	}
+
+	stmt, args, err := query.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := metrics.Tx().Query(listWebhooksFn, ctx, tx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := make([]*entity.Webhook, 0)
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, w)
+	}
+
+	return webhooks, rows.Err()
+}
+
+func (r Repository) Delete(ctx context.Context, tx pgx.Tx, id uuid.UUID) error {
+	stmt, args, err := sq.Delete("webhooks").
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	res, err := metrics.Tx().Exec(deleteWebhookFn, ctx, tx, stmt, args...)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// Enable turns an auto-disabled webhook back on. Deliveries failed meanwhile can be redelivered manually.
+func (r Repository) Enable(ctx context.Context, tx pgx.Tx, id uuid.UUID) error {
+	stmt, args, err := sq.Update("webhooks").
+		Set("enabled", true).
+		Set("failing_since", nil).
+		Set("disabled_at", nil).
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	res, err := metrics.Tx().Exec(enableWebhookFn, ctx, tx, stmt, args...)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// Enqueue stores deliveries of the transaction outcome for every matching enabled webhook.
+// It runs in the transaction that commits the outcome, so events are neither lost nor sent for rolled back work.
+func (r Repository) Enqueue(ctx context.Context, tx pgx.Tx, t *entity.Transaction) error {
+	event := entity.NewWebhookEvent(t)
+
+	payload, err := jsoniter.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	match := sq.Or{sq.Eq{"wallet_uuid": t.WalletUUID}}
+	if t.Initiator != "" {
+		match = append(match, sq.And{
+			sq.Eq{"wallet_uuid": nil},
+			sq.Eq{"subject": t.Initiator},
+		})
+	}
+
+	now := time.Now()
+	stmt, args, err := sq.
+		Insert(webhookDeliveriesName).
+		Columns(
+			"webhook_id",
+			"event_id",
+			"event_type",
+			"payload",
+			"status",
+			"next_attempt_at",
+			"created_at",
+		).
+		Select(
+			sq.Select("id").
+				Column(sq.Expr("?::uuid", event.ID)).
+				Column(sq.Expr("?", event.Type)).
+				Column(sq.Expr("?::jsonb", string(payload))).
+				Column(sq.Expr("?", entity.DeliveryPending)).
+				Column(sq.Expr("?::timestamptz", now)).
+				Column(sq.Expr("?::timestamptz", now)).
+				From("webhooks").
+				Where(sq.Eq{"enabled": true}).
+				Where(match),
+		).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = metrics.Tx().Exec(enqueueDeliveriesFn, ctx, tx, stmt, args...)
+	return err
+}
+
+// ClaimedDelivery is a due delivery together with the endpoint it goes to.
+type ClaimedDelivery struct {
+	Delivery *entity.WebhookDelivery
+	URL      string
+	Secret   string
+}
+
+// Claim leases up to limit due deliveries of enabled webhooks by moving their next attempt lease ahead,
+// so other replicas skip them while they are being sent. A crashed sender's deliveries are retried after the lease.
+func (r Repository) Claim(ctx context.Context, tx pgx.Tx, limit uint64, lease time.Duration) ([]ClaimedDelivery, error) {
+	due, dueArgs, err := sq.Select("d.id").
+		From(webhookDeliveriesName + " d").
+		Join("webhooks w ON w.id = d.webhook_id").
+		Where(sq.Eq{"d.status": entity.DeliveryPending}).
+		Where(sq.LtOrEq{"d.next_attempt_at": time.Now()}).
+		Where(sq.Eq{"w.enabled": true}).
+		OrderBy("d.next_attempt_at").
+		Limit(limit).
+		Suffix("FOR UPDATE OF d SKIP LOCKED").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	stmt, args, err := sq.Update(webhookDeliveriesName+" d").
+		Set("next_attempt_at", time.Now().Add(lease)).
+		From("webhooks w").
+		Where("w.id = d.webhook_id").
+		Where("d.id IN ("+due+")", dueArgs...).
+		Suffix("RETURNING " + prefixed("d.", deliveryColumns) + ", w.url, w.secret").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := metrics.Tx().Query(claimDeliveriesFn, ctx, tx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	claimed := make([]ClaimedDelivery, 0)
+	for rows.Next() {
+		var c ClaimedDelivery
+		c.Delivery, err = scanDelivery(rows, &c.URL, &c.Secret)
+		if err != nil {
+			return nil, err
+		}
+		claimed = append(claimed, c)
+	}
+
+	return claimed, rows.Err()
+}
+
+// Complete stores the delivery state after an attempt.
+func (r Repository) Complete(ctx context.Context, tx pgx.Tx, d *entity.WebhookDelivery) error {
+	stmt, args, err := sq.Update(webhookDeliveriesName).
+		Set("status", d.Status).
+		Set("attempts", d.Attempts).
+		Set("next_attempt_at", d.NextAttemptAt).
+		Set("last_status_code", d.LastStatusCode).
+		Set("last_error", d.LastError).
+		Set("delivered_at", d.DeliveredAt).
+		Where(sq.Eq{"id": d.ID}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = metrics.Tx().Exec(completeDeliveryFn, ctx, tx, stmt, args...)
+	return err
+}
+
+// LogAttempt appends an attempt to the delivery log.
+func (r Repository) LogAttempt(ctx context.Context, tx pgx.Tx, deliveryID int64, statusCode *int, attemptErr *string, duration time.Duration) error {
+	stmt, args, err := sq.Insert("webhook_delivery_attempts").
+		Columns(
+			"delivery_id",
+			"status_code",
+			"error",
+			"duration_ms",
+			"attempted_at",
+		).
+		Values(
+			deliveryID,
+			statusCode,
+			attemptErr,
+			duration.Milliseconds(),
+			time.Now(),
+		).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = metrics.Tx().Exec(insertAttemptFn, ctx, tx, stmt, args...)
+	return err
+}
+
+func (r Repository) MarkHealthy(ctx context.Context, tx pgx.Tx, id uuid.UUID) error {
+	stmt, args, err := sq.Update("webhooks").
+		Set("failing_since", nil).
+		Where(sq.Eq{"id": id}).
+		Where(sq.NotEq{"failing_since": nil}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = metrics.Tx().Exec(markWebhookHealthyFn, ctx, tx, stmt, args...)
+	return err
+}
+
+// MarkFailing records that the webhook fails and disables it once it has been failing for longer than disableAfter.
+// It reports whether the webhook got disabled.
+func (r Repository) MarkFailing(ctx context.Context, tx pgx.Tx, id uuid.UUID, disableAfter time.Duration) (bool, error) {
+	now := time.Now()
+	stmt, args, err := sq.Update("webhooks").
+		Set("failing_since", sq.Expr("COALESCE(failing_since, ?::timestamptz)", now)).
+		Set("enabled", sq.Expr("COALESCE(failing_since, ?::timestamptz) > ?::timestamptz", now, now.Add(-disableAfter))).
+		Set("disabled_at", sq.Expr("CASE WHEN COALESCE(failing_since, ?::timestamptz) > ?::timestamptz THEN NULL ELSE ?::timestamptz END", now, now.Add(-disableAfter), now)).
+		Where(sq.Eq{"id": id}).
+		Where(sq.Eq{"enabled": true}).
+		Suffix("RETURNING enabled").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	var enabled bool
+	if err := metrics.Tx().QueryRow(markWebhookFailingFn, ctx, tx, stmt, args...).Scan(&enabled); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return !enabled, nil
+}
+
+// Deliveries returns the latest deliveries of the webhook, newest first.
+func (r Repository) Deliveries(ctx context.Context, tx pgx.Tx, webhookID uuid.UUID, limit uint64) ([]*entity.WebhookDelivery, error) {
+	stmt, args, err := sq.Select(deliveryColumns...).
+		From(webhookDeliveriesName).
+		Where(sq.Eq{"webhook_id": webhookID}).
+		OrderBy("id DESC").
+		Limit(limit).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := metrics.Tx().Query(listDeliveriesFn, ctx, tx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]*entity.WebhookDelivery, 0)
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// Redeliver queues the delivery of the webhook again with a fresh retry budget.
+func (r Repository) Redeliver(ctx context.Context, tx pgx.Tx, webhookID uuid.UUID, deliveryID int64) error {
+	stmt, args, err := sq.Update(webhookDeliveriesName).
+		Set("status", entity.DeliveryPending).
+		Set("attempts", 0).
+		Set("next_attempt_at", time.Now()).
+		Where(sq.Eq{
+			"id":         deliveryID,
+			"webhook_id": webhookID,
+		}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	res, err := metrics.Tx().Exec(redeliverDeliveryFn, ctx, tx, stmt, args...)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return ErrDeliveryNotFound
+	}
+
+	return nil
+}
+
+func scanWebhook(row pgx.Row) (*entity.Webhook, error) {
+	w := new(entity.Webhook)
+
+	if err := row.Scan(
+		&w.ID,
+		&w.Subject,
+		&w.WalletUUID,
+		&w.URL,
+		&w.Secret,
+		&w.Enabled,
+		&w.FailingSince,
+		&w.DisabledAt,
+		&w.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func scanDelivery(row pgx.Row, extra ...any) (*entity.WebhookDelivery, error) {
+	d := new(entity.WebhookDelivery)
+
+	dest := append([]any{
+		&d.ID,
+		&d.WebhookID,
+		&d.EventID,
+		&d.EventType,
+		&d.Payload,
+		&d.Status,
+		&d.Attempts,
+		&d.NextAttemptAt,
+		&d.LastStatusCode,
+		&d.LastError,
+		&d.CreatedAt,
+		&d.DeliveredAt,
+	}, extra...)
+
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func prefixed(prefix string, columns []string) string {
+	res := make([]string, 0, len(columns))
+	for _, c := range columns {
+		res = append(res, prefix+c)
+	}
+	return strings.Join(res, ", ")
+}
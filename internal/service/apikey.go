@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"log"
+	"time"
+	"wallet/internal/entity"
+	apiKeyRepository "wallet/internal/repository/apikey"
+	"wallet/internal/utils/lru"
+)
+
+//go:generate mockery --name apiKeyRepo --structname=APIKeyRepo
+type apiKeyRepo interface {
+	Insert(context.Context, pgx.Tx, *entity.APIKey) error
+	GetByHash(context.Context, pgx.Tx, string) (*entity.APIKey, error)
+	List(context.Context, pgx.Tx) ([]*entity.APIKey, error)
+	Revoke(context.Context, pgx.Tx, uuid.UUID) error
+}
+
+type APIKeyConfig struct {
+	CacheSize int
+	CacheTTL  time.Duration
+	// RejectTTL caches unknown and revoked keys, so repeated attempts with a bad key skip the database.
+	RejectTTL time.Duration
+}
+
+// APIKeyService issues, revokes and authenticates api keys.
+// Authenticated keys are cached in process for CacheTTL, so a revocation
+// takes up to CacheTTL to reach every replica.
+type APIKeyService struct {
+	apiKeyRepo apiKeyRepo
+	store      store
+	keys       *lru.Cache[string, *entity.APIKey]
+	rejected   *lru.Cache[string, struct{}]
+}
+
+func NewAPIKeyService(apiKeyRepo apiKeyRepo, store store, cfg APIKeyConfig) *APIKeyService {
+	return &APIKeyService{
+		apiKeyRepo: apiKeyRepo,
+		store:      store,
+		keys:       lru.New[string, *entity.APIKey](cfg.CacheSize, cfg.CacheTTL),
+		rejected:   lru.New[string, struct{}](cfg.CacheSize, cfg.RejectTTL),
+	}
+}
+
+func (s *APIKeyService) Issue(ctx context.Context, name string, scopes []string) (*entity.APIKey, string, error) {
+	key, raw, err := entity.NewAPIKey(name, scopes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = s.store.WithTransact(ctx, func(tx pgx.Tx) error {
+		return s.apiKeyRepo.Insert(ctx, tx, key)
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return key, raw, nil
+}
+
+func (s *APIKeyService) List(ctx context.Context) ([]*entity.APIKey, error) {
+	var keys []*entity.APIKey
+
+	err := s.store.WithTransact(ctx, func(tx pgx.Tx) error {
+		var err error
+		keys, err = s.apiKeyRepo.List(ctx, tx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (s *APIKeyService) Revoke(ctx context.Context, id uuid.UUID) error {
+	if id == uuid.Nil {
+		return ErrInvalidUUID
+	}
+
+	err := s.store.WithTransact(ctx, func(tx pgx.Tx) error {
+		return s.apiKeyRepo.Revoke(ctx, tx, id)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.keys.DeleteFunc(func(_ string, key *entity.APIKey) bool {
+		return key.ID == id
+	})
+
+	return nil
+}
+
+func (s *APIKeyService) Authenticate(ctx context.Context, raw string) (*entity.Principal, error) {
+	if raw == "" {
+		return nil, ErrUnauthenticated
+	}
+
+	hash := entity.HashAPIKey(raw)
+
+	if key, ok := s.keys.Get(hash); ok {
+		return key.Principal(), nil
+	}
+	if _, ok := s.rejected.Get(hash); ok {
+		return nil, ErrUnauthenticated
+	}
+
+	var key *entity.APIKey
+	err := s.store.WithTransact(ctx, func(tx pgx.Tx) error {
+		var err error
+		key, err = s.apiKeyRepo.GetByHash(ctx, tx, hash)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, apiKeyRepository.ErrAPIKeyNotFound) {
+			s.rejected.Set(hash, struct{}{})
+			return nil, ErrUnauthenticated
+		}
+		log.Println("error while get api key:", err)
+		return nil, err
+	}
+
+	if key.IsRevoked() {
+		s.rejected.Set(hash, struct{}{})
+		return nil, ErrUnauthenticated
+	}
+
+	s.keys.Set(hash, key)
+
+	return key.Principal(), nil
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"wallet/internal/entity"
+	apiKeyRepository "wallet/internal/repository/apikey"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"wallet/internal/service/mocks"
+)
+
+func newTestAPIKeyService(apiKeyRepoMock *mocks.APIKeyRepo, cacheTTL time.Duration) *APIKeyService {
+	storeMock := &mocks.Store{}
+	storeMock.
+		On("WithTransact", mock.Anything, mock.AnythingOfType("func(pgx.Tx) error")).
+		Return(func(_ context.Context, fn func(pgx.Tx) error) error {
+			return fn(&mocks.MockTx{})
+		})
+
+	return NewAPIKeyService(apiKeyRepoMock, storeMock, APIKeyConfig{
+		CacheSize: 10,
+		CacheTTL:  cacheTTL,
+		RejectTTL: cacheTTL,
+	})
+}
+
+func TestAPIKeyService_Authenticate(t *testing.T) {
+	ctx := context.Background()
+	dbErr := errors.New("db is down")
+
+	key, raw, err := entity.NewAPIKey("test", []string{"wallet:read"})
+	require.NoError(t, err)
+	key.ID = uuid.New()
+
+	revoked := *key
+	revoked.Revoke()
+
+	tcs := []struct {
+		name   string
+		raw    string
+		key    *entity.APIKey
+		repErr error
+		err    error
+	}{
+		{"Valid", raw, key, nil, nil},
+		{"Empty", "", nil, nil, ErrUnauthenticated},
+		{"Unknown", raw, nil, apiKeyRepository.ErrAPIKeyNotFound, ErrUnauthenticated},
+		{"Revoked", raw, &revoked, nil, ErrUnauthenticated},
+		{"Database error", raw, nil, dbErr, dbErr},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			apiKeyRepoMock := &mocks.APIKeyRepo{}
+			s := newTestAPIKeyService(apiKeyRepoMock, time.Minute)
+
+			if tc.raw != "" {
+				apiKeyRepoMock.On("GetByHash", ctx, mock.Anything, entity.HashAPIKey(tc.raw)).Return(tc.key, tc.repErr)
+			}
+
+			principal, err := s.Authenticate(ctx, tc.raw)
+			assert.ErrorIs(t, err, tc.err)
+			if tc.err == nil {
+				assert.Equal(t, key.Principal(), principal)
+			}
+			apiKeyRepoMock.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAPIKeyService_AuthenticateCache(t *testing.T) {
+	ctx := context.Background()
+
+	key, raw, err := entity.NewAPIKey("test", []string{"wallet:read"})
+	require.NoError(t, err)
+	_, unknown, err := entity.NewAPIKey("unknown", []string{"wallet:read"})
+	require.NoError(t, err)
+
+	apiKeyRepoMock := &mocks.APIKeyRepo{}
+	s := newTestAPIKeyService(apiKeyRepoMock, 50*time.Millisecond)
+
+	apiKeyRepoMock.On("GetByHash", ctx, mock.Anything, key.Hash).Return(key, nil).Twice()
+	apiKeyRepoMock.On("GetByHash", ctx, mock.Anything, entity.HashAPIKey(unknown)).
+		Return(nil, apiKeyRepository.ErrAPIKeyNotFound).Twice()
+
+	// повторные запросы в пределах TTL не ходят в БД, в том числе с неизвестным ключом
+	for range 3 {
+		_, err := s.Authenticate(ctx, raw)
+		assert.NoError(t, err)
+		_, err = s.Authenticate(ctx, unknown)
+		assert.ErrorIs(t, err, ErrUnauthenticated)
+	}
+	apiKeyRepoMock.AssertNumberOfCalls(t, "GetByHash", 2)
+
+	// после TTL ключи перечитываются
+	time.Sleep(60 * time.Millisecond)
+	_, err = s.Authenticate(ctx, raw)
+	assert.NoError(t, err)
+	_, err = s.Authenticate(ctx, unknown)
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+	apiKeyRepoMock.AssertExpectations(t)
+}
+
+func TestAPIKeyService_Revoke(t *testing.T) {
+	ctx := context.Background()
+
+	key, raw, err := entity.NewAPIKey("test", []string{"wallet:read"})
+	require.NoError(t, err)
+	key.ID = uuid.New()
+
+	revoked := *key
+	revoked.Revoke()
+
+	apiKeyRepoMock := &mocks.APIKeyRepo{}
+	s := newTestAPIKeyService(apiKeyRepoMock, time.Minute)
+
+	apiKeyRepoMock.On("GetByHash", ctx, mock.Anything, key.Hash).Return(key, nil).Once()
+	apiKeyRepoMock.On("Revoke", ctx, mock.Anything, key.ID).Return(nil).Once()
+	apiKeyRepoMock.On("GetByHash", ctx, mock.Anything, key.Hash).Return(&revoked, nil).Once()
+
+	_, err = s.Authenticate(ctx, raw)
+	require.NoError(t, err)
+
+	// отзыв сбрасывает кэш этой реплики сразу, не дожидаясь TTL
+	require.NoError(t, s.Revoke(ctx, key.ID))
+	_, err = s.Authenticate(ctx, raw)
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+
+	assert.ErrorIs(t, s.Revoke(ctx, uuid.Nil), ErrInvalidUUID)
+	apiKeyRepoMock.AssertExpectations(t)
+}
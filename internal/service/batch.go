@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"wallet/internal/entity"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// consumeBatch takes up to BatchSize transactions. It blocks for the first one and
+// waits at most BatchWait for the rest.
+func (s *Service) consumeBatch(ctx context.Context) []consumed {
+	t, ack, err := s.transactionBroker.Consume(ctx)
+	if err != nil {
+		log.Println("error consuming transaction: ", err)
+		return nil
+	}
+
+	batch := make([]consumed, 1, s.workerCfg.BatchSize)
+	batch[0] = consumed{transaction: t, ack: ack}
+
+	waitCtx, cancel := context.WithTimeout(ctx, s.workerCfg.BatchWait)
+	defer cancel()
+
+	for len(batch) < s.workerCfg.BatchSize {
+		t, ack, err := s.transactionBroker.Consume(waitCtx)
+		if err != nil {
+			if waitCtx.Err() == nil {
+				log.Println("error consuming transaction: ", err)
+			}
+			break
+		}
+		batch = append(batch, consumed{transaction: t, ack: ack})
+	}
+
+	return batch
+}
+
+// processBatch applies the transactions of every wallet in the batch together, keeping their order.
+func (s *Service) processBatch(ctx context.Context, batch []consumed) {
+	var wallets []uuid.UUID
+	byWallet := make(map[uuid.UUID][]consumed)
+	for _, c := range batch {
+		uid := c.transaction.WalletUUID
+		if _, ok := byWallet[uid]; !ok {
+			wallets = append(wallets, uid)
+		}
+		byWallet[uid] = append(byWallet[uid], c)
+	}
+
+	for _, uid := range wallets {
+		if cs := byWallet[uid]; len(cs) == 1 {
+			s.settle(ctx, cs[0], s.processTransaction(ctx, cs[0].transaction))
+		} else {
+			s.processWalletBatch(ctx, uid, cs)
+		}
+	}
+}
+
+// appliedTransaction is an operation of a batch together with the wallet state it produced,
+// or the state it was rejected at with the reason.
+type appliedTransaction struct {
+	transaction *entity.Transaction
+	wallet      *entity.Wallet
+	reason      error
+}
+
+// processWalletBatch applies transactions of one wallet in a single database transaction with
+// one wallet update and one insert. Every operation still succeeds or fails on its own: a withdraw
+// lacking funds is recorded as failed and the following operations see the balance without it.
+// If the batch can't be applied, e.g. on a version conflict, the transactions are processed one by one.
+// The transactions are acknowledged once their outcome is committed.
+func (s *Service) processWalletBatch(ctx context.Context, uid uuid.UUID, cs []consumed) {
+	ts := make([]*entity.Transaction, 0, len(cs))
+	keys := make([]uuid.UUID, 0, len(cs))
+	for _, c := range cs {
+		ts = append(ts, c.transaction)
+		keys = append(keys, c.transaction.IdempotencyKey)
+	}
+
+	unlock := s.walletLocks.Lock(uid)
+
+	var (
+		wallet  *entity.Wallet
+		applied []appliedTransaction
+	)
+	err := s.walletTransact(ctx, func(tx pgx.Tx) error {
+		// the store retries serialization failures, state of a rolled back attempt must not leak
+		wallet, applied = nil, applied[:0]
+
+		seen, err := s.transactionRepo.ExistingKeys(ctx, tx, uid, keys)
+		if err != nil {
+			return err
+		}
+		wallet, err = s.lockWallet(ctx, tx, uid)
+		if err != nil {
+			return err
+		}
+
+		current := wallet
+		for _, t := range ts {
+			if _, ok := seen[t.IdempotencyKey]; ok {
+				continue
+			}
+			seen[t.IdempotencyKey] = struct{}{}
+
+			next, err := current.DoTransaction(t)
+			switch {
+			case err == nil:
+				t.StatusSuccess()
+				applied = append(applied, appliedTransaction{transaction: t, wallet: next})
+				current = next
+			case errors.Is(err, entity.ErrNotEnoughFunds) || errors.Is(err, entity.ErrWalletFrozen):
+				t.StatusFailure()
+				applied = append(applied, appliedTransaction{transaction: t, wallet: current, reason: err})
+			default:
+				return err
+			}
+		}
+		if len(applied) == 0 {
+			return nil
+		}
+
+		if current != wallet {
+			if err := s.walletRepo.Update(ctx, tx, current); err != nil {
+				return err
+			}
+			wallet = current
+		}
+
+		transactions := make([]*entity.Transaction, 0, len(applied))
+		for _, a := range applied {
+			transactions = append(transactions, a.transaction)
+		}
+		if err := s.transactionRepo.InsertBatch(ctx, tx, transactions); err != nil {
+			return err
+		}
+
+		for _, t := range transactions {
+			if err := s.enqueueWebhooks(ctx, tx, t); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		unlock()
+		log.Printf("failed to process batch of wallet %v, processing one by one: %v\n", uid, err)
+		for _, c := range cs {
+			c.transaction.StatusNew()
+			s.settle(ctx, c, s.processTransaction(ctx, c.transaction))
+		}
+		return
+	}
+
+	for _, c := range cs {
+		s.settle(ctx, c, nil)
+	}
+
+	changed := false
+	for _, a := range applied {
+		changed = changed || a.reason == nil
+	}
+	if changed {
+		s.cacheBalance(ctx, wallet)
+	}
+
+	unlock()
+
+	if changed {
+		s.publishEvents(ctx, entity.NewBalanceChangedEvent(wallet))
+	}
+	for _, a := range applied {
+		s.publishEvents(ctx, entity.NewTransactionStatusEvent(a.transaction))
+		if a.reason == nil {
+			// operations of the batch are committed with a single wallet version
+			a.wallet.Version = wallet.Version
+			s.publishDomainEvent(ctx, entity.NewOperationSucceededEvent(a.wallet, a.transaction))
+		} else {
+			s.publishDomainEvent(ctx, entity.NewOperationFailedEvent(a.wallet, a.transaction, a.reason))
+		}
+	}
+}
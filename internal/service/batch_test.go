@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"wallet/internal/entity"
+	"wallet/internal/service/mocks"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestService_processWalletBatch(t *testing.T) {
+	ctx := context.Background()
+	uid := uuid.New()
+
+	ts := []*entity.Transaction{
+		{WalletUUID: uid, Operation: entity.Deposit, Amount: 50, Status: entity.New, IdempotencyKey: uuid.New()},
+		{WalletUUID: uid, Operation: entity.Withdraw, Amount: 200, Status: entity.New, IdempotencyKey: uuid.New()},
+		{WalletUUID: uid, Operation: entity.Withdraw, Amount: 100, Status: entity.New, IdempotencyKey: uuid.New()},
+	}
+
+	txMock := &mocks.MockTx{}
+	storeMock := &mocks.Store{}
+	walletRepoMock := &mocks.WalletRepo{}
+	transactionRepoMock := &mocks.TransactionRepo{}
+	walletCacheMock := &mocks.WalletCache{}
+
+	storeMock.
+		On("WithTransact", ctx, mock.AnythingOfType("func(pgx.Tx) error")).
+		Return(func(_ context.Context, fn func(pgx.Tx) error) error { return fn(txMock) }).
+		Once()
+	transactionRepoMock.
+		On("ExistingKeys", ctx, txMock, uid, mock.AnythingOfType("[]uuid.UUID")).
+		Return(map[uuid.UUID]struct{}{}, nil).
+		Once()
+	walletRepoMock.
+		On("GetByUUID", ctx, txMock, uid).
+		Return(&entity.Wallet{UUID: uid, Amount: 100, Version: 3}, nil).
+		Once()
+	walletRepoMock.
+		On("Update", ctx, txMock, mock.MatchedBy(func(w *entity.Wallet) bool { return w.Amount == 50 })).
+		Return(nil).
+		Once()
+	transactionRepoMock.
+		On("InsertBatch", ctx, txMock, ts).
+		Return(nil).
+		Once()
+	walletCacheMock.
+		On("SetBalance", ctx, uid, int64(50), mock.AnythingOfType("int64")).
+		Return(nil).
+		Once()
+
+	service := &Service{
+		walletRepo:      walletRepoMock,
+		transactionRepo: transactionRepoMock,
+		store:           storeMock,
+		walletCache:     walletCacheMock,
+	}
+
+	acked := 0
+	cs := make([]consumed, 0, len(ts))
+	for _, tr := range ts {
+		cs = append(cs, consumed{transaction: tr, ack: func(context.Context) error {
+			acked++
+			return nil
+		}})
+	}
+
+	service.processWalletBatch(ctx, uid, cs)
+
+	// the failed withdraw doesn't stop the operations after it
+	assert.Equal(t, entity.Success, ts[0].Status)
+	assert.Equal(t, entity.Failure, ts[1].Status)
+	assert.Equal(t, entity.Success, ts[2].Status)
+	assert.Equal(t, len(ts), acked)
+
+	storeMock.AssertExpectations(t)
+	walletRepoMock.AssertExpectations(t)
+	transactionRepoMock.AssertExpectations(t)
+	walletCacheMock.AssertExpectations(t)
+}
+
+func TestService_settle(t *testing.T) {
+	acked := 0
+	c := consumed{
+		transaction: &entity.Transaction{IdempotencyKey: uuid.New()},
+		ack: func(context.Context) error {
+			acked++
+			return nil
+		},
+	}
+	service := &Service{}
+
+	// a transaction whose outcome was not stored is left for redelivery
+	service.settle(context.Background(), c, assert.AnError)
+	assert.Equal(t, 0, acked)
+
+	service.settle(context.Background(), c, nil)
+	assert.Equal(t, 1, acked)
+}
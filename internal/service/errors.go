@@ -3,5 +3,8 @@ package service
 import "errors"
 
 var (
-	ErrInvalidUUID = errors.New("invalid uuid")
+	ErrInvalidUUID     = errors.New("invalid uuid")
+	ErrUnauthenticated = errors.New("unauthenticated")
+	ErrForbidden       = errors.New("forbidden")
+	ErrUnknownLocking  = errors.New("unknown locking mode")
 )
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"strconv"
+	"strings"
+	"sync"
+	"wallet/internal/entity"
+	"wallet/internal/utils/metrics"
+)
+
+//go:generate mockery --name eventSource --structname=EventSource
+type eventSource interface {
+	History(ctx context.Context, walletUUID uuid.UUID, afterID string) ([]*entity.WalletEvent, error)
+	Listen(ctx context.Context) <-chan *entity.WalletEvent
+}
+
+// EventHub fans wallet events received by this replica out to local subscribers.
+type EventHub struct {
+	source      eventSource
+	bufferSize  int
+	mu          sync.Mutex
+	subscribers map[uuid.UUID]map[*subscriber]struct{}
+}
+
+type subscriber struct {
+	events chan *entity.WalletEvent
+}
+
+// NewEventHub starts listening to the source until ctx is done.
+// Each subscriber may fall behind by bufferSize events before it is disconnected.
+func NewEventHub(ctx context.Context, source eventSource, bufferSize int) *EventHub {
+	h := &EventHub{
+		source:      source,
+		bufferSize:  bufferSize,
+		subscribers: make(map[uuid.UUID]map[*subscriber]struct{}),
+	}
+
+	go h.run(ctx)
+
+	return h
+}
+
+func (h *EventHub) run(ctx context.Context) {
+	for e := range h.source.Listen(ctx) {
+		h.dispatch(e)
+	}
+}
+
+func (h *EventHub) dispatch(e *entity.WalletEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subscribers[e.WalletUUID] {
+		select {
+		case sub.events <- e:
+		default:
+			h.remove(e.WalletUUID, sub)
+			metrics.IncEventSubscribersDropped()
+		}
+	}
+}
+
+// Subscribe streams events of the wallet until ctx is done. When lastEventID is set,
+// events published after it and still kept in the history are replayed first.
+// The channel is also closed when the subscriber falls behind, the client is expected
+// to subscribe again with the id of the last received event.
+func (h *EventHub) Subscribe(ctx context.Context, walletUUID uuid.UUID, lastEventID string) (<-chan *entity.WalletEvent, error) {
+	if uuid.Nil == walletUUID {
+		return nil, ErrInvalidUUID
+	}
+	if _, _, ok := parseEventID(lastEventID); !ok {
+		lastEventID = ""
+	}
+
+	sub := &subscriber{events: make(chan *entity.WalletEvent, h.bufferSize)}
+
+	// register before reading the history, so nothing published in between is lost
+	h.mu.Lock()
+	if h.subscribers[walletUUID] == nil {
+		h.subscribers[walletUUID] = make(map[*subscriber]struct{})
+	}
+	h.subscribers[walletUUID][sub] = struct{}{}
+	h.mu.Unlock()
+	metrics.IncEventSubscribers()
+
+	unsubscribe := func() {
+		h.mu.Lock()
+		h.remove(walletUUID, sub)
+		h.mu.Unlock()
+		metrics.DecEventSubscribers()
+	}
+
+	var history []*entity.WalletEvent
+	if lastEventID != "" {
+		var err error
+		history, err = h.source.History(ctx, walletUUID, lastEventID)
+		if err != nil {
+			unsubscribe()
+			return nil, err
+		}
+	}
+
+	out := make(chan *entity.WalletEvent)
+
+	go func() {
+		defer close(out)
+		defer unsubscribe()
+
+		last := lastEventID
+		send := func(e *entity.WalletEvent) bool {
+			// live events may repeat the tail of the history
+			if last != "" && !eventIDAfter(e.ID, last) {
+				return true
+			}
+			select {
+			case out <- e:
+				last = e.ID
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for _, e := range history {
+			if !send(e) {
+				return
+			}
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e, ok := <-sub.events:
+				if !ok || !send(e) {
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// remove must be called with mu held. It is safe to call for an already removed subscriber.
+func (h *EventHub) remove(walletUUID uuid.UUID, sub *subscriber) {
+	subs := h.subscribers[walletUUID]
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(h.subscribers, walletUUID)
+	}
+	close(sub.events)
+}
+
+// parseEventID splits a redis stream id of the form "<ms>-<seq>".
+func parseEventID(id string) (uint64, uint64, bool) {
+	msPart, seqPart, ok := strings.Cut(id, "-")
+	if !ok {
+		return 0, 0, false
+	}
+	ms, err := strconv.ParseUint(msPart, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	seq, err := strconv.ParseUint(seqPart, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return ms, seq, true
+}
+
+func eventIDAfter(id, other string) bool {
+	ms, seq, ok := parseEventID(id)
+	if !ok {
+		return false
+	}
+	otherMs, otherSeq, ok := parseEventID(other)
+	if !ok {
+		return true
+	}
+	return ms > otherMs || (ms == otherMs && seq > otherSeq)
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"testing"
+	"time"
+	"wallet/internal/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"wallet/internal/service/mocks"
+)
+
+func collect(t *testing.T, events <-chan *entity.WalletEvent, n int) []string {
+	t.Helper()
+
+	ids := make([]string, 0, n)
+	for len(ids) < n {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return ids
+			}
+			ids = append(ids, e.ID)
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for events, got %v", ids)
+		}
+	}
+	return ids
+}
+
+func TestEventHub_Subscribe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	walletUUID := uuid.New()
+	live := make(chan *entity.WalletEvent)
+
+	sourceMock := &mocks.EventSource{}
+	sourceMock.On("Listen", ctx).Return((<-chan *entity.WalletEvent)(live))
+	sourceMock.On("History", mock.Anything, walletUUID, "1-0").Return([]*entity.WalletEvent{
+		{ID: "2-0", WalletUUID: walletUUID},
+		{ID: "3-0", WalletUUID: walletUUID},
+	}, nil)
+
+	hub := NewEventHub(ctx, sourceMock, 10)
+
+	events, err := hub.Subscribe(ctx, walletUUID, "1-0")
+	require.NoError(t, err)
+
+	// the tail of the history is published again, events of other wallets are not delivered
+	live <- &entity.WalletEvent{ID: "3-0", WalletUUID: walletUUID}
+	live <- &entity.WalletEvent{ID: "4-0", WalletUUID: uuid.New()}
+	live <- &entity.WalletEvent{ID: "5-0", WalletUUID: walletUUID}
+
+	assert.Equal(t, []string{"2-0", "3-0", "5-0"}, collect(t, events, 3))
+}
+
+func TestEventHub_SlowSubscriber(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	walletUUID := uuid.New()
+	live := make(chan *entity.WalletEvent)
+
+	sourceMock := &mocks.EventSource{}
+	sourceMock.On("Listen", ctx).Return((<-chan *entity.WalletEvent)(live))
+
+	hub := NewEventHub(ctx, sourceMock, 1)
+
+	events, err := hub.Subscribe(ctx, walletUUID, "")
+	require.NoError(t, err)
+
+	for i := range 5 {
+		live <- &entity.WalletEvent{ID: fmt.Sprintf("%d-0", i+1), WalletUUID: walletUUID}
+	}
+
+	// the subscriber gets what fitted into the buffer and is disconnected
+	ids := collect(t, events, 5)
+	assert.Less(t, len(ids), 5)
+
+	hub.mu.Lock()
+	assert.Empty(t, hub.subscribers)
+	hub.mu.Unlock()
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"github.com/google/uuid"
+	"sync"
+	"wallet/internal/utils/metrics"
+)
+
+// walletLock is a mutex shared by the workers processing one wallet.
+type walletLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// lockTable serializes work on a wallet within the replica. An entry lives only while some
+// worker holds or waits for the lock, so the table is bounded by the number of workers.
+// The zero value is ready to use.
+type lockTable struct {
+	mu    sync.Mutex
+	locks map[uuid.UUID]*walletLock
+}
+
+// Lock blocks until the wallet is free and returns the function releasing it.
+func (t *lockTable) Lock(uid uuid.UUID) (unlock func()) {
+	t.mu.Lock()
+	if t.locks == nil {
+		t.locks = make(map[uuid.UUID]*walletLock)
+	}
+	l, ok := t.locks[uid]
+	if !ok {
+		l = &walletLock{}
+		t.locks[uid] = l
+		metrics.IncWalletLocks()
+	}
+	l.refs++
+	t.mu.Unlock()
+
+	l.mu.Lock()
+
+	return func() {
+		l.mu.Unlock()
+
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		l.refs--
+		if l.refs == 0 {
+			delete(t.locks, uid)
+			metrics.DecWalletLocks()
+		}
+	}
+}
+
+// Len returns the number of wallets currently locked or awaited.
+func (t *lockTable) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.locks)
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLockTable_Stress runs many workers on a few hot wallets, run it with -race.
+func TestLockTable_Stress(t *testing.T) {
+	const (
+		workers    = 64
+		iterations = 500
+	)
+
+	wallets := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	holders := make([]atomic.Int32, len(wallets))
+	counters := make([]int, len(wallets))
+
+	var table lockTable
+	var wg sync.WaitGroup
+
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range iterations {
+				idx := (w + i) % len(wallets)
+
+				unlock := table.Lock(wallets[idx])
+				if n := holders[idx].Add(1); n != 1 {
+					t.Errorf("wallet %d is held by %d workers", idx, n)
+				}
+				// not atomic on purpose, the race detector reports unsynchronized access
+				counters[idx]++
+				holders[idx].Add(-1)
+				unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	total := 0
+	for _, c := range counters {
+		total += c
+	}
+	assert.Equal(t, workers*iterations, total)
+	assert.Zero(t, table.Len(), "released locks must be evicted")
+}
+
+func TestLockTable_Evict(t *testing.T) {
+	var table lockTable
+	uid := uuid.New()
+
+	unlock := table.Lock(uid)
+	assert.Equal(t, 1, table.Len())
+
+	acquired := make(chan struct{})
+	go func() {
+		unlockWaiter := table.Lock(uid)
+		close(acquired)
+		unlockWaiter()
+	}()
+
+	unlock()
+	<-acquired
+
+	assert.Eventually(t, func() bool { return table.Len() == 0 }, time.Second, time.Millisecond)
+}
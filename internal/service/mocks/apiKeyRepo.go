@@ -0,0 +1,129 @@
+// Code generated by mockery v2.52.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	entity "wallet/internal/entity"
+
+	mock "github.com/stretchr/testify/mock"
+
+	pgx "github.com/jackc/pgx/v5"
+
+	uuid "github.com/google/uuid"
+)
+
+// APIKeyRepo is an autogenerated mock type for the apiKeyRepo type
+type APIKeyRepo struct {
+	mock.Mock
+}
+
+// GetByHash provides a mock function with given fields: _a0, _a1, _a2
+func (_m *APIKeyRepo) GetByHash(_a0 context.Context, _a1 pgx.Tx, _a2 string) (*entity.APIKey, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByHash")
+	}
+
+	var r0 *entity.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, pgx.Tx, string) (*entity.APIKey, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, pgx.Tx, string) *entity.APIKey); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, pgx.Tx, string) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Insert provides a mock function with given fields: _a0, _a1, _a2
+func (_m *APIKeyRepo) Insert(_a0 context.Context, _a1 pgx.Tx, _a2 *entity.APIKey) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Insert")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, pgx.Tx, *entity.APIKey) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// List provides a mock function with given fields: _a0, _a1
+func (_m *APIKeyRepo) List(_a0 context.Context, _a1 pgx.Tx) ([]*entity.APIKey, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []*entity.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, pgx.Tx) ([]*entity.APIKey, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, pgx.Tx) []*entity.APIKey); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, pgx.Tx) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Revoke provides a mock function with given fields: _a0, _a1, _a2
+func (_m *APIKeyRepo) Revoke(_a0 context.Context, _a1 pgx.Tx, _a2 uuid.UUID) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Revoke")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, pgx.Tx, uuid.UUID) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewAPIKeyRepo creates a new instance of APIKeyRepo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAPIKeyRepo(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *APIKeyRepo {
+	mock := &APIKeyRepo{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
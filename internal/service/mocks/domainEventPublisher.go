@@ -0,0 +1,47 @@
+// Code generated by mockery v2.52.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	entity "wallet/internal/entity"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// DomainEventPublisher is an autogenerated mock type for the domainEventPublisher type
+type DomainEventPublisher struct {
+	mock.Mock
+}
+
+// Publish provides a mock function with given fields: _a0, _a1
+func (_m *DomainEventPublisher) Publish(_a0 context.Context, _a1 *entity.DomainEvent) error {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Publish")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.DomainEvent) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewDomainEventPublisher creates a new instance of DomainEventPublisher. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewDomainEventPublisher(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *DomainEventPublisher {
+	mock := &DomainEventPublisher{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,81 @@
+// Code generated by mockery v2.52.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	entity "wallet/internal/entity"
+
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// EventSource is an autogenerated mock type for the eventSource type
+type EventSource struct {
+	mock.Mock
+}
+
+// History provides a mock function with given fields: ctx, walletUUID, afterID
+func (_m *EventSource) History(ctx context.Context, walletUUID uuid.UUID, afterID string) ([]*entity.WalletEvent, error) {
+	ret := _m.Called(ctx, walletUUID, afterID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for History")
+	}
+
+	var r0 []*entity.WalletEvent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string) ([]*entity.WalletEvent, error)); ok {
+		return rf(ctx, walletUUID, afterID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string) []*entity.WalletEvent); ok {
+		r0 = rf(ctx, walletUUID, afterID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.WalletEvent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, string) error); ok {
+		r1 = rf(ctx, walletUUID, afterID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Listen provides a mock function with given fields: ctx
+func (_m *EventSource) Listen(ctx context.Context) <-chan *entity.WalletEvent {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Listen")
+	}
+
+	var r0 <-chan *entity.WalletEvent
+	if rf, ok := ret.Get(0).(func(context.Context) <-chan *entity.WalletEvent); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan *entity.WalletEvent)
+		}
+	}
+
+	return r0
+}
+
+// NewEventSource creates a new instance of EventSource. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewEventSource(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *EventSource {
+	mock := &EventSource{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -32,6 +32,24 @@ func (_m *Store) WithTransact(_a0 context.Context, _a1 func(pgx.Tx) error) error
 	return r0
 }
 
+// WithTxOptions provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Store) WithTxOptions(_a0 context.Context, _a1 pgx.TxOptions, _a2 func(pgx.Tx) error) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WithTxOptions")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, pgx.TxOptions, func(pgx.Tx) error) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewStore creates a new instance of Store. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewStore(t interface {
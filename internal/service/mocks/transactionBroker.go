@@ -7,6 +7,8 @@ import (
 	entity "wallet/internal/entity"
 
 	mock "github.com/stretchr/testify/mock"
+
+	transaction "wallet/internal/repository/transaction"
 )
 
 // TransactionBroker is an autogenerated mock type for the transactionBroker type
@@ -15,7 +17,7 @@ type TransactionBroker struct {
 }
 
 // Consume provides a mock function with given fields: _a0
-func (_m *TransactionBroker) Consume(_a0 context.Context) (*entity.Transaction, error) {
+func (_m *TransactionBroker) Consume(_a0 context.Context) (*entity.Transaction, transaction.Ack, error) {
 	ret := _m.Called(_a0)
 
 	if len(ret) == 0 {
@@ -23,8 +25,9 @@ func (_m *TransactionBroker) Consume(_a0 context.Context) (*entity.Transaction,
 	}
 
 	var r0 *entity.Transaction
-	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context) (*entity.Transaction, error)); ok {
+	var r1 transaction.Ack
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*entity.Transaction, transaction.Ack, error)); ok {
 		return rf(_a0)
 	}
 	if rf, ok := ret.Get(0).(func(context.Context) *entity.Transaction); ok {
@@ -35,13 +38,21 @@ func (_m *TransactionBroker) Consume(_a0 context.Context) (*entity.Transaction,
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context) transaction.Ack); ok {
 		r1 = rf(_a0)
 	} else {
-		r1 = ret.Error(1)
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(transaction.Ack)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context) error); ok {
+		r2 = rf(_a0)
+	} else {
+		r2 = ret.Error(2)
 	}
 
-	return r0, r1
+	return r0, r1, r2
 }
 
 // Publish provides a mock function with given fields: _a0, _a1
@@ -9,6 +9,8 @@ import (
 	mock "github.com/stretchr/testify/mock"
 
 	pgx "github.com/jackc/pgx/v5"
+
+	uuid "github.com/google/uuid"
 )
 
 // TransactionRepo is an autogenerated mock type for the transactionRepo type
@@ -16,6 +18,36 @@ type TransactionRepo struct {
 	mock.Mock
 }
 
+// ExistingKeys provides a mock function with given fields: ctx, tx, walletUUID, keys
+func (_m *TransactionRepo) ExistingKeys(ctx context.Context, tx pgx.Tx, walletUUID uuid.UUID, keys []uuid.UUID) (map[uuid.UUID]struct{}, error) {
+	ret := _m.Called(ctx, tx, walletUUID, keys)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExistingKeys")
+	}
+
+	var r0 map[uuid.UUID]struct{}
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, pgx.Tx, uuid.UUID, []uuid.UUID) (map[uuid.UUID]struct{}, error)); ok {
+		return rf(ctx, tx, walletUUID, keys)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, pgx.Tx, uuid.UUID, []uuid.UUID) map[uuid.UUID]struct{}); ok {
+		r0 = rf(ctx, tx, walletUUID, keys)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[uuid.UUID]struct{})
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, pgx.Tx, uuid.UUID, []uuid.UUID) error); ok {
+		r1 = rf(ctx, tx, walletUUID, keys)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Exists provides a mock function with given fields: _a0, _a1, _a2
 func (_m *TransactionRepo) Exists(_a0 context.Context, _a1 pgx.Tx, _a2 *entity.Transaction) (bool, error) {
 	ret := _m.Called(_a0, _a1, _a2)
@@ -44,6 +76,36 @@ func (_m *TransactionRepo) Exists(_a0 context.Context, _a1 pgx.Tx, _a2 *entity.T
 	return r0, r1
 }
 
+// GetByIdempotencyKey provides a mock function with given fields: _a0, _a1, _a2
+func (_m *TransactionRepo) GetByIdempotencyKey(_a0 context.Context, _a1 pgx.Tx, _a2 uuid.UUID) (*entity.Transaction, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByIdempotencyKey")
+	}
+
+	var r0 *entity.Transaction
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, pgx.Tx, uuid.UUID) (*entity.Transaction, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, pgx.Tx, uuid.UUID) *entity.Transaction); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Transaction)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, pgx.Tx, uuid.UUID) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Insert provides a mock function with given fields: _a0, _a1, _a2
 func (_m *TransactionRepo) Insert(_a0 context.Context, _a1 pgx.Tx, _a2 *entity.Transaction) error {
 	ret := _m.Called(_a0, _a1, _a2)
@@ -62,6 +124,24 @@ func (_m *TransactionRepo) Insert(_a0 context.Context, _a1 pgx.Tx, _a2 *entity.T
 	return r0
 }
 
+// InsertBatch provides a mock function with given fields: _a0, _a1, _a2
+func (_m *TransactionRepo) InsertBatch(_a0 context.Context, _a1 pgx.Tx, _a2 []*entity.Transaction) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertBatch")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, pgx.Tx, []*entity.Transaction) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewTransactionRepo creates a new instance of TransactionRepo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewTransactionRepo(t interface {
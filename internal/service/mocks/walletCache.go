@@ -43,16 +43,80 @@ func (_m *WalletCache) GetBalance(_a0 context.Context, _a1 uuid.UUID) (int64, er
 	return r0, r1
 }
 
-// SetBalance provides a mock function with given fields: _a0, _a1, _a2
-func (_m *WalletCache) SetBalance(_a0 context.Context, _a1 uuid.UUID, _a2 int64) error {
-	ret := _m.Called(_a0, _a1, _a2)
+// GetOwner provides a mock function with given fields: _a0, _a1
+func (_m *WalletCache) GetOwner(_a0 context.Context, _a1 uuid.UUID) (string, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOwner")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (string, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) string); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InvalidateBalance provides a mock function with given fields: _a0, _a1
+func (_m *WalletCache) InvalidateBalance(_a0 context.Context, _a1 uuid.UUID) error {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InvalidateBalance")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetBalance provides a mock function with given fields: ctx, uid, balance, version
+func (_m *WalletCache) SetBalance(ctx context.Context, uid uuid.UUID, balance int64, version int64) error {
+	ret := _m.Called(ctx, uid, balance, version)
 
 	if len(ret) == 0 {
 		panic("no return value specified for SetBalance")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, int64) error); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, int64, int64) error); ok {
+		r0 = rf(ctx, uid, balance, version)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetOwner provides a mock function with given fields: _a0, _a1, _a2
+func (_m *WalletCache) SetOwner(_a0 context.Context, _a1 uuid.UUID, _a2 string) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetOwner")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string) error); ok {
 		r0 = rf(_a0, _a1, _a2)
 	} else {
 		r0 = ret.Error(0)
@@ -48,6 +48,36 @@ func (_m *WalletRepo) GetByUUID(_a0 context.Context, _a1 pgx.Tx, _a2 uuid.UUID)
 	return r0, r1
 }
 
+// GetByUUIDForUpdate provides a mock function with given fields: _a0, _a1, _a2
+func (_m *WalletRepo) GetByUUIDForUpdate(_a0 context.Context, _a1 pgx.Tx, _a2 uuid.UUID) (*entity.Wallet, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByUUIDForUpdate")
+	}
+
+	var r0 *entity.Wallet
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, pgx.Tx, uuid.UUID) (*entity.Wallet, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, pgx.Tx, uuid.UUID) *entity.Wallet); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Wallet)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, pgx.Tx, uuid.UUID) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Insert provides a mock function with given fields: _a0, _a1, _a2
 func (_m *WalletRepo) Insert(_a0 context.Context, _a1 pgx.Tx, _a2 *entity.Wallet) error {
 	ret := _m.Called(_a0, _a1, _a2)
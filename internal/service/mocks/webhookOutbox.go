@@ -0,0 +1,49 @@
+// Code generated by mockery v2.52.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	entity "wallet/internal/entity"
+
+	mock "github.com/stretchr/testify/mock"
+
+	pgx "github.com/jackc/pgx/v5"
+)
+
+// WebhookOutbox is an autogenerated mock type for the webhookOutbox type
+type WebhookOutbox struct {
+	mock.Mock
+}
+
+// Enqueue provides a mock function with given fields: _a0, _a1, _a2
+func (_m *WebhookOutbox) Enqueue(_a0 context.Context, _a1 pgx.Tx, _a2 *entity.Transaction) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Enqueue")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, pgx.Tx, *entity.Transaction) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewWebhookOutbox creates a new instance of WebhookOutbox. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewWebhookOutbox(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *WebhookOutbox {
+	mock := &WebhookOutbox{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
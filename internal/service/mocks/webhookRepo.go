@@ -0,0 +1,311 @@
+// Code generated by mockery v2.52.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	entity "wallet/internal/entity"
+
+	mock "github.com/stretchr/testify/mock"
+
+	pgx "github.com/jackc/pgx/v5"
+
+	time "time"
+
+	uuid "github.com/google/uuid"
+
+	webhook "wallet/internal/repository/webhook"
+)
+
+// WebhookRepo is an autogenerated mock type for the webhookRepo type
+type WebhookRepo struct {
+	mock.Mock
+}
+
+// Claim provides a mock function with given fields: ctx, tx, limit, lease
+func (_m *WebhookRepo) Claim(ctx context.Context, tx pgx.Tx, limit uint64, lease time.Duration) ([]webhook.ClaimedDelivery, error) {
+	ret := _m.Called(ctx, tx, limit, lease)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Claim")
+	}
+
+	var r0 []webhook.ClaimedDelivery
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, pgx.Tx, uint64, time.Duration) ([]webhook.ClaimedDelivery, error)); ok {
+		return rf(ctx, tx, limit, lease)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, pgx.Tx, uint64, time.Duration) []webhook.ClaimedDelivery); ok {
+		r0 = rf(ctx, tx, limit, lease)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]webhook.ClaimedDelivery)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, pgx.Tx, uint64, time.Duration) error); ok {
+		r1 = rf(ctx, tx, limit, lease)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Complete provides a mock function with given fields: _a0, _a1, _a2
+func (_m *WebhookRepo) Complete(_a0 context.Context, _a1 pgx.Tx, _a2 *entity.WebhookDelivery) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Complete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, pgx.Tx, *entity.WebhookDelivery) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Delete provides a mock function with given fields: _a0, _a1, _a2
+func (_m *WebhookRepo) Delete(_a0 context.Context, _a1 pgx.Tx, _a2 uuid.UUID) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, pgx.Tx, uuid.UUID) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Deliveries provides a mock function with given fields: ctx, tx, webhookID, limit
+func (_m *WebhookRepo) Deliveries(ctx context.Context, tx pgx.Tx, webhookID uuid.UUID, limit uint64) ([]*entity.WebhookDelivery, error) {
+	ret := _m.Called(ctx, tx, webhookID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Deliveries")
+	}
+
+	var r0 []*entity.WebhookDelivery
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, pgx.Tx, uuid.UUID, uint64) ([]*entity.WebhookDelivery, error)); ok {
+		return rf(ctx, tx, webhookID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, pgx.Tx, uuid.UUID, uint64) []*entity.WebhookDelivery); ok {
+		r0 = rf(ctx, tx, webhookID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.WebhookDelivery)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, pgx.Tx, uuid.UUID, uint64) error); ok {
+		r1 = rf(ctx, tx, webhookID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Enable provides a mock function with given fields: _a0, _a1, _a2
+func (_m *WebhookRepo) Enable(_a0 context.Context, _a1 pgx.Tx, _a2 uuid.UUID) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Enable")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, pgx.Tx, uuid.UUID) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetByID provides a mock function with given fields: _a0, _a1, _a2
+func (_m *WebhookRepo) GetByID(_a0 context.Context, _a1 pgx.Tx, _a2 uuid.UUID) (*entity.Webhook, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByID")
+	}
+
+	var r0 *entity.Webhook
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, pgx.Tx, uuid.UUID) (*entity.Webhook, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, pgx.Tx, uuid.UUID) *entity.Webhook); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Webhook)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, pgx.Tx, uuid.UUID) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Insert provides a mock function with given fields: _a0, _a1, _a2
+func (_m *WebhookRepo) Insert(_a0 context.Context, _a1 pgx.Tx, _a2 *entity.Webhook) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Insert")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, pgx.Tx, *entity.Webhook) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// List provides a mock function with given fields: ctx, tx, subject
+func (_m *WebhookRepo) List(ctx context.Context, tx pgx.Tx, subject string) ([]*entity.Webhook, error) {
+	ret := _m.Called(ctx, tx, subject)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []*entity.Webhook
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, pgx.Tx, string) ([]*entity.Webhook, error)); ok {
+		return rf(ctx, tx, subject)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, pgx.Tx, string) []*entity.Webhook); ok {
+		r0 = rf(ctx, tx, subject)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.Webhook)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, pgx.Tx, string) error); ok {
+		r1 = rf(ctx, tx, subject)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LogAttempt provides a mock function with given fields: ctx, tx, deliveryID, statusCode, attemptErr, duration
+func (_m *WebhookRepo) LogAttempt(ctx context.Context, tx pgx.Tx, deliveryID int64, statusCode *int, attemptErr *string, duration time.Duration) error {
+	ret := _m.Called(ctx, tx, deliveryID, statusCode, attemptErr, duration)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LogAttempt")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, pgx.Tx, int64, *int, *string, time.Duration) error); ok {
+		r0 = rf(ctx, tx, deliveryID, statusCode, attemptErr, duration)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MarkFailing provides a mock function with given fields: ctx, tx, id, disableAfter
+func (_m *WebhookRepo) MarkFailing(ctx context.Context, tx pgx.Tx, id uuid.UUID, disableAfter time.Duration) (bool, error) {
+	ret := _m.Called(ctx, tx, id, disableAfter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkFailing")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, pgx.Tx, uuid.UUID, time.Duration) (bool, error)); ok {
+		return rf(ctx, tx, id, disableAfter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, pgx.Tx, uuid.UUID, time.Duration) bool); ok {
+		r0 = rf(ctx, tx, id, disableAfter)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, pgx.Tx, uuid.UUID, time.Duration) error); ok {
+		r1 = rf(ctx, tx, id, disableAfter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkHealthy provides a mock function with given fields: _a0, _a1, _a2
+func (_m *WebhookRepo) MarkHealthy(_a0 context.Context, _a1 pgx.Tx, _a2 uuid.UUID) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkHealthy")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, pgx.Tx, uuid.UUID) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Redeliver provides a mock function with given fields: ctx, tx, webhookID, deliveryID
+func (_m *WebhookRepo) Redeliver(ctx context.Context, tx pgx.Tx, webhookID uuid.UUID, deliveryID int64) error {
+	ret := _m.Called(ctx, tx, webhookID, deliveryID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Redeliver")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, pgx.Tx, uuid.UUID, int64) error); ok {
+		r0 = rf(ctx, tx, webhookID, deliveryID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewWebhookRepo creates a new instance of WebhookRepo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewWebhookRepo(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *WebhookRepo {
+	mock := &WebhookRepo{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,58 @@
+// Code generated by mockery v2.52.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// WebhookSender is an autogenerated mock type for the webhookSender type
+type WebhookSender struct {
+	mock.Mock
+}
+
+// Send provides a mock function with given fields: ctx, url, secret, eventID, payload
+func (_m *WebhookSender) Send(ctx context.Context, url string, secret string, eventID uuid.UUID, payload []byte) (int, error) {
+	ret := _m.Called(ctx, url, secret, eventID, payload)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Send")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, uuid.UUID, []byte) (int, error)); ok {
+		return rf(ctx, url, secret, eventID, payload)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, uuid.UUID, []byte) int); ok {
+		r0 = rf(ctx, url, secret, eventID, payload)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, uuid.UUID, []byte) error); ok {
+		r1 = rf(ctx, url, secret, eventID, payload)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewWebhookSender creates a new instance of WebhookSender. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewWebhookSender(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *WebhookSender {
+	mock := &WebhookSender{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -3,10 +3,12 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"golang.org/x/sync/singleflight"
 	"log"
-	"sync"
+	"time"
 	"wallet/internal/entity"
 	transactionRepository "wallet/internal/repository/transaction"
 	walletRepository "wallet/internal/repository/wallet"
@@ -18,41 +20,71 @@ type walletRepo interface {
 	Update(context.Context, pgx.Tx, *entity.Wallet) error
 
 	GetByUUID(context.Context, pgx.Tx, uuid.UUID) (*entity.Wallet, error)
+	GetByUUIDForUpdate(context.Context, pgx.Tx, uuid.UUID) (*entity.Wallet, error)
 }
 
 //go:generate mockery --name walletCache --structname=WalletCache
 type walletCache interface {
-	SetBalance(context.Context, uuid.UUID, int64) error
+	SetBalance(ctx context.Context, uid uuid.UUID, balance, version int64) error
 	GetBalance(context.Context, uuid.UUID) (int64, error)
+	InvalidateBalance(context.Context, uuid.UUID) error
+	SetOwner(context.Context, uuid.UUID, string) error
+	GetOwner(context.Context, uuid.UUID) (string, error)
 }
 
 //go:generate mockery --name transactionRepo --structname=TransactionRepo
 type transactionRepo interface {
 	Insert(context.Context, pgx.Tx, *entity.Transaction) error
+	InsertBatch(context.Context, pgx.Tx, []*entity.Transaction) error
 	Exists(context.Context, pgx.Tx, *entity.Transaction) (bool, error)
+	ExistingKeys(ctx context.Context, tx pgx.Tx, walletUUID uuid.UUID, keys []uuid.UUID) (map[uuid.UUID]struct{}, error)
+	GetByIdempotencyKey(context.Context, pgx.Tx, uuid.UUID) (*entity.Transaction, error)
 }
 
 //go:generate mockery --name transactionBroker --structname=TransactionBroker
 type transactionBroker interface {
 	Publish(context.Context, *entity.Transaction) error
-	Consume(context.Context) (*entity.Transaction, error)
+	Consume(context.Context) (*entity.Transaction, transactionRepository.Ack, error)
+}
+
+//go:generate mockery --name eventPublisher --structname=EventPublisher
+type eventPublisher interface {
+	Publish(context.Context, *entity.WalletEvent) error
+}
+
+//go:generate mockery --name domainEventPublisher --structname=DomainEventPublisher
+type domainEventPublisher interface {
+	Publish(context.Context, *entity.DomainEvent) error
 }
 
 //go:generate mockery --name store --structname=Store
 type store interface {
 	WithTransact(context.Context, func(pgx.Tx) error) error
-	//WithSerializableTransact(context.Context, func(pgx.Tx) error) error
+	WithTxOptions(context.Context, pgx.TxOptions, func(pgx.Tx) error) error
 }
 
+var (
+	// readOnlyTx is used by reads, they never wait for or block writers.
+	readOnlyTx = pgx.TxOptions{AccessMode: pgx.ReadOnly}
+	// serializableTx is used by wallet updates in LockingSerializable mode.
+	serializableTx = pgx.TxOptions{IsoLevel: pgx.Serializable}
+)
+
 type Service struct {
 	walletRepo        walletRepo
 	transactionRepo   transactionRepo
 	transactionBroker transactionBroker
 	walletCache       walletCache
+	events            eventPublisher
+	domainEvents      domainEventPublisher
+	webhooks          webhookOutbox
 	store             store
-	workersCount      int8
-	mu                *sync.RWMutex
-	walletMutex       sync.Map
+	locking           LockingMode
+	consumerLag       consumerLag
+	workerCfg         WorkerConfig
+	workers           workerPool
+	balanceLoads      singleflight.Group
+	walletLocks       lockTable
 }
 
 func New(
@@ -62,8 +94,8 @@ func New(
 	transactionBroker transactionBroker,
 	walletCache walletCache,
 	store store,
-	workersCount int8,
-
+	workers WorkerConfig,
+	opts ...Option,
 ) *Service {
 	s := &Service{
 		walletRepo:        walletRepo,
@@ -71,23 +103,73 @@ func New(
 		transactionBroker: transactionBroker,
 		walletCache:       walletCache,
 		store:             store,
-		workersCount:      workersCount,
-		mu:                &sync.RWMutex{},
+		workerCfg:         workers,
+	}
+
+	for _, opt := range opts {
+		opt(s)
 	}
 
-	for range s.workersCount {
-		go s.consumeTransactions(ctx)
+	s.startWorkers(ctx, workers.Min)
+	if workers.autoscale() {
+		go s.autoscale(ctx, workers)
 	}
 
 	return s
 }
 
+// Option configures optional collaborators of the Service.
+type Option func(*Service)
+
+// WithEventPublisher makes the consumer publish wallet events after every commit.
+func WithEventPublisher(events eventPublisher) Option {
+	return func(s *Service) {
+		s.events = events
+	}
+}
+
+// WithDomainEvents makes the service publish domain events to downstream consumers after every commit.
+func WithDomainEvents(domainEvents domainEventPublisher) Option {
+	return func(s *Service) {
+		s.domainEvents = domainEvents
+	}
+}
+
+// LockingMode is how the consumer guards concurrent updates of one wallet.
+type LockingMode string
+
+var (
+	// LockingOptimistic relies on the per wallet mutex of the replica and the version check,
+	// conflicting updates from other replicas are requeued.
+	LockingOptimistic LockingMode = "optimistic"
+	// LockingPessimistic locks the wallet row with SELECT FOR UPDATE, updates from all replicas wait for each other.
+	LockingPessimistic LockingMode = "pessimistic"
+	// LockingSerializable updates wallets in serializable transactions, the store retries serialization failures.
+	LockingSerializable LockingMode = "serializable"
+)
+
+func ParseLockingMode(mode string) (LockingMode, error) {
+	switch LockingMode(mode) {
+	case LockingOptimistic, LockingPessimistic, LockingSerializable:
+		return LockingMode(mode), nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownLocking, mode)
+}
+
+// WithLocking sets the locking mode, LockingOptimistic is used by default.
+func WithLocking(mode LockingMode) Option {
+	return func(s *Service) {
+		s.locking = mode
+	}
+}
+
 /*
 NEW WALLET
 */
 
-func (s *Service) NewWallet(ctx context.Context) (*entity.Wallet, error) {
+func (s *Service) NewWallet(ctx context.Context, owner string) (*entity.Wallet, error) {
 	wallet := entity.NewWallet()
+	wallet.Owner = owner
 
 	err := s.store.WithTransact(ctx, func(t pgx.Tx) error {
 		return s.walletRepo.Insert(ctx, t, wallet)
@@ -96,70 +178,153 @@ func (s *Service) NewWallet(ctx context.Context) (*entity.Wallet, error) {
 		return nil, err
 	}
 
+	s.publishDomainEvent(ctx, entity.NewWalletCreatedEvent(wallet))
+
 	return wallet, err
 }
 
 /*
-GET BALANCE
+FREEZE
 */
 
-func (s *Service) GetBalance(ctx context.Context, uid uuid.UUID) (int64, error) {
+// SetFrozen freezes or unfreezes the wallet. Operations on a frozen wallet are marked as failed.
+func (s *Service) SetFrozen(ctx context.Context, uid uuid.UUID, frozen bool) (*entity.Wallet, error) {
+	if uuid.Nil == uid {
+		return nil, ErrInvalidUUID
+	}
+
+	unlock := s.walletLocks.Lock(uid)
+	defer unlock()
+
+	var wallet *entity.Wallet
+	var changed bool
+	err := s.walletTransact(ctx, func(tx pgx.Tx) error {
+		changed = false
+
+		var err error
+		wallet, err = s.lockWallet(ctx, tx, uid)
+		if err != nil {
+			return err
+		}
+		if wallet.Frozen == frozen {
+			return nil
+		}
+
+		wallet.Frozen = frozen
+		wallet.UpdatedAt = time.Now()
+		changed = true
+		return s.walletRepo.Update(ctx, tx, wallet)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if changed {
+		s.publishDomainEvent(ctx, entity.NewFreezeEvent(wallet))
+	}
+
+	return wallet, nil
+}
 
-	var balance int64
-	var err error
+/*
+GET BALANCE
+*/
 
+// GetBalance serves the balance from the cache. Concurrent misses on one wallet share a single database read.
+func (s *Service) GetBalance(ctx context.Context, uid uuid.UUID) (int64, error) {
 	if uuid.Nil == uid {
 		return 0, ErrInvalidUUID
 	}
 
-	if err = s.withRLock(func() error {
-		balance, err = s.walletCache.GetBalance(ctx, uid)
-		return err
-	}); err == nil {
+	balance, err := s.walletCache.GetBalance(ctx, uid)
+	if err == nil {
 		return balance, nil
 	}
 
-	err = s.withLock(func() error {
-		return s.updateCache(ctx, uid)
+	// when redis is unavailable the balance is read from the database and not written back,
+	// so every read doesn't wait for the cache timeouts twice
+	writeThrough := errors.Is(err, walletRepository.ErrCacheMiss)
+	if !writeThrough {
+		log.Println("cache unavailable, reading balance from database: ", err)
+	}
+
+	// the load is shared with other callers, so it must not be canceled with the first one
+	loadCtx := context.WithoutCancel(ctx)
+	loaded, err, _ := s.balanceLoads.Do(uid.String(), func() (any, error) {
+		return s.loadBalance(loadCtx, uid, writeThrough)
 	})
 	if err != nil {
 		return 0, err
 	}
 
-	balance, err = s.walletCache.GetBalance(ctx, uid)
+	return loaded.(int64), nil
+}
+
+func (s *Service) loadBalance(ctx context.Context, uid uuid.UUID, writeThrough bool) (int64, error) {
+	var wallet *entity.Wallet
+	err := s.store.WithTxOptions(ctx, readOnlyTx, func(tx pgx.Tx) error {
+		var err error
+		wallet, err = s.walletRepo.GetByUUID(ctx, tx, uid)
+		return err
+	})
 	if err != nil {
 		return 0, err
 	}
 
-	return balance, nil
+	if writeThrough {
+		s.cacheBalance(ctx, wallet)
+	}
+
+	return wallet.Amount, nil
+}
+
+// cacheBalance writes the committed balance through to the cache. Older versions never overwrite newer ones.
+func (s *Service) cacheBalance(ctx context.Context, w *entity.Wallet) {
+	if err := s.walletCache.SetBalance(ctx, w.UUID, w.Amount, w.Version); err != nil {
+		log.Println("error set cache: ", err)
+	}
+}
+
+// InvalidateBalance drops the cached balance of the wallet, the next read goes to the database.
+func (s *Service) InvalidateBalance(ctx context.Context, uid uuid.UUID) error {
+	if uuid.Nil == uid {
+		return ErrInvalidUUID
+	}
+	return s.walletCache.InvalidateBalance(ctx, uid)
 }
 
-func (s *Service) updateCache(ctx context.Context, uid uuid.UUID) error {
-	return s.store.WithTransact(ctx, func(tx pgx.Tx) error {
+/*
+GET OWNER
+*/
+
+// GetOwner returns the owner of the wallet. Owners never change, so they are cached for long.
+func (s *Service) GetOwner(ctx context.Context, uid uuid.UUID) (string, error) {
+	if uuid.Nil == uid {
+		return "", ErrInvalidUUID
+	}
+
+	if owner, err := s.walletCache.GetOwner(ctx, uid); err == nil {
+		return owner, nil
+	}
+
+	var owner string
+	err := s.store.WithTxOptions(ctx, readOnlyTx, func(tx pgx.Tx) error {
 		wallet, err := s.walletRepo.GetByUUID(ctx, tx, uid)
 		if err != nil {
 			return err
 		}
-
-		err = s.walletCache.SetBalance(ctx, uid, wallet.Amount)
-		if err != nil {
-			log.Println("error update cache: ", err)
-		}
-
+		owner = wallet.Owner
 		return nil
 	})
-}
+	if err != nil {
+		return "", err
+	}
 
-func (s *Service) withLock(fn func() error) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	return fn()
-}
+	if err := s.walletCache.SetOwner(ctx, uid, owner); err != nil {
+		log.Println("error update owner cache: ", err)
+	}
 
-func (s *Service) withRLock(fn func() error) error {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return fn()
+	return owner, nil
 }
 
 /*
@@ -167,7 +332,7 @@ TRANSACTION WITH BROKER
 */
 
 func (s *Service) NewTransaction(ctx context.Context, t *entity.Transaction) error {
-	err := s.store.WithTransact(ctx, func(tx pgx.Tx) error {
+	err := s.store.WithTxOptions(ctx, readOnlyTx, func(tx pgx.Tx) error {
 		wallet, err := s.walletRepo.GetByUUID(ctx, tx, t.WalletUUID)
 		if err != nil {
 			return err
@@ -182,7 +347,7 @@ func (s *Service) NewTransaction(ctx context.Context, t *entity.Transaction) err
 		if errors.Is(err, entity.ErrWalletUUIDIsEmpty) {
 			return err
 		}
-		if errors.Is(err, entity.ErrNotEnoughFunds) {
+		if errors.Is(err, entity.ErrNotEnoughFunds) || errors.Is(err, entity.ErrWalletFrozen) {
 			return err
 		}
 		if errors.Is(err, walletRepository.ErrWalletNotFound) {
@@ -196,97 +361,214 @@ func (s *Service) NewTransaction(ctx context.Context, t *entity.Transaction) err
 	return s.transactionBroker.Publish(ctx, t)
 }
 
-func (s *Service) consumeTransactions(ctx context.Context) {
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		default:
-			t, err := s.transactionBroker.Consume(ctx)
-			if err != nil {
-				log.Println("error consuming transaction: ", err)
-				continue
-			}
+// GetTransaction returns a processed transaction. Transactions still waiting in the broker are not found.
+func (s *Service) GetTransaction(ctx context.Context, key uuid.UUID) (*entity.Transaction, error) {
+	if uuid.Nil == key {
+		return nil, ErrInvalidUUID
+	}
 
-			mu := s.getWalletMutex(t.WalletUUID)
-
-			mu.Lock()
-
-			err = s.store.WithTransact(ctx, func(tx pgx.Tx) error {
-				if exists, err := s.transactionRepo.Exists(ctx, tx, t); err != nil || exists {
-					return nil
-				}
-				wallet, err := s.walletRepo.GetByUUID(ctx, tx, t.WalletUUID)
-				if err != nil {
-					return err
-				}
-				newWallet, err := wallet.DoTransaction(t)
-				if err != nil {
-					return err
-				}
-				err = s.walletRepo.Update(ctx, tx, newWallet)
-				if err != nil {
-					return err
-				}
-
-				t.StatusSuccess()
-				err = s.transactionRepo.Insert(ctx, tx, t)
-				if err != nil {
-					return err
-				}
-
-				err = s.walletCache.SetBalance(ctx, t.WalletUUID, newWallet.Amount)
-				if err != nil {
-					log.Println("error set cache: ", err)
-				}
-
-				return nil
-			})
-
-			mu.Unlock()
-
-			if err != nil {
-				log.Printf("failed to process transaction %v: %v\n", t.IdempotencyKey, err)
-
-				if errors.Is(err, transactionRepository.ErrDuplicateTransaction) ||
-					errors.Is(err, walletRepository.ErrWalletNotFound) {
-					continue
-				}
-
-				s.handleTransactionError(ctx, t, err)
+	var t *entity.Transaction
+	err := s.store.WithTxOptions(ctx, readOnlyTx, func(tx pgx.Tx) error {
+		var err error
+		t, err = s.transactionRepo.GetByIdempotencyKey(ctx, tx, key)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// runWorker consumes transactions until ctx is done or stop is closed.
+func (s *Service) runWorker(ctx context.Context, stop <-chan struct{}) {
+	for s.workers.wait(ctx, stop) {
+		if s.workerCfg.batching() {
+			if batch := s.consumeBatch(ctx); len(batch) > 0 {
+				done := s.workers.track(len(batch))
+				s.processBatch(ctx, batch)
+				done()
 			}
+			continue
+		}
+
+		t, ack, err := s.transactionBroker.Consume(ctx)
+		if err != nil {
+			log.Println("error consuming transaction: ", err)
+			continue
+		}
+
+		done := s.workers.track(1)
+		s.settle(ctx, consumed{transaction: t, ack: ack}, s.processTransaction(ctx, t))
+		done()
+	}
+}
+
+// consumed is a transaction taken from the broker together with its acknowledgement.
+type consumed struct {
+	transaction *entity.Transaction
+	ack         transactionRepository.Ack
+}
+
+// settle acknowledges the transaction when processing returned no error, i.e. it was committed,
+// recorded as failed, requeued or dropped for good. Otherwise the broker delivers it again.
+func (s *Service) settle(ctx context.Context, c consumed, err error) {
+	if err != nil {
+		log.Printf("transaction %v is not settled, leaving it for redelivery: %v\n", c.transaction.IdempotencyKey, err)
+		return
+	}
+	if err := c.ack(ctx); err != nil {
+		log.Printf("failed to acknowledge transaction %v: %v\n", c.transaction.IdempotencyKey, err)
+	}
+}
+
+// processTransaction applies the transaction. It returns an error only when the outcome
+// could not be stored, so the transaction has to be delivered again.
+func (s *Service) processTransaction(ctx context.Context, t *entity.Transaction) error {
+	unlock := s.walletLocks.Lock(t.WalletUUID)
+
+	var wallet, newWallet *entity.Wallet
+	err := s.walletTransact(ctx, func(tx pgx.Tx) error {
+		// the store retries serialization failures, state of a rolled back attempt must not leak
+		wallet, newWallet = nil, nil
+
+		exists, err := s.transactionRepo.Exists(ctx, tx, t)
+		if err != nil || exists {
+			return err
+		}
+		wallet, err = s.lockWallet(ctx, tx, t.WalletUUID)
+		if err != nil {
+			return err
+		}
+		newWallet, err = wallet.DoTransaction(t)
+		if err != nil {
+			return err
+		}
+		err = s.walletRepo.Update(ctx, tx, newWallet)
+		if err != nil {
+			return err
+		}
+
+		t.StatusSuccess()
+		err = s.transactionRepo.Insert(ctx, tx, t)
+		if err != nil {
+			return err
+		}
+
+		return s.enqueueWebhooks(ctx, tx, t)
+	})
+
+	if err == nil && newWallet != nil {
+		s.cacheBalance(ctx, newWallet)
+	}
+
+	unlock()
+
+	if err == nil && newWallet != nil {
+		s.publishEvents(ctx,
+			entity.NewBalanceChangedEvent(newWallet),
+			entity.NewTransactionStatusEvent(t),
+		)
+		s.publishDomainEvent(ctx, entity.NewOperationSucceededEvent(newWallet, t))
+	}
+
+	if err != nil {
+		log.Printf("failed to process transaction %v: %v\n", t.IdempotencyKey, err)
+
+		if errors.Is(err, transactionRepository.ErrDuplicateTransaction) ||
+			errors.Is(err, walletRepository.ErrWalletNotFound) {
+			return nil
 		}
+
+		return s.handleTransactionError(ctx, t, wallet, err)
 	}
+
+	return nil
+}
+
+// walletTransact runs fn, which updates a wallet, with the isolation required by the locking mode.
+func (s *Service) walletTransact(ctx context.Context, fn func(pgx.Tx) error) error {
+	if s.locking == LockingSerializable {
+		return s.store.WithTxOptions(ctx, serializableTx, fn)
+	}
+	return s.store.WithTransact(ctx, fn)
+}
+
+// lockWallet loads the wallet that is going to be updated in the transaction.
+func (s *Service) lockWallet(ctx context.Context, tx pgx.Tx, uid uuid.UUID) (*entity.Wallet, error) {
+	if s.locking == LockingPessimistic {
+		return s.walletRepo.GetByUUIDForUpdate(ctx, tx, uid)
+	}
+	return s.walletRepo.GetByUUID(ctx, tx, uid)
 }
 
-func (s *Service) handleTransactionError(ctx context.Context, t *entity.Transaction, err error) {
+// handleTransactionError requeues the transaction unless it can never succeed.
+// wallet is the state the transaction was checked against, it is nil when the wallet was not loaded.
+func (s *Service) handleTransactionError(ctx context.Context, t *entity.Transaction, wallet *entity.Wallet, err error) error {
 	if errors.Is(err, transactionRepository.ErrDuplicateTransaction) {
 		log.Printf("Duplicate transaction: %v, skipping\n", t.IdempotencyKey)
-		return
+		return nil
 	}
 
-	if errors.Is(err, entity.ErrNotEnoughFunds) {
-		s.markTransactionAsFailed(ctx, t)
-		return
+	if errors.Is(err, entity.ErrNotEnoughFunds) || errors.Is(err, entity.ErrWalletFrozen) {
+		return s.markTransactionAsFailed(ctx, t, wallet, err)
 	}
 
 	t.StatusNew()
 	if err := s.transactionBroker.Publish(ctx, t); err != nil {
 		log.Printf("Failed to requeue transaction %v: %v\n", t.IdempotencyKey, err)
+		return err
 	}
+	return nil
 }
 
-func (s *Service) markTransactionAsFailed(ctx context.Context, t *entity.Transaction) {
+func (s *Service) markTransactionAsFailed(ctx context.Context, t *entity.Transaction, wallet *entity.Wallet, reason error) error {
 	err := s.store.WithTransact(ctx, func(tx pgx.Tx) error {
 		t.StatusFailure()
-		return s.transactionRepo.Insert(ctx, tx, t)
+		if err := s.transactionRepo.Insert(ctx, tx, t); err != nil {
+			return err
+		}
+		return s.enqueueWebhooks(ctx, tx, t)
 	})
 	if err != nil {
 		log.Printf("Failed to mark transaction as failed: %v", err)
+		return err
+	}
+
+	s.publishEvents(ctx, entity.NewTransactionStatusEvent(t))
+	if wallet != nil {
+		s.publishDomainEvent(ctx, entity.NewOperationFailedEvent(wallet, t, reason))
 	}
+	return nil
 }
 
-func (s *Service) getWalletMutex(uid uuid.UUID) *sync.Mutex {
-	mu, _ := s.walletMutex.LoadOrStore(uid.String(), &sync.Mutex{})
-	return mu.(*sync.Mutex)
+func (s *Service) enqueueWebhooks(ctx context.Context, tx pgx.Tx, t *entity.Transaction) error {
+	if s.webhooks == nil {
+		return nil
+	}
+	return s.webhooks.Enqueue(ctx, tx, t)
+}
+
+// publishEvents notifies subscribers about committed changes. Events are best effort and never fail the transaction.
+func (s *Service) publishEvents(ctx context.Context, events ...*entity.WalletEvent) {
+	if s.events == nil {
+		return
+	}
+
+	for _, e := range events {
+		if err := s.events.Publish(ctx, e); err != nil {
+			log.Printf("Failed to publish %v event of wallet %v: %v\n", e.Type, e.WalletUUID, err)
+		}
+	}
+}
+
+// publishDomainEvent publishes a committed change for downstream consumers. Like publishEvents it never fails the transaction.
+func (s *Service) publishDomainEvent(ctx context.Context, e *entity.DomainEvent) {
+	if s.domainEvents == nil {
+		return
+	}
+
+	if err := s.domainEvents.Publish(ctx, e); err != nil {
+		log.Printf("Failed to publish %v domain event of wallet %v: %v\n", e.Type, e.WalletUUID, err)
+	}
 }
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+	"wallet/internal/entity"
+	walletRepository "wallet/internal/repository/wallet"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// memoryCache keeps balances in memory, so the benchmark measures the service and not the network.
+type memoryCache struct {
+	balances sync.Map
+}
+
+func (c *memoryCache) SetBalance(_ context.Context, uid uuid.UUID, balance, _ int64) error {
+	c.balances.Store(uid, balance)
+	return nil
+}
+
+func (c *memoryCache) GetBalance(_ context.Context, uid uuid.UUID) (int64, error) {
+	balance, ok := c.balances.Load(uid)
+	if !ok {
+		return 0, walletRepository.ErrCacheMiss
+	}
+	return balance.(int64), nil
+}
+
+func (c *memoryCache) InvalidateBalance(_ context.Context, uid uuid.UUID) error {
+	c.balances.Delete(uid)
+	return nil
+}
+
+func (c *memoryCache) SetOwner(context.Context, uuid.UUID, string) error   { return nil }
+func (c *memoryCache) GetOwner(context.Context, uuid.UUID) (string, error) { return "", nil }
+
+// slowStore imitates a database round trip.
+type slowStore struct{}
+
+func (slowStore) WithTransact(_ context.Context, fn func(pgx.Tx) error) error {
+	time.Sleep(time.Millisecond)
+	return fn(nil)
+}
+
+func (s slowStore) WithTxOptions(ctx context.Context, _ pgx.TxOptions, fn func(pgx.Tx) error) error {
+	return s.WithTransact(ctx, fn)
+}
+
+type memoryWallets struct {
+	walletRepo
+}
+
+func (memoryWallets) GetByUUID(_ context.Context, _ pgx.Tx, uid uuid.UUID) (*entity.Wallet, error) {
+	return &entity.Wallet{UUID: uid, Amount: 100, Version: 1}, nil
+}
+
+// BenchmarkService_GetBalance reads balances of many wallets in parallel. With invalidate every
+// read misses the cache, concurrent misses of one wallet share one database read.
+func BenchmarkService_GetBalance(b *testing.B) {
+	wallets := make([]uuid.UUID, 1024)
+	for i := range wallets {
+		wallets[i] = uuid.New()
+	}
+
+	for _, invalidate := range []bool{false, true} {
+		name := "hit"
+		if invalidate {
+			name = "miss"
+		}
+
+		b.Run(name, func(b *testing.B) {
+			cache := &memoryCache{}
+			s := &Service{
+				walletCache: cache,
+				walletRepo:  memoryWallets{},
+				store:       slowStore{},
+			}
+			ctx := context.Background()
+			for _, uid := range wallets {
+				_ = cache.SetBalance(ctx, uid, 100, 1)
+			}
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					uid := wallets[i%len(wallets)]
+					i++
+					if invalidate {
+						_ = cache.InvalidateBalance(ctx, uid)
+					}
+					if _, err := s.GetBalance(ctx, uid); err != nil {
+						b.Error(err)
+						return
+					}
+				}
+			})
+		})
+	}
+}
@@ -2,12 +2,15 @@ package service
 
 import (
 	"context"
+	"errors"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"sync"
 	"testing"
 	"time"
 	"wallet/internal/entity"
+	transactionRepository "wallet/internal/repository/transaction"
+	walletRepository "wallet/internal/repository/wallet"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -45,12 +48,13 @@ func TestService_NewWallet(t *testing.T) {
 	}
 
 	// Вызываем метод
-	wallet, err := service.NewWallet(ctx)
+	wallet, err := service.NewWallet(ctx, "user-1")
 
 	// Проверяем результаты
 	assert.NoError(t, err)
 	assert.NotNil(t, wallet)
 	assert.Equal(t, int64(0), wallet.Amount) // Проверяем, что кошелек создан с нулевым балансом
+	assert.Equal(t, "user-1", wallet.Owner)
 
 	// Проверяем, что моки были вызваны
 	storeMock.AssertCalled(t, "WithTransact", ctx, mock.AnythingOfType("func(pgx.Tx) error"))
@@ -74,7 +78,6 @@ func TestService_GetBalance(t *testing.T) {
 		walletCache: walletCacheMock,
 		store:       storeMock,
 		walletRepo:  walletRepoMock,
-		mu:          &sync.RWMutex{},
 	}
 
 	// Вызываем метод
@@ -105,9 +108,9 @@ func TestService_NewTransaction(t *testing.T) {
 
 	// Настраиваем ожидания
 	storeMock.
-		On("WithTransact", ctx, mock.AnythingOfType("func(pgx.Tx) error")).
+		On("WithTxOptions", ctx, readOnlyTx, mock.AnythingOfType("func(pgx.Tx) error")).
 		Run(func(args mock.Arguments) {
-			fn := args.Get(1).(func(pgx.Tx) error)
+			fn := args.Get(2).(func(pgx.Tx) error)
 			_ = fn(txMock)
 		}).
 		Return(nil)
@@ -123,7 +126,6 @@ func TestService_NewTransaction(t *testing.T) {
 		walletRepo:        walletRepoMock,
 		store:             storeMock,
 		transactionBroker: transactionBrokerMock,
-		mu:                &sync.RWMutex{},
 	}
 
 	// Создаем транзакцию
@@ -140,12 +142,12 @@ func TestService_NewTransaction(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Проверяем, что моки были вызваны
-	storeMock.AssertCalled(t, "WithTransact", ctx, mock.AnythingOfType("func(pgx.Tx) error"))
+	storeMock.AssertCalled(t, "WithTxOptions", ctx, readOnlyTx, mock.AnythingOfType("func(pgx.Tx) error"))
 	walletRepoMock.AssertCalled(t, "GetByUUID", ctx, mock.AnythingOfType("*mocks.MockTx"), walletUUID)
 	transactionBrokerMock.AssertCalled(t, "Publish", ctx, mock.AnythingOfType("*entity.Transaction"))
 }
 
-func TestService_consumeTransactions(t *testing.T) {
+func TestService_runWorker(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -163,7 +165,7 @@ func TestService_consumeTransactions(t *testing.T) {
 		Amount:         100,
 		Status:         entity.New,
 		IdempotencyKey: uuid.New(),
-	}, nil)
+	}, transactionRepository.Ack(func(context.Context) error { return nil }), nil)
 	storeMock.
 		On("WithTransact", ctx, mock.AnythingOfType("func(pgx.Tx) error")).
 		Return(nil)
@@ -180,7 +182,7 @@ func TestService_consumeTransactions(t *testing.T) {
 		On("Insert", ctx, mock.AnythingOfType("pgx.Tx"), mock.AnythingOfType("*entity.Transaction")).
 		Return(nil)
 	walletCacheMock.
-		On("SetBalance", ctx, mock.AnythingOfType("uuid.UUID"), mock.AnythingOfType("int64")).
+		On("SetBalance", ctx, mock.AnythingOfType("uuid.UUID"), mock.AnythingOfType("int64"), mock.AnythingOfType("int64")).
 		Return(nil)
 
 	// Создаем сервис с моками
@@ -190,12 +192,10 @@ func TestService_consumeTransactions(t *testing.T) {
 		transactionRepo:   transactionRepoMock,
 		store:             storeMock,
 		walletCache:       walletCacheMock,
-		mu:                &sync.RWMutex{},
-		walletMutex:       sync.Map{},
 	}
 
-	// Запускаем consumeTransactions в отдельной горутине
-	go service.consumeTransactions(ctx)
+	// Запускаем воркер в отдельной горутине
+	go service.runWorker(ctx, nil)
 
 	// Даем время для обработки
 	time.Sleep(100 * time.Millisecond)
@@ -205,7 +205,7 @@ func TestService_consumeTransactions(t *testing.T) {
 	storeMock.AssertCalled(t, "WithTransact", ctx, mock.AnythingOfType("func(pgx.Tx) error"))
 }
 
-func TestService_consumeTransactions2(t *testing.T) {
+func TestService_runWorker2(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -223,7 +223,7 @@ func TestService_consumeTransactions2(t *testing.T) {
 		Amount:         100,
 		Status:         entity.New,
 		IdempotencyKey: uuid.New(),
-	}, nil)
+	}, transactionRepository.Ack(func(context.Context) error { return nil }), nil)
 	storeMock.
 		On("WithTransact", ctx, mock.AnythingOfType("func(pgx.Tx) error")).
 		Return(nil)
@@ -240,7 +240,7 @@ func TestService_consumeTransactions2(t *testing.T) {
 		On("Insert", ctx, mock.AnythingOfType("pgx.Tx"), mock.AnythingOfType("*entity.Transaction")).
 		Return(nil)
 	walletCacheMock.
-		On("SetBalance", ctx, mock.AnythingOfType("uuid.UUID"), mock.AnythingOfType("int64")).
+		On("SetBalance", ctx, mock.AnythingOfType("uuid.UUID"), mock.AnythingOfType("int64"), mock.AnythingOfType("int64")).
 		Return(nil)
 
 	// Создаем сервис с моками
@@ -250,12 +250,10 @@ func TestService_consumeTransactions2(t *testing.T) {
 		transactionRepo:   transactionRepoMock,
 		store:             storeMock,
 		walletCache:       walletCacheMock,
-		mu:                &sync.RWMutex{},
-		walletMutex:       sync.Map{},
 	}
 
-	// Запускаем consumeTransactions в отдельной горутине
-	go service.consumeTransactions(ctx)
+	// Запускаем воркер в отдельной горутине
+	go service.runWorker(ctx, nil)
 
 	// Даем время для обработки
 	time.Sleep(100 * time.Millisecond)
@@ -264,3 +262,228 @@ func TestService_consumeTransactions2(t *testing.T) {
 	transactionBrokerMock.AssertCalled(t, "Consume", ctx)
 	storeMock.AssertCalled(t, "WithTransact", ctx, mock.AnythingOfType("func(pgx.Tx) error"))
 }
+
+func TestService_SetFrozen(t *testing.T) {
+	ctx := context.Background()
+	walletUUID := uuid.New()
+
+	walletRepoMock := &mocks.WalletRepo{}
+	storeMock := &mocks.Store{}
+	domainEventsMock := &mocks.DomainEventPublisher{}
+	txMock := &mocks.MockTx{}
+
+	storeMock.
+		On("WithTransact", ctx, mock.AnythingOfType("func(pgx.Tx) error")).
+		Return(func(_ context.Context, fn func(pgx.Tx) error) error {
+			return fn(txMock)
+		})
+	walletRepoMock.
+		On("GetByUUID", ctx, txMock, walletUUID).
+		Return(&entity.Wallet{UUID: walletUUID, Amount: 100, Version: 1}, nil).
+		Once()
+	walletRepoMock.
+		On("Update", ctx, txMock, mock.MatchedBy(func(w *entity.Wallet) bool { return w.Frozen })).
+		Return(nil).
+		Once()
+	domainEventsMock.
+		On("Publish", ctx, mock.MatchedBy(func(e *entity.DomainEvent) bool {
+			return e.Type == entity.WalletFrozen && e.WalletUUID == walletUUID && e.Balance == 100
+		})).
+		Return(nil).
+		Once()
+
+	service := &Service{
+		walletRepo:   walletRepoMock,
+		store:        storeMock,
+		domainEvents: domainEventsMock,
+	}
+
+	wallet, err := service.SetFrozen(ctx, walletUUID, true)
+	assert.NoError(t, err)
+	assert.True(t, wallet.Frozen)
+
+	// freezing a frozen wallet changes nothing and publishes nothing
+	walletRepoMock.
+		On("GetByUUID", ctx, txMock, walletUUID).
+		Return(&entity.Wallet{UUID: walletUUID, Amount: 100, Version: 2, Frozen: true}, nil).
+		Once()
+
+	_, err = service.SetFrozen(ctx, walletUUID, true)
+	assert.NoError(t, err)
+
+	walletRepoMock.AssertExpectations(t)
+	domainEventsMock.AssertExpectations(t)
+}
+
+// a retried attempt that finds the transaction already processed must not publish the state of the rolled back one
+func TestService_processTransaction_Retry(t *testing.T) {
+	ctx := context.Background()
+	walletUUID := uuid.New()
+
+	walletRepoMock := &mocks.WalletRepo{}
+	transactionRepoMock := &mocks.TransactionRepo{}
+	storeMock := &mocks.Store{}
+	walletCacheMock := &mocks.WalletCache{}
+	domainEventsMock := &mocks.DomainEventPublisher{}
+	txMock := &mocks.MockTx{}
+
+	storeMock.
+		On("WithTransact", ctx, mock.AnythingOfType("func(pgx.Tx) error")).
+		Return(func(_ context.Context, fn func(pgx.Tx) error) error {
+			// the first attempt fails to commit with a serialization failure
+			_ = fn(txMock)
+			return fn(txMock)
+		})
+	transactionRepoMock.On("Exists", ctx, txMock, mock.Anything).Return(false, nil).Once()
+	transactionRepoMock.On("Exists", ctx, txMock, mock.Anything).Return(true, nil).Once()
+	walletRepoMock.On("GetByUUID", ctx, txMock, walletUUID).Return(&entity.Wallet{UUID: walletUUID, Amount: 100}, nil).Once()
+	walletRepoMock.On("Update", ctx, txMock, mock.Anything).Return(nil).Once()
+	transactionRepoMock.On("Insert", ctx, txMock, mock.Anything).Return(nil).Once()
+
+	service := &Service{
+		walletRepo:      walletRepoMock,
+		transactionRepo: transactionRepoMock,
+		store:           storeMock,
+		walletCache:     walletCacheMock,
+		domainEvents:    domainEventsMock,
+	}
+
+	service.processTransaction(ctx, &entity.Transaction{
+		WalletUUID:     walletUUID,
+		IdempotencyKey: uuid.New(),
+		Operation:      entity.Deposit,
+		Amount:         50,
+		Status:         entity.New,
+	})
+
+	transactionRepoMock.AssertExpectations(t)
+	walletCacheMock.AssertNotCalled(t, "SetBalance", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	domainEventsMock.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+}
+
+func TestService_lockWallet(t *testing.T) {
+	ctx := context.Background()
+	walletUUID := uuid.New()
+	txMock := &mocks.MockTx{}
+
+	walletRepoMock := &mocks.WalletRepo{}
+	walletRepoMock.
+		On("GetByUUID", ctx, txMock, walletUUID).
+		Return(&entity.Wallet{UUID: walletUUID}, nil).
+		Once()
+	walletRepoMock.
+		On("GetByUUIDForUpdate", ctx, txMock, walletUUID).
+		Return(&entity.Wallet{UUID: walletUUID}, nil).
+		Once()
+
+	optimistic := &Service{walletRepo: walletRepoMock}
+	_, err := optimistic.lockWallet(ctx, txMock, walletUUID)
+	assert.NoError(t, err)
+
+	pessimistic := &Service{walletRepo: walletRepoMock, locking: LockingPessimistic}
+	_, err = pessimistic.lockWallet(ctx, txMock, walletUUID)
+	assert.NoError(t, err)
+
+	walletRepoMock.AssertExpectations(t)
+
+	_, err = ParseLockingMode("mutex")
+	assert.ErrorIs(t, err, ErrUnknownLocking)
+}
+
+func TestService_walletTransact(t *testing.T) {
+	ctx := context.Background()
+	fn := func(pgx.Tx) error { return nil }
+
+	storeMock := &mocks.Store{}
+	storeMock.On("WithTransact", ctx, mock.AnythingOfType("func(pgx.Tx) error")).Return(nil).Once()
+	storeMock.On("WithTxOptions", ctx, serializableTx, mock.AnythingOfType("func(pgx.Tx) error")).Return(nil).Once()
+
+	assert.NoError(t, (&Service{store: storeMock}).walletTransact(ctx, fn))
+	assert.NoError(t, (&Service{store: storeMock, locking: LockingSerializable}).walletTransact(ctx, fn))
+
+	storeMock.AssertExpectations(t)
+}
+
+func TestService_GetBalance_SharedLoad(t *testing.T) {
+	ctx := context.Background()
+	walletUUID := uuid.New()
+	txMock := &mocks.MockTx{}
+
+	walletCacheMock := &mocks.WalletCache{}
+	walletCacheMock.On("GetBalance", ctx, walletUUID).Return(int64(0), walletRepository.ErrCacheMiss)
+	walletCacheMock.On("SetBalance", mock.Anything, walletUUID, int64(300), int64(7)).Return(nil).Once()
+
+	release := make(chan time.Time)
+	walletRepoMock := &mocks.WalletRepo{}
+	walletRepoMock.
+		On("GetByUUID", mock.Anything, txMock, walletUUID).
+		WaitUntil(release).
+		Return(&entity.Wallet{UUID: walletUUID, Amount: 300, Version: 7}, nil).
+		Once()
+
+	storeMock := &mocks.Store{}
+	storeMock.
+		On("WithTxOptions", mock.Anything, readOnlyTx, mock.AnythingOfType("func(pgx.Tx) error")).
+		Return(func(_ context.Context, _ pgx.TxOptions, fn func(pgx.Tx) error) error {
+			return fn(txMock)
+		})
+
+	service := &Service{
+		walletCache: walletCacheMock,
+		walletRepo:  walletRepoMock,
+		store:       storeMock,
+	}
+
+	const readers = 10
+	var wg sync.WaitGroup
+	for range readers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			balance, err := service.GetBalance(ctx, walletUUID)
+			assert.NoError(t, err)
+			assert.Equal(t, int64(300), balance)
+		}()
+	}
+
+	// let every reader miss the cache before the database answers
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	walletRepoMock.AssertNumberOfCalls(t, "GetByUUID", 1)
+	walletCacheMock.AssertExpectations(t)
+}
+
+func TestService_GetBalance_CacheUnavailable(t *testing.T) {
+	ctx := context.Background()
+	walletUUID := uuid.New()
+	txMock := &mocks.MockTx{}
+
+	walletCacheMock := &mocks.WalletCache{}
+	walletCacheMock.On("GetBalance", ctx, walletUUID).Return(int64(0), errors.New("dial tcp: connection refused"))
+
+	walletRepoMock := &mocks.WalletRepo{}
+	walletRepoMock.On("GetByUUID", mock.Anything, txMock, walletUUID).
+		Return(&entity.Wallet{UUID: walletUUID, Amount: 300, Version: 7}, nil).Once()
+
+	storeMock := &mocks.Store{}
+	storeMock.
+		On("WithTxOptions", mock.Anything, readOnlyTx, mock.AnythingOfType("func(pgx.Tx) error")).
+		Return(func(_ context.Context, _ pgx.TxOptions, fn func(pgx.Tx) error) error {
+			return fn(txMock)
+		})
+
+	service := &Service{
+		walletCache: walletCacheMock,
+		walletRepo:  walletRepoMock,
+		store:       storeMock,
+	}
+
+	balance, err := service.GetBalance(ctx, walletUUID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(300), balance)
+
+	walletCacheMock.AssertNotCalled(t, "SetBalance", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	walletRepoMock.AssertExpectations(t)
+}
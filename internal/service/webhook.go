@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"log"
+	"math/rand/v2"
+	"sync"
+	"time"
+	"wallet/internal/entity"
+	webhookRepository "wallet/internal/repository/webhook"
+	"wallet/internal/utils/metrics"
+)
+
+//go:generate mockery --name webhookRepo --structname=WebhookRepo
+type webhookRepo interface {
+	Insert(context.Context, pgx.Tx, *entity.Webhook) error
+	GetByID(context.Context, pgx.Tx, uuid.UUID) (*entity.Webhook, error)
+	List(ctx context.Context, tx pgx.Tx, subject string) ([]*entity.Webhook, error)
+	Delete(context.Context, pgx.Tx, uuid.UUID) error
+	Enable(context.Context, pgx.Tx, uuid.UUID) error
+
+	Claim(ctx context.Context, tx pgx.Tx, limit uint64, lease time.Duration) ([]webhookRepository.ClaimedDelivery, error)
+	Complete(context.Context, pgx.Tx, *entity.WebhookDelivery) error
+	LogAttempt(ctx context.Context, tx pgx.Tx, deliveryID int64, statusCode *int, attemptErr *string, duration time.Duration) error
+	MarkHealthy(context.Context, pgx.Tx, uuid.UUID) error
+	MarkFailing(ctx context.Context, tx pgx.Tx, id uuid.UUID, disableAfter time.Duration) (bool, error)
+
+	Deliveries(ctx context.Context, tx pgx.Tx, webhookID uuid.UUID, limit uint64) ([]*entity.WebhookDelivery, error)
+	Redeliver(ctx context.Context, tx pgx.Tx, webhookID uuid.UUID, deliveryID int64) error
+}
+
+//go:generate mockery --name webhookSender --structname=WebhookSender
+type webhookSender interface {
+	Send(ctx context.Context, url, secret string, eventID uuid.UUID, payload []byte) (int, error)
+}
+
+//go:generate mockery --name webhookOutbox --structname=WebhookOutbox
+type webhookOutbox interface {
+	Enqueue(context.Context, pgx.Tx, *entity.Transaction) error
+}
+
+// WithWebhookOutbox makes the consumer enqueue webhook deliveries in the transaction committing the outcome.
+func WithWebhookOutbox(outbox webhookOutbox) Option {
+	return func(s *Service) {
+		s.webhooks = outbox
+	}
+}
+
+type WebhookConfig struct {
+	PollInterval time.Duration
+	BatchSize    uint64
+	// Lease hides claimed deliveries from other replicas while they are being sent.
+	Lease       time.Duration
+	MaxAttempts int
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	// DisableAfter disables webhooks failing continuously for longer.
+	DisableAfter time.Duration
+}
+
+const webhookDeliveriesLimit = 100
+
+// WebhookService manages webhook subscriptions and delivers the queued events.
+type WebhookService struct {
+	webhookRepo webhookRepo
+	sender      webhookSender
+	store       store
+	cfg         WebhookConfig
+}
+
+func NewWebhookService(webhookRepo webhookRepo, sender webhookSender, store store, cfg WebhookConfig) *WebhookService {
+	return &WebhookService{
+		webhookRepo: webhookRepo,
+		sender:      sender,
+		store:       store,
+		cfg:         cfg,
+	}
+}
+
+func (s *WebhookService) Create(ctx context.Context, subject string, walletUUID *uuid.UUID, url string) (*entity.Webhook, error) {
+	webhook, err := entity.NewWebhook(subject, walletUUID, url)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.store.WithTransact(ctx, func(tx pgx.Tx) error {
+		return s.webhookRepo.Insert(ctx, tx, webhook)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+func (s *WebhookService) Get(ctx context.Context, id uuid.UUID) (*entity.Webhook, error) {
+	if id == uuid.Nil {
+		return nil, ErrInvalidUUID
+	}
+
+	var webhook *entity.Webhook
+	err := s.store.WithTransact(ctx, func(tx pgx.Tx) error {
+		var err error
+		webhook, err = s.webhookRepo.GetByID(ctx, tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+// List returns webhooks of the subject, or all webhooks when subject is empty.
+func (s *WebhookService) List(ctx context.Context, subject string) ([]*entity.Webhook, error) {
+	var webhooks []*entity.Webhook
+
+	err := s.store.WithTransact(ctx, func(tx pgx.Tx) error {
+		var err error
+		webhooks, err = s.webhookRepo.List(ctx, tx, subject)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+func (s *WebhookService) Delete(ctx context.Context, id uuid.UUID) error {
+	return s.store.WithTransact(ctx, func(tx pgx.Tx) error {
+		return s.webhookRepo.Delete(ctx, tx, id)
+	})
+}
+
+func (s *WebhookService) Enable(ctx context.Context, id uuid.UUID) error {
+	return s.store.WithTransact(ctx, func(tx pgx.Tx) error {
+		return s.webhookRepo.Enable(ctx, tx, id)
+	})
+}
+
+// Deliveries returns the latest deliveries of the webhook.
+func (s *WebhookService) Deliveries(ctx context.Context, id uuid.UUID) ([]*entity.WebhookDelivery, error) {
+	var deliveries []*entity.WebhookDelivery
+
+	err := s.store.WithTransact(ctx, func(tx pgx.Tx) error {
+		var err error
+		deliveries, err = s.webhookRepo.Deliveries(ctx, tx, id, webhookDeliveriesLimit)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (s *WebhookService) Redeliver(ctx context.Context, id uuid.UUID, deliveryID int64) error {
+	return s.store.WithTransact(ctx, func(tx pgx.Tx) error {
+		return s.webhookRepo.Redeliver(ctx, tx, id, deliveryID)
+	})
+}
+
+/*
+DELIVERY
+*/
+
+// Run delivers due webhooks until ctx is done. Every replica may run it, deliveries are claimed with SKIP LOCKED.
+func (s *WebhookService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// keep going while there is a backlog
+			for ctx.Err() == nil {
+				if n := s.deliverDue(ctx); n == 0 || n < int(s.cfg.BatchSize) {
+					break
+				}
+			}
+		}
+	}
+}
+
+func (s *WebhookService) deliverDue(ctx context.Context) int {
+	var claimed []webhookRepository.ClaimedDelivery
+
+	err := s.store.WithTransact(ctx, func(tx pgx.Tx) error {
+		var err error
+		claimed, err = s.webhookRepo.Claim(ctx, tx, s.cfg.BatchSize, s.cfg.Lease)
+		return err
+	})
+	if err != nil {
+		log.Println("error claim webhook deliveries: ", err)
+		return 0
+	}
+
+	var wg sync.WaitGroup
+	for _, c := range claimed {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.deliver(ctx, c)
+		}()
+	}
+	wg.Wait()
+
+	return len(claimed)
+}
+
+func (s *WebhookService) deliver(ctx context.Context, c webhookRepository.ClaimedDelivery) {
+	d := c.Delivery
+
+	start := time.Now()
+	code, sendErr := s.sender.Send(ctx, c.URL, c.Secret, d.EventID, d.Payload)
+	duration := time.Since(start)
+
+	now := time.Now()
+	d.Attempts++
+	d.LastStatusCode = nil
+	d.LastError = nil
+	if code != 0 {
+		d.LastStatusCode = &code
+	}
+
+	result := string(entity.DeliveryDelivered)
+	switch {
+	case sendErr == nil:
+		d.Status = entity.DeliveryDelivered
+		d.DeliveredAt = &now
+	case d.Attempts >= s.cfg.MaxAttempts:
+		msg := sendErr.Error()
+		d.LastError = &msg
+		d.Status = entity.DeliveryFailed
+		result = string(entity.DeliveryFailed)
+	default:
+		msg := sendErr.Error()
+		d.LastError = &msg
+		d.NextAttemptAt = now.Add(s.backoff(d.Attempts))
+		result = "retry"
+	}
+	metrics.IncWebhookDeliveries(result)
+
+	err := s.store.WithTransact(ctx, func(tx pgx.Tx) error {
+		if err := s.webhookRepo.Complete(ctx, tx, d); err != nil {
+			return err
+		}
+		if err := s.webhookRepo.LogAttempt(ctx, tx, d.ID, d.LastStatusCode, d.LastError, duration); err != nil {
+			return err
+		}
+
+		if sendErr == nil {
+			return s.webhookRepo.MarkHealthy(ctx, tx, d.WebhookID)
+		}
+
+		disabled, err := s.webhookRepo.MarkFailing(ctx, tx, d.WebhookID, s.cfg.DisableAfter)
+		if err != nil {
+			return err
+		}
+		if disabled {
+			log.Printf("webhook %v disabled after failing for %v\n", d.WebhookID, s.cfg.DisableAfter)
+			metrics.IncWebhooksDisabled()
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("error complete webhook delivery %v: %v\n", d.ID, err)
+	}
+}
+
+// backoff doubles the delay with every attempt up to BackoffMax, randomized so retries of one outage spread out.
+func (s *WebhookService) backoff(attempts int) time.Duration {
+	delay := s.cfg.BackoffMax
+	if shift := attempts - 1; shift < 32 && s.cfg.BackoffBase<<shift < s.cfg.BackoffMax {
+		delay = s.cfg.BackoffBase << shift
+	}
+
+	return delay/2 + rand.N(delay/2+1)
+}
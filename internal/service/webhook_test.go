@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"wallet/internal/entity"
+	webhookRepository "wallet/internal/repository/webhook"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"wallet/internal/service/mocks"
+)
+
+func newTestWebhookService(webhookRepoMock *mocks.WebhookRepo, senderMock *mocks.WebhookSender) *WebhookService {
+	storeMock := &mocks.Store{}
+	storeMock.
+		On("WithTransact", mock.Anything, mock.AnythingOfType("func(pgx.Tx) error")).
+		Return(func(_ context.Context, fn func(pgx.Tx) error) error {
+			return fn(&mocks.MockTx{})
+		})
+
+	return NewWebhookService(webhookRepoMock, senderMock, storeMock, WebhookConfig{
+		BatchSize:    10,
+		Lease:        time.Minute,
+		MaxAttempts:  3,
+		BackoffBase:  time.Second,
+		BackoffMax:   time.Minute,
+		DisableAfter: time.Hour,
+	})
+}
+
+func TestWebhookService_Deliver(t *testing.T) {
+	ctx := context.Background()
+	webhookID := uuid.New()
+
+	tcs := []struct {
+		name     string
+		attempts int
+		code     int
+		err      error
+		status   entity.DeliveryStatus
+	}{
+		{"Delivered", 0, 200, nil, entity.DeliveryDelivered},
+		{"Retry", 0, 500, errors.New("500"), entity.DeliveryPending},
+		{"Out of attempts", 2, 0, errors.New("timeout"), entity.DeliveryFailed},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			webhookRepoMock := &mocks.WebhookRepo{}
+			senderMock := &mocks.WebhookSender{}
+			s := newTestWebhookService(webhookRepoMock, senderMock)
+
+			delivery := &entity.WebhookDelivery{
+				ID:        1,
+				WebhookID: webhookID,
+				EventID:   uuid.New(),
+				Status:    entity.DeliveryPending,
+				Attempts:  tc.attempts,
+			}
+
+			webhookRepoMock.On("Claim", ctx, mock.Anything, uint64(10), time.Minute).
+				Return([]webhookRepository.ClaimedDelivery{{Delivery: delivery, URL: "https://merchant.example", Secret: "whsec_test"}}, nil)
+			senderMock.On("Send", ctx, "https://merchant.example", "whsec_test", delivery.EventID, mock.Anything).
+				Return(tc.code, tc.err)
+			webhookRepoMock.On("Complete", ctx, mock.Anything, delivery).Return(nil)
+			webhookRepoMock.On("LogAttempt", ctx, mock.Anything, int64(1), mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			if tc.err == nil {
+				webhookRepoMock.On("MarkHealthy", ctx, mock.Anything, webhookID).Return(nil)
+			} else {
+				webhookRepoMock.On("MarkFailing", ctx, mock.Anything, webhookID, time.Hour).Return(false, nil)
+			}
+
+			start := time.Now()
+			assert.Equal(t, 1, s.deliverDue(ctx))
+
+			assert.Equal(t, tc.status, delivery.Status)
+			assert.Equal(t, tc.attempts+1, delivery.Attempts)
+			if tc.status == entity.DeliveryPending {
+				assert.True(t, delivery.NextAttemptAt.After(start))
+				assert.NotNil(t, delivery.LastError)
+			}
+			webhookRepoMock.AssertExpectations(t)
+			senderMock.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWebhookService_Backoff(t *testing.T) {
+	s := &WebhookService{cfg: WebhookConfig{BackoffBase: time.Second, BackoffMax: time.Minute}}
+
+	for attempts := 1; attempts < 100; attempts++ {
+		delay := s.backoff(attempts)
+		assert.LessOrEqual(t, delay, time.Minute)
+		assert.Greater(t, delay, time.Duration(0))
+	}
+	assert.LessOrEqual(t, s.backoff(1), time.Second)
+	assert.GreaterOrEqual(t, s.backoff(10), 30*time.Second)
+}
+
+func TestWebhookService_RunStopsOnEmptyClaim(t *testing.T) {
+	webhookRepoMock := &mocks.WebhookRepo{}
+	s := newTestWebhookService(webhookRepoMock, &mocks.WebhookSender{})
+	s.cfg.BatchSize = 0
+	s.cfg.PollInterval = time.Millisecond
+
+	webhookRepoMock.On("Claim", mock.Anything, mock.Anything, uint64(0), time.Minute).
+		Return(nil, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after ctx was done")
+	}
+}
@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+	"wallet/internal/utils/metrics"
+)
+
+// WorkerConfig sizes the pool of workers consuming transactions.
+type WorkerConfig struct {
+	// Min workers are started with the service, autoscaling never goes below.
+	Min int
+	// Max bounds autoscaling, which is disabled when Max is not above Min.
+	Max int
+	// ScaleInterval is how often the autoscaler samples the lag and the processing latency.
+	ScaleInterval time.Duration
+	// TargetDrain is how fast the consumer lag should be processed. The pool grows when
+	// lag × latency / workers exceeds it and shrinks when the backlog drains four times faster.
+	TargetDrain time.Duration
+	// BatchSize above one makes every worker take up to BatchSize transactions, waiting
+	// at most BatchWait after the first one, and apply them per wallet in one database transaction.
+	BatchSize int
+	BatchWait time.Duration
+}
+
+func (c WorkerConfig) batching() bool {
+	return c.BatchSize > 1
+}
+
+func (c WorkerConfig) autoscale() bool {
+	return c.Max > c.Min && c.ScaleInterval > 0
+}
+
+// WorkerStatus is a snapshot of the worker pool.
+type WorkerStatus struct {
+	Paused bool
+	Active int
+	Busy   int
+}
+
+type consumerLag interface {
+	Lag() int64
+}
+
+// WithConsumerLag lets the autoscaler size the pool by the consumer lag. Without it the pool
+// is sized by the share of time workers spend processing.
+func WithConsumerLag(lag consumerLag) Option {
+	return func(s *Service) {
+		s.consumerLag = lag
+	}
+}
+
+// workerPool tracks the workers consuming transactions. Pausing stops workers from taking new
+// messages, a worker already waiting for one processes it before it stops.
+// The zero value is ready to use.
+type workerPool struct {
+	mu      sync.Mutex
+	stops   []chan struct{}
+	paused  bool
+	resumed chan struct{}
+
+	busy     atomic.Int64
+	busyTime atomic.Int64
+	done     atomic.Int64
+}
+
+// startWorkers runs n more workers until ctx is done or they are stopped by the autoscaler.
+func (s *Service) startWorkers(ctx context.Context, n int) {
+	s.workers.mu.Lock()
+	defer s.workers.mu.Unlock()
+
+	for range n {
+		stop := make(chan struct{})
+		s.workers.stops = append(s.workers.stops, stop)
+		go s.runWorker(ctx, stop)
+	}
+	metrics.SetActiveWorkers(len(s.workers.stops))
+}
+
+// stop retires n workers, each one finishes the transaction it is processing.
+func (p *workerPool) stop(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	n = min(n, len(p.stops))
+	for _, stop := range p.stops[len(p.stops)-n:] {
+		close(stop)
+	}
+	p.stops = p.stops[:len(p.stops)-n]
+	metrics.SetActiveWorkers(len(p.stops))
+}
+
+// wait blocks while the pool is paused. It returns false when ctx is done or the worker is stopped.
+func (p *workerPool) wait(ctx context.Context, stop <-chan struct{}) bool {
+	p.mu.Lock()
+	paused, resumed := p.paused, p.resumed
+	p.mu.Unlock()
+
+	if !paused {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-stop:
+			return false
+		default:
+			return true
+		}
+	}
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-stop:
+		return false
+	case <-resumed:
+		return true
+	}
+}
+
+func (p *workerPool) pause() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.paused {
+		p.paused = true
+		p.resumed = make(chan struct{})
+		metrics.SetWorkersPaused(true)
+	}
+}
+
+func (p *workerPool) resume() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.paused {
+		p.paused = false
+		close(p.resumed)
+		metrics.SetWorkersPaused(false)
+	}
+}
+
+// track marks the worker busy with n transactions until the returned function is called.
+func (p *workerPool) track(n int) (done func()) {
+	start := time.Now()
+	p.busy.Add(1)
+	metrics.IncBusyWorkers()
+
+	return func() {
+		p.busy.Add(-1)
+		metrics.DecBusyWorkers()
+		p.busyTime.Add(int64(time.Since(start)))
+		p.done.Add(int64(n))
+	}
+}
+
+func (p *workerPool) status() WorkerStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return WorkerStatus{
+		Paused: p.paused,
+		Active: len(p.stops),
+		Busy:   int(p.busy.Load()),
+	}
+}
+
+// PauseConsumption stops workers from taking new transactions, e.g. during incidents.
+func (s *Service) PauseConsumption() WorkerStatus {
+	s.workers.pause()
+	return s.workers.status()
+}
+
+// ResumeConsumption lets paused workers take transactions again.
+func (s *Service) ResumeConsumption() WorkerStatus {
+	s.workers.resume()
+	return s.workers.status()
+}
+
+func (s *Service) WorkerStatus() WorkerStatus {
+	return s.workers.status()
+}
+
+// workerStats is sampled by the autoscaler once per interval.
+type workerStats struct {
+	active int
+	// utilization is the share of the interval the workers spent processing.
+	utilization float64
+	// latency is the average processing time of a transaction.
+	latency time.Duration
+	lag     int64
+	hasLag  bool
+}
+
+const (
+	highUtilization = 0.9
+	lowUtilization  = 0.5
+)
+
+// desired returns the pool size for the sampled stats. It grows by a quarter and shrinks by one worker.
+func (c WorkerConfig) desired(st workerStats) int {
+	var up, down bool
+	if st.hasLag {
+		var drain time.Duration
+		if st.active > 0 {
+			drain = time.Duration(st.lag) * st.latency / time.Duration(st.active)
+		}
+		up = drain > c.TargetDrain
+		down = drain < c.TargetDrain/4 && st.utilization < lowUtilization
+	} else {
+		up = st.utilization > highUtilization
+		down = st.utilization < lowUtilization
+	}
+
+	n := st.active
+	switch {
+	case up:
+		n += max(1, n/4)
+	case down:
+		n--
+	}
+	return min(max(n, c.Min), c.Max)
+}
+
+func (s *Service) autoscale(ctx context.Context, cfg WorkerConfig) {
+	ticker := time.NewTicker(cfg.ScaleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		busyTime := time.Duration(s.workers.busyTime.Swap(0))
+		done := s.workers.done.Swap(0)
+
+		status := s.workers.status()
+		if status.Paused {
+			continue
+		}
+
+		st := workerStats{active: status.Active}
+		if status.Active > 0 {
+			st.utilization = float64(busyTime) / float64(cfg.ScaleInterval*time.Duration(status.Active))
+		}
+		if done > 0 {
+			st.latency = busyTime / time.Duration(done)
+		}
+		if s.consumerLag != nil {
+			st.lag, st.hasLag = s.consumerLag.Lag(), true
+		}
+
+		switch n := cfg.desired(st); {
+		case n > status.Active:
+			log.Printf("scaling workers up from %d to %d\n", status.Active, n)
+			s.startWorkers(ctx, n-status.Active)
+		case n < status.Active:
+			log.Printf("scaling workers down from %d to %d\n", status.Active, n)
+			s.workers.stop(status.Active - n)
+		}
+	}
+}
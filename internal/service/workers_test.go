@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkerConfig_desired(t *testing.T) {
+	cfg := WorkerConfig{Min: 4, Max: 20, TargetDrain: 10 * time.Second}
+
+	tests := []struct {
+		name string
+		st   workerStats
+		want int
+	}{
+		{"backlog drains too slowly", workerStats{active: 8, lag: 10000, latency: 10 * time.Millisecond, hasLag: true, utilization: 1}, 10},
+		{"backlog drains in time", workerStats{active: 8, lag: 1000, latency: 10 * time.Millisecond, hasLag: true, utilization: 0.8}, 8},
+		{"no backlog and idle", workerStats{active: 8, hasLag: true, utilization: 0.1}, 7},
+		{"never above max", workerStats{active: 20, lag: 1e6, latency: time.Second, hasLag: true}, 20},
+		{"never below min", workerStats{active: 4, hasLag: true}, 4},
+		{"busy without lag source", workerStats{active: 4, utilization: 0.95}, 5},
+		{"idle without lag source", workerStats{active: 6, utilization: 0.2}, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, cfg.desired(tt.st))
+		})
+	}
+}
+
+func TestWorkerPool_Pause(t *testing.T) {
+	ctx := context.Background()
+	var p workerPool
+
+	assert.True(t, p.wait(ctx, nil))
+
+	p.pause()
+	resumed := make(chan bool)
+	go func() {
+		resumed <- p.wait(ctx, nil)
+	}()
+
+	select {
+	case <-resumed:
+		t.Fatal("worker took a message while paused")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	p.resume()
+	assert.True(t, <-resumed)
+
+	stop := make(chan struct{})
+	close(stop)
+	assert.False(t, p.wait(ctx, stop))
+}
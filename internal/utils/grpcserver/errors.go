@@ -0,0 +1,7 @@
+package grpcserver
+
+import "errors"
+
+var (
+	ErrDuplicateRun = errors.New("duplicate grpc server running")
+)
@@ -0,0 +1,80 @@
+package grpcserver
+
+import (
+	"context"
+	"log"
+	"net"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+type Server struct {
+	srv       *grpc.Server
+	addr      string
+	notify    chan error
+	isRunning atomic.Bool
+}
+
+type ServerConfig struct {
+	Addr       string
+	Reflection bool
+}
+
+func NewGRPCServer(cfg ServerConfig, opts ...grpc.ServerOption) *Server {
+	srv := grpc.NewServer(opts...)
+	if cfg.Reflection {
+		reflection.Register(srv)
+	}
+
+	return &Server{
+		srv:    srv,
+		addr:   cfg.Addr,
+		notify: make(chan error),
+	}
+}
+
+// RegisterService implements grpc.ServiceRegistrar, so generated Register*Server functions accept the Server.
+func (s *Server) RegisterService(desc *grpc.ServiceDesc, impl any) {
+	s.srv.RegisterService(desc, impl)
+}
+
+func (s *Server) Run() {
+	if s.isRunning.Swap(true) {
+		log.Println(ErrDuplicateRun)
+		return
+	}
+
+	lis, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		s.notify <- err
+		close(s.notify)
+		return
+	}
+
+	s.notify <- s.srv.Serve(lis)
+	close(s.notify)
+}
+
+// Shutdown waits for in-flight rpcs and stops the server forcibly when ctx is done.
+// Streaming rpcs are not finished by the client, so the forced stop is the usual case for them.
+func (s *Server) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.srv.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.srv.Stop()
+		return ctx.Err()
+	}
+}
+
+func (s *Server) Notify() chan error {
+	return s.notify
+}
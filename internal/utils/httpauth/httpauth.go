@@ -0,0 +1,60 @@
+package httpauth
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+const bearerPrefix = "Bearer "
+
+// Config protects operational endpoints with basic auth, a bearer token or both.
+// Requests pass when they match any configured credential, an empty config lets everything through.
+type Config struct {
+	Username string
+	Password string
+	Token    string
+}
+
+func (c Config) Enabled() bool {
+	return c.Username != "" || c.Token != ""
+}
+
+// Protect rejects requests without valid credentials with 401.
+func Protect(cfg Config, next http.Handler) http.Handler {
+	if !cfg.Enabled() {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cfg.allowed(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if cfg.Username != "" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="wallet"`)
+		} else {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+		}
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+	})
+}
+
+func (c Config) allowed(r *http.Request) bool {
+	if c.Username != "" {
+		if user, pass, ok := r.BasicAuth(); ok && equal(user, c.Username) && equal(pass, c.Password) {
+			return true
+		}
+	}
+	if c.Token != "" {
+		if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, bearerPrefix) {
+			return equal(strings.TrimPrefix(auth, bearerPrefix), c.Token)
+		}
+	}
+	return false
+}
+
+func equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
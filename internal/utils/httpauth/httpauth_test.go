@@ -0,0 +1,42 @@
+package httpauth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProtect(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+	h := Protect(Config{Username: "ops", Password: "secret", Token: "scrape"}, ok)
+
+	tests := []struct {
+		name string
+		set  func(r *http.Request)
+		want int
+	}{
+		{"no credentials", func(*http.Request) {}, http.StatusUnauthorized},
+		{"basic auth", func(r *http.Request) { r.SetBasicAuth("ops", "secret") }, http.StatusOK},
+		{"wrong password", func(r *http.Request) { r.SetBasicAuth("ops", "wrong") }, http.StatusUnauthorized},
+		{"bearer token", func(r *http.Request) { r.Header.Set("Authorization", "Bearer scrape") }, http.StatusOK},
+		{"wrong token", func(r *http.Request) { r.Header.Set("Authorization", "Bearer other") }, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			tt.set(r)
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+
+	w := httptest.NewRecorder()
+	Protect(Config{}, ok).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -3,5 +3,9 @@ package httpserver
 import "errors"
 
 var (
-	ErrDuplicateRun = errors.New("duplicate http server running")
+	ErrDuplicateRun      = errors.New("duplicate http server running")
+	ErrUnknownClientAuth = errors.New("unknown tls client auth")
+	ErrNoClientCA        = errors.New("client ca file is required to verify client certificates")
+	ErrInvalidClientCA   = errors.New("no certificates found in client ca file")
+	ErrLoadCertificate   = errors.New("load tls certificate")
 )
@@ -13,25 +13,42 @@ type Server struct {
 	router    http.Handler
 	notify    chan error
 	isRunning atomic.Bool
+	certs     *certReloader
 }
 
 type ServerConfig struct {
-	Addr         string
-	ReadTimeout  time.Duration
-	WriteTimeout time.Duration
+	Addr              string
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	TLS               TLSConfig
 }
 
-func NewHTTPServer(cfg ServerConfig, router http.Handler) *Server {
-	return &Server{
+func NewHTTPServer(cfg ServerConfig, router http.Handler) (*Server, error) {
+	s := &Server{
 		srv: http.Server{
-			Addr:         cfg.Addr,
-			Handler:      router,
-			ReadTimeout:  cfg.ReadTimeout,
-			WriteTimeout: cfg.WriteTimeout,
+			Addr:              cfg.Addr,
+			Handler:           router,
+			ReadTimeout:       cfg.ReadTimeout,
+			ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+			WriteTimeout:      cfg.WriteTimeout,
+			IdleTimeout:       cfg.IdleTimeout,
 		},
 		router: router,
 		notify: make(chan error),
 	}
+
+	if cfg.TLS.Enabled {
+		certs, err := newCertReloader(cfg.TLS)
+		if err != nil {
+			return nil, err
+		}
+		s.certs = certs
+		s.srv.TLSConfig = certs.tlsConfig()
+	}
+
+	return s, nil
 }
 
 func (s *Server) Run() {
@@ -40,11 +57,22 @@ func (s *Server) Run() {
 		return
 	}
 
-	s.notify <- s.srv.ListenAndServe()
+	if s.certs == nil {
+		s.notify <- s.srv.ListenAndServe()
+		close(s.notify)
+		return
+	}
+
+	go s.certs.watch()
+	// the certificate comes from TLSConfig, so the file names are empty
+	s.notify <- s.srv.ListenAndServeTLS("", "")
 	close(s.notify)
 }
 
 func (s *Server) Shutdown(ctx context.Context) error {
+	if s.certs != nil {
+		s.certs.close()
+	}
 	return s.srv.Shutdown(ctx)
 }
 
@@ -0,0 +1,189 @@
+package httpserver
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// ClientAuth is how the server treats client certificates.
+type ClientAuth string
+
+const (
+	// ClientAuthNone doesn't ask for client certificates.
+	ClientAuthNone ClientAuth = "none"
+	// ClientAuthOptional verifies a client certificate when it is presented.
+	ClientAuthOptional ClientAuth = "optional"
+	// ClientAuthRequire rejects connections without a valid client certificate.
+	ClientAuthRequire ClientAuth = "require"
+)
+
+type TLSConfig struct {
+	Enabled  bool
+	CertFile string
+	KeyFile  string
+	// ClientCAFile verifies client certificates, it is required unless ClientAuth is none.
+	ClientCAFile string
+	ClientAuth   ClientAuth
+	// ReloadInterval is how often the files are checked for changes, zero disables reloading.
+	ReloadInterval time.Duration
+}
+
+func (c TLSConfig) clientAuth() (tls.ClientAuthType, error) {
+	switch c.ClientAuth {
+	case ClientAuthNone, "":
+		return tls.NoClientCert, nil
+	case ClientAuthOptional:
+		return tls.VerifyClientCertIfGiven, nil
+	case ClientAuthRequire:
+		return tls.RequireAndVerifyClientCert, nil
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrUnknownClientAuth, c.ClientAuth)
+	}
+}
+
+// certReloader serves the certificate and the client CAs read from files and rereads them
+// when the files change, so rotated certificates are picked up without a restart.
+// A broken rotation keeps the previous certificate.
+type certReloader struct {
+	cfg        TLSConfig
+	clientAuth tls.ClientAuthType
+
+	mu      sync.RWMutex
+	config  *tls.Config
+	modTime time.Time
+
+	stop chan struct{}
+	once sync.Once
+}
+
+func newCertReloader(cfg TLSConfig) (*certReloader, error) {
+	clientAuth, err := cfg.clientAuth()
+	if err != nil {
+		return nil, err
+	}
+	if clientAuth != tls.NoClientCert && cfg.ClientCAFile == "" {
+		return nil, ErrNoClientCA
+	}
+
+	r := &certReloader{
+		cfg:        cfg,
+		clientAuth: clientAuth,
+		stop:       make(chan struct{}),
+	}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// tlsConfig is the server config, every handshake takes the current certificate and client CAs.
+func (r *certReloader) tlsConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			return r.config, nil
+		},
+	}
+}
+
+func (r *certReloader) reload() error {
+	modTime, err := r.lastModified()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrLoadCertificate, err)
+	}
+
+	config := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   r.clientAuth,
+		NextProtos:   []string{"h2", "http/1.1"},
+	}
+	if r.clientAuth != tls.NoClientCert {
+		pem, err := os.ReadFile(r.cfg.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrLoadCertificate, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return ErrInvalidClientCA
+		}
+		config.ClientCAs = pool
+	}
+
+	r.mu.Lock()
+	r.config = config
+	r.modTime = modTime
+	r.mu.Unlock()
+
+	return nil
+}
+
+// lastModified is the latest modification time of the files.
+func (r *certReloader) lastModified() (time.Time, error) {
+	var latest time.Time
+	for _, name := range []string{r.cfg.CertFile, r.cfg.KeyFile, r.cfg.ClientCAFile} {
+		if name == "" {
+			continue
+		}
+		info, err := os.Stat(name)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%w: %w", ErrLoadCertificate, err)
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+func (r *certReloader) changed() bool {
+	modTime, err := r.lastModified()
+	if err != nil {
+		return false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return !modTime.Equal(r.modTime)
+}
+
+func (r *certReloader) watch() {
+	if r.cfg.ReloadInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(r.cfg.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+			if err := r.reload(); err != nil {
+				log.Println("http server - certificate reload failed, keeping the previous one: ", err)
+				continue
+			}
+			log.Println("http server - certificate reloaded")
+		}
+	}
+}
+
+func (r *certReloader) close() {
+	r.once.Do(func() { close(r.stop) })
+}
@@ -0,0 +1,86 @@
+package httpserver
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeCertificate(t *testing.T, dir, name string, modTime time.Time) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+	require.NoError(t, os.Chtimes(keyFile, modTime, modTime))
+}
+
+func servedSubject(t *testing.T, r *certReloader) string {
+	config, err := r.tlsConfig().GetConfigForClient(nil)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(config.Certificates[0].Certificate[0])
+	require.NoError(t, err)
+	return cert.Subject.CommonName
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	writeCertificate(t, dir, "old", now.Add(-time.Minute))
+
+	r, err := newCertReloader(TLSConfig{
+		Enabled:  true,
+		CertFile: filepath.Join(dir, "tls.crt"),
+		KeyFile:  filepath.Join(dir, "tls.key"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "old", servedSubject(t, r))
+	assert.False(t, r.changed())
+
+	writeCertificate(t, dir, "new", now)
+	assert.True(t, r.changed())
+	require.NoError(t, r.reload())
+	assert.Equal(t, "new", servedSubject(t, r))
+
+	// a broken rotation keeps serving the previous certificate
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tls.key"), []byte("broken"), 0o600))
+	assert.Error(t, r.reload())
+	assert.Equal(t, "new", servedSubject(t, r))
+}
+
+func TestNewCertReloader_ClientCARequired(t *testing.T) {
+	dir := t.TempDir()
+	writeCertificate(t, dir, "server", time.Now())
+
+	_, err := newCertReloader(TLSConfig{
+		Enabled:    true,
+		CertFile:   filepath.Join(dir, "tls.crt"),
+		KeyFile:    filepath.Join(dir, "tls.key"),
+		ClientAuth: ClientAuthRequire,
+	})
+	assert.ErrorIs(t, err, ErrNoClientCA)
+}
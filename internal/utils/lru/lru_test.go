@@ -0,0 +1,63 @@
+package lru
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_Evict(t *testing.T) {
+	c := New[string, int](2, 0)
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	_, _ = c.Get("a")
+	c.Set("c", 3)
+
+	_, ok := c.Get("b")
+	assert.False(t, ok, "least recently used entry must be evicted")
+
+	v, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+	assert.Equal(t, 2, c.Len())
+}
+
+func TestCache_TTL(t *testing.T) {
+	now := time.Now()
+	c := New[string, int](10, time.Second)
+	c.now = func() time.Time { return now }
+
+	c.Set("a", 1)
+	_, ok := c.Get("a")
+	assert.True(t, ok)
+
+	now = now.Add(2 * time.Second)
+	_, ok = c.Get("a")
+	assert.False(t, ok)
+	assert.Zero(t, c.Len())
+}
+
+func TestCache_Delete(t *testing.T) {
+	c := New[string, int](10, 0)
+	c.Set("a", 5)
+
+	c.Delete("a")
+	_, ok := c.Get("a")
+	assert.False(t, ok)
+	assert.Zero(t, c.Len())
+}
+
+func TestCache_DeleteFunc(t *testing.T) {
+	c := New[string, int](10, 0)
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Set("c", 1)
+
+	c.DeleteFunc(func(_ string, v int) bool { return v == 1 })
+
+	_, ok := c.Get("b")
+	assert.True(t, ok)
+	assert.Equal(t, 1, c.Len())
+}
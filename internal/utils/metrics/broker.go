@@ -0,0 +1,132 @@
+package metrics
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var consumerLagGauge = promauto.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "kafka",
+		Subsystem: "consumer",
+		Name:      "lag",
+		Help:      "Consumer group lag by partition",
+	},
+	[]string{
+		"topic",
+		"partition",
+	},
+)
+
+func SetConsumerLag(topic string, partition int, lag int64) {
+	consumerLagGauge.WithLabelValues(
+		topic,
+		strconv.Itoa(partition),
+	).Set(float64(lag))
+}
+
+var consumerMessagesCounter = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "kafka",
+		Subsystem: "consumer",
+		Name:      "messages_total",
+		Help:      "Number of consumed messages",
+	},
+	[]string{
+		"topic",
+	},
+)
+
+var consumerFetchErrorsCounter = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "kafka",
+		Subsystem: "consumer",
+		Name:      "fetch_errors_total",
+		Help:      "Number of consumer fetch errors",
+	},
+	[]string{
+		"topic",
+	},
+)
+
+var consumerRebalancesCounter = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "kafka",
+		Subsystem: "consumer",
+		Name:      "rebalances_total",
+		Help:      "Number of consumer group rebalances",
+	},
+	[]string{
+		"topic",
+	},
+)
+
+func AddConsumerStats(topic string, messages, fetchErrors, rebalances int64) {
+	consumerMessagesCounter.WithLabelValues(topic).Add(float64(messages))
+	consumerFetchErrorsCounter.WithLabelValues(topic).Add(float64(fetchErrors))
+	consumerRebalancesCounter.WithLabelValues(topic).Add(float64(rebalances))
+}
+
+var producerMessagesCounter = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "kafka",
+		Subsystem: "producer",
+		Name:      "messages_total",
+		Help:      "Number of produced messages",
+	},
+	[]string{
+		"topic",
+	},
+)
+
+var producerWriteErrorsCounter = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "kafka",
+		Subsystem: "producer",
+		Name:      "write_errors_total",
+		Help:      "Number of producer write errors",
+	},
+	[]string{
+		"topic",
+	},
+)
+
+var producerBatchSizeGauge = promauto.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "kafka",
+		Subsystem: "producer",
+		Name:      "batch_size",
+		Help:      "Producer batch size in messages",
+	},
+	[]string{
+		"topic",
+		"stat",
+	},
+)
+
+func AddProducerStats(topic string, messages, writeErrors int64) {
+	producerMessagesCounter.WithLabelValues(topic).Add(float64(messages))
+	producerWriteErrorsCounter.WithLabelValues(topic).Add(float64(writeErrors))
+}
+
+func SetProducerBatchSize(topic string, avg, max int64) {
+	producerBatchSizeGauge.WithLabelValues(topic, "avg").Set(float64(avg))
+	producerBatchSizeGauge.WithLabelValues(topic, "max").Set(float64(max))
+}
+
+var queueDeadLetteredCounter = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "queue",
+		Name:      "dead_lettered_total",
+		Help:      "Number of queue messages moved to the dead letter topic after running out of attempts",
+	},
+	[]string{
+		"topic",
+	},
+)
+
+func AddQueueDeadLettered(topic string, messages int64) {
+	queueDeadLetteredCounter.WithLabelValues(topic).Add(float64(messages))
+}
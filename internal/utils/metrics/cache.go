@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	CacheLayerLocal = "local"
+	CacheLayerRedis = "redis"
+)
+
+const (
+	CacheResultHit   = "hit"
+	CacheResultMiss  = "miss"
+	CacheResultError = "error"
+)
+
+var cacheRequestsCounter = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "cache",
+		Name:      "requests_total",
+		Help:      "Number of balance cache lookups by layer and result",
+	},
+	[]string{
+		"layer",
+		"result",
+	},
+)
+
+func IncCacheRequests(layer, result string) {
+	cacheRequestsCounter.WithLabelValues(layer, result).Inc()
+}
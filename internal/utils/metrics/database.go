@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var txRetriesCounter = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "db",
+		Subsystem: "tx",
+		Name:      "retries_total",
+		Help:      "Number of retried transactions by failure",
+	},
+	[]string{
+		"reason",
+	},
+)
+
+func IncTxRetries(reason string) {
+	txRetriesCounter.WithLabelValues(reason).Inc()
+}
+
+var txRetriesExhaustedCounter = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "db",
+		Subsystem: "tx",
+		Name:      "retries_exhausted_total",
+		Help:      "Number of retryable transaction failures returned because attempts or retry budget ran out",
+	},
+	[]string{
+		"limit",
+	},
+)
+
+func IncTxRetriesExhausted(limit string) {
+	txRetriesExhaustedCounter.WithLabelValues(limit).Inc()
+}
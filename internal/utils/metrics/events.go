@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var eventSubscribersGauge = promauto.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "events",
+		Name:      "subscribers",
+		Help:      "Number of active wallet event subscribers",
+	},
+)
+
+func IncEventSubscribers() {
+	eventSubscribersGauge.Inc()
+}
+
+func DecEventSubscribers() {
+	eventSubscribersGauge.Dec()
+}
+
+var eventSubscribersDroppedCounter = promauto.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "events",
+		Name:      "subscribers_dropped_total",
+		Help:      "Number of subscribers disconnected for falling behind",
+	},
+)
+
+func IncEventSubscribersDropped() {
+	eventSubscribersDroppedCounter.Inc()
+}
@@ -0,0 +1,22 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var walletLocksGauge = promauto.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "service",
+		Name:      "wallet_locks",
+		Help:      "Number of wallets locked or awaited by workers",
+	},
+)
+
+func IncWalletLocks() {
+	walletLocksGauge.Inc()
+}
+
+func DecWalletLocks() {
+	walletLocksGauge.Dec()
+}
@@ -0,0 +1,21 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var rateLimitRejectedCounter = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "ratelimit",
+		Name:      "rejected_total",
+		Help:      "Number of requests rejected by rate limit",
+	},
+	[]string{
+		"scope",
+	},
+)
+
+func IncRateLimitRejected(scope string) {
+	rateLimitRejectedCounter.WithLabelValues(scope).Inc()
+}
@@ -32,3 +32,8 @@ func (w *CustomResponseWriter) Header() http.Header {
 func (w *CustomResponseWriter) GetStatusCode() int {
 	return w.statusCode
 }
+
+// Unwrap lets http.ResponseController reach the Flusher and deadlines of the underlying writer.
+func (w *CustomResponseWriter) Unwrap() http.ResponseWriter {
+	return w.rw
+}
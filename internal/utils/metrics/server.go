@@ -2,33 +2,81 @@ package metrics
 
 import (
 	"context"
+	"encoding/json"
 	"github.com/gorilla/mux"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"log"
 	"net/http"
+	"time"
+	"wallet/internal/utils/httpauth"
 )
 
+const readinessTimeout = 2 * time.Second
+
+// ReadinessCheck returns an error when the checked component is not ready to serve traffic.
+type ReadinessCheck func(context.Context) error
+
 type Server struct {
 	srv    *http.Server
 	router *mux.Router
 	notify chan error
+	checks map[string]ReadinessCheck
 }
 
 type Config struct {
-	Addr string
+	Addr              string
+	ReadHeaderTimeout time.Duration
+	IdleTimeout       time.Duration
+	// Auth protects /metrics, /readyz stays open for probes.
+	Auth httpauth.Config
 }
 
 func NewMetricsServer(cfg Config) *Server {
 	r := mux.NewRouter()
-	r.Handle("/metrics", promhttp.Handler())
 
-	return &Server{
+	s := &Server{
 		&http.Server{
-			Addr:    cfg.Addr,
-			Handler: r,
+			Addr:              cfg.Addr,
+			Handler:           r,
+			ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+			IdleTimeout:       cfg.IdleTimeout,
 		},
 		r,
 		make(chan error, 1),
+		make(map[string]ReadinessCheck),
+	}
+
+	r.Handle("/metrics", httpauth.Protect(cfg.Auth, promhttp.Handler()))
+	r.HandleFunc("/readyz", s.readyz)
+
+	return s
+}
+
+// AddReadinessCheck registers a check used by /readyz. It must be called before Run.
+func (s *Server) AddReadinessCheck(name string, check ReadinessCheck) {
+	s.checks[name] = check
+}
+
+func (s *Server) readyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+
+	failed := make(map[string]string)
+	for name, check := range s.checks {
+		if err := check(ctx); err != nil {
+			failed[name] = err.Error()
+		}
+	}
+
+	code := http.StatusOK
+	if len(failed) > 0 {
+		code = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(failed); err != nil {
+		log.Println("metrics - readiness write failed: ", err)
 	}
 }
 
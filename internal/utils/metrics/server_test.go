@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_Readyz(t *testing.T) {
+	errLagTooHigh := errors.New("consumer lag too high")
+
+	tests := []struct {
+		name  string
+		check ReadinessCheck
+		code  int
+		body  string
+	}{
+		{"Ready", func(context.Context) error { return nil }, http.StatusOK, "{}\n"},
+		{"Lag over threshold", func(context.Context) error { return errLagTooHigh }, http.StatusServiceUnavailable, `{"consumer":"consumer lag too high"}` + "\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewMetricsServer(Config{})
+			s.AddReadinessCheck("consumer", tt.check)
+
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			assert.Equal(t, tt.code, rec.Code)
+			assert.Equal(t, tt.body, rec.Body.String())
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		})
+	}
+}
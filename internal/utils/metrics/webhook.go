@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var webhookDeliveriesCounter = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "webhook",
+		Name:      "deliveries_total",
+		Help:      "Number of webhook delivery attempts by result",
+	},
+	[]string{
+		"result",
+	},
+)
+
+func IncWebhookDeliveries(result string) {
+	webhookDeliveriesCounter.WithLabelValues(result).Inc()
+}
+
+var webhooksDisabledCounter = promauto.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "webhook",
+		Name:      "disabled_total",
+		Help:      "Number of webhooks disabled after failing for too long",
+	},
+)
+
+func IncWebhooksDisabled() {
+	webhooksDisabledCounter.Inc()
+}
@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var activeWorkersGauge = promauto.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "service",
+		Name:      "workers_active",
+		Help:      "Number of workers consuming transactions",
+	},
+)
+
+var busyWorkersGauge = promauto.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "service",
+		Name:      "workers_busy",
+		Help:      "Number of workers processing a transaction",
+	},
+)
+
+var workersPausedGauge = promauto.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "service",
+		Name:      "workers_paused",
+		Help:      "Whether consumption of transactions is paused",
+	},
+)
+
+func SetActiveWorkers(n int) {
+	activeWorkersGauge.Set(float64(n))
+}
+
+func IncBusyWorkers() {
+	busyWorkersGauge.Inc()
+}
+
+func DecBusyWorkers() {
+	busyWorkersGauge.Dec()
+}
+
+func SetWorkersPaused(paused bool) {
+	v := 0.
+	if paused {
+		v = 1
+	}
+	workersPausedGauge.Set(v)
+}
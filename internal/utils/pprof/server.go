@@ -6,6 +6,8 @@ import (
 	"log"
 	"net/http"
 	"net/http/pprof"
+	"time"
+	"wallet/internal/utils/httpauth"
 )
 
 type Server struct {
@@ -15,7 +17,10 @@ type Server struct {
 }
 
 type Config struct {
-	Addr string
+	Addr              string
+	ReadHeaderTimeout time.Duration
+	IdleTimeout       time.Duration
+	Auth              httpauth.Config
 }
 
 func NewPProfServer(cfg Config) *Server {
@@ -29,11 +34,16 @@ func NewPProfServer(cfg Config) *Server {
 	sr.HandleFunc("/symbol", pprof.Symbol)
 	sr.HandleFunc("/trace", pprof.Trace)
 	sr.HandleFunc("/{profile}", pprof.Index)
+	sr.Use(func(next http.Handler) http.Handler {
+		return httpauth.Protect(cfg.Auth, next)
+	})
 
 	return &Server{
 		&http.Server{
-			Addr:    cfg.Addr,
-			Handler: r,
+			Addr:              cfg.Addr,
+			Handler:           r,
+			ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+			IdleTimeout:       cfg.IdleTimeout,
 		},
 		r,
 		make(chan error, 1),
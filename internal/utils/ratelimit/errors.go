@@ -0,0 +1,7 @@
+package ratelimit
+
+import "errors"
+
+var (
+	ErrLimitExceeded = errors.New("rate limit exceeded")
+)
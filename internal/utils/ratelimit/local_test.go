@@ -0,0 +1,53 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocal_Allow(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	l := NewLocal(Config{Rate: 2, Burst: 3})
+	l.now = func() time.Time { return now }
+
+	for range 3 {
+		res, err := l.Allow(ctx, "client")
+		assert.NoError(t, err)
+		assert.True(t, res.Allowed)
+	}
+
+	res, err := l.Allow(ctx, "client")
+	assert.NoError(t, err)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 500*time.Millisecond, res.RetryAfter)
+
+	// other keys have own buckets
+	res, _ = l.Allow(ctx, "other")
+	assert.True(t, res.Allowed)
+
+	now = now.Add(500 * time.Millisecond)
+	res, _ = l.Allow(ctx, "client")
+	assert.True(t, res.Allowed)
+	res, _ = l.Allow(ctx, "client")
+	assert.False(t, res.Allowed)
+}
+
+func TestLocal_Cleanup(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	l := NewLocal(Config{Rate: 10, Burst: 10})
+	l.now = func() time.Time { return now }
+
+	_, _ = l.Allow(ctx, "idle")
+	now = now.Add(cleanupInterval)
+	_, _ = l.Allow(ctx, "active")
+
+	assert.Len(t, l.buckets, 1)
+	assert.Contains(t, l.buckets, "active")
+}
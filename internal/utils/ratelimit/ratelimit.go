@@ -0,0 +1,22 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+type Limiter interface {
+	Allow(ctx context.Context, key string) (Result, error)
+}
+
+type Config struct {
+	// Rate is the number of requests per second refilled into the bucket.
+	Rate float64
+	// Burst is the bucket capacity.
+	Burst int
+}
+
+type Result struct {
+	Allowed    bool
+	RetryAfter time.Duration
+}
@@ -0,0 +1,76 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const keyPrefix = "ratelimit:"
+
+// tokenBucketScript refills and takes a token atomically using the redis clock,
+// so replicas with skewed clocks share the same bucket state.
+const tokenBucketScript = `
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) + tonumber(t[2]) / 1000000
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or burst
+local ts = tonumber(state[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate)
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = (1 - tokens) / rate
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('EXPIRE', KEYS[1], math.ceil(burst / rate) + 1)
+return {allowed, tostring(retry)}
+`
+
+type scripter interface {
+	Eval(ctx context.Context, script string, keys []string, args ...any) (any, error)
+}
+
+// Redis is a token bucket limiter shared by all replicas.
+type Redis struct {
+	cfg   Config
+	cache scripter
+}
+
+func NewRedis(cache scripter, cfg Config) *Redis {
+	return &Redis{
+		cfg:   cfg,
+		cache: cache,
+	}
+}
+
+func (l *Redis) Allow(ctx context.Context, key string) (Result, error) {
+	res, err := l.cache.Eval(ctx, tokenBucketScript, []string{keyPrefix + key}, l.cfg.Rate, l.cfg.Burst)
+	if err != nil {
+		return Result{}, err
+	}
+
+	values, ok := res.([]any)
+	if !ok || len(values) != 2 {
+		return Result{}, fmt.Errorf("unexpected rate limit script result: %v", res)
+	}
+
+	allowed, _ := values[0].(int64)
+	retry, _ := values[1].(string)
+
+	seconds, err := strconv.ParseFloat(retry, 64)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return Result{
+		Allowed:    allowed == 1,
+		RetryAfter: time.Duration(seconds * float64(time.Second)),
+	}, nil
+}
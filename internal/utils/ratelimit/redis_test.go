@@ -0,0 +1,47 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+	"wallet/internal/infrastructure/cache/redis"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedis_Allow(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	mr := miniredis.RunT(t)
+	mr.SetTime(now)
+
+	cache, err := redis.New(ctx, redis.Config{URI: "redis://" + mr.Addr()})
+	require.NoError(t, err)
+	defer cache.Close()
+
+	l := NewRedis(cache, Config{Rate: 2, Burst: 3})
+
+	for range 3 {
+		res, err := l.Allow(ctx, "client")
+		assert.NoError(t, err)
+		assert.True(t, res.Allowed)
+	}
+
+	res, err := l.Allow(ctx, "client")
+	assert.NoError(t, err)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 500*time.Millisecond, res.RetryAfter)
+
+	// other keys have own buckets
+	res, _ = l.Allow(ctx, "other")
+	assert.True(t, res.Allowed)
+
+	// the bucket is refilled by the redis clock and expires when full
+	mr.SetTime(now.Add(500 * time.Millisecond))
+	res, _ = l.Allow(ctx, "client")
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 3*time.Second, mr.TTL(keyPrefix+"client"))
+}
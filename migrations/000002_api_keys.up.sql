@@ -0,0 +1,14 @@
+/*
+API KEYS
+*/
+
+CREATE TABLE IF NOT EXISTS api_keys
+(
+    id         uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+    name       VARCHAR(255)                               NOT NULL,
+    prefix     VARCHAR(32)                                NOT NULL,
+    key_hash   VARCHAR(64) UNIQUE                         NOT NULL,
+    scopes     TEXT[]                                     NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE                   NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS wallets_owner_idx;
+
+ALTER TABLE wallets
+    DROP COLUMN IF EXISTS owner;
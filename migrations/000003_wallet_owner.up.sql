@@ -0,0 +1,4 @@
+ALTER TABLE wallets
+    ADD COLUMN IF NOT EXISTS owner VARCHAR(255) DEFAULT '' NOT NULL;
+
+CREATE INDEX IF NOT EXISTS wallets_owner_idx ON wallets (owner);
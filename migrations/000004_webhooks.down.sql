@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS webhook_delivery_attempts;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
+
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS initiator;
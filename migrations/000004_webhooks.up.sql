@@ -0,0 +1,57 @@
+/*
+TRANSACTION INITIATOR
+*/
+
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS initiator VARCHAR(255) DEFAULT '' NOT NULL;
+
+/*
+WEBHOOKS
+*/
+
+CREATE TABLE IF NOT EXISTS webhooks
+(
+    id            uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+    subject       VARCHAR(255)                               NOT NULL,
+    wallet_uuid   uuid,
+    url           TEXT                                       NOT NULL,
+    secret        VARCHAR(128)                               NOT NULL,
+    enabled       BOOLEAN          DEFAULT TRUE              NOT NULL,
+    failing_since TIMESTAMP WITH TIME ZONE,
+    disabled_at   TIMESTAMP WITH TIME ZONE,
+    created_at    TIMESTAMP WITH TIME ZONE                   NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS webhooks_subject_idx ON webhooks (subject);
+CREATE INDEX IF NOT EXISTS webhooks_wallet_uuid_idx ON webhooks (wallet_uuid);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries
+(
+    id               BIGSERIAL PRIMARY KEY                           NOT NULL,
+    webhook_id       uuid REFERENCES webhooks (id) ON DELETE CASCADE NOT NULL,
+    event_id         uuid                                            NOT NULL,
+    event_type       VARCHAR(64)                                     NOT NULL,
+    payload          JSONB                                           NOT NULL,
+    status           VARCHAR(16)                                     NOT NULL,
+    attempts         INT    DEFAULT 0                                NOT NULL,
+    next_attempt_at  TIMESTAMP WITH TIME ZONE                        NOT NULL,
+    last_status_code INT,
+    last_error       TEXT,
+    created_at       TIMESTAMP WITH TIME ZONE                        NOT NULL,
+    delivered_at     TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_pending_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_id_idx ON webhook_deliveries (webhook_id, id);
+
+CREATE TABLE IF NOT EXISTS webhook_delivery_attempts
+(
+    id           BIGSERIAL PRIMARY KEY                                     NOT NULL,
+    delivery_id  BIGINT REFERENCES webhook_deliveries (id) ON DELETE CASCADE NOT NULL,
+    status_code  INT,
+    error        TEXT,
+    duration_ms  BIGINT                                                    NOT NULL,
+    attempted_at TIMESTAMP WITH TIME ZONE                                  NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS webhook_delivery_attempts_delivery_id_idx ON webhook_delivery_attempts (delivery_id);
@@ -0,0 +1,2 @@
+ALTER TABLE wallets
+    ADD COLUMN IF NOT EXISTS frozen BOOLEAN DEFAULT FALSE NOT NULL;
@@ -0,0 +1,17 @@
+/*
+POSTGRES QUEUE
+*/
+
+CREATE TABLE IF NOT EXISTS queue_messages
+(
+    id         BIGSERIAL PRIMARY KEY    NOT NULL,
+    topic      VARCHAR(255)             NOT NULL,
+    key        BYTEA,
+    value      BYTEA                    NOT NULL,
+    headers    JSONB DEFAULT '{}'       NOT NULL,
+    attempts   INT   DEFAULT 0          NOT NULL,
+    visible_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS queue_messages_topic_visible_at_idx ON queue_messages (topic, visible_at, id);